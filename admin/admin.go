@@ -0,0 +1,150 @@
+package admin
+
+import (
+	"net/http"
+
+	"mock-api-server/config"
+	"mock-api-server/handler"
+	"mock-api-server/middleware"
+	"mock-api-server/pkg/chaos"
+	"mock-api-server/pkg/events"
+	"mock-api-server/state"
+	"mock-api-server/ui"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server exposes administrative APIs under /admin
+type Server struct {
+	configManager *config.ConfigManager
+	mockHandler   *handler.MockHandler
+	scenarioStore state.Store
+	auditLog      *AuditLog
+	eventBus      *events.Bus
+	chaos         *chaos.Controller
+	logBuffer     *middleware.LogBuffer
+	health        *handler.HealthOverride
+}
+
+// Options holds the subsystems the admin API inspects and controls
+type Options struct {
+	ConfigManager *config.ConfigManager
+	MockHandler   *handler.MockHandler
+	ScenarioStore state.Store
+	EventBus      *events.Bus
+	Chaos         *chaos.Controller
+	LogBuffer     *middleware.LogBuffer // optional, nil disables /admin/logs
+	Health        *handler.HealthOverride
+}
+
+// NewServer creates a new admin Server
+func NewServer(opts Options) *Server {
+	auditSize := 0
+	if cfg := opts.ConfigManager.GetConfig(); cfg != nil {
+		auditSize = cfg.Admin.AuditSize
+	}
+
+	return &Server{
+		configManager: opts.ConfigManager,
+		mockHandler:   opts.MockHandler,
+		scenarioStore: opts.ScenarioStore,
+		auditLog:      NewAuditLog(auditSize),
+		eventBus:      opts.EventBus,
+		chaos:         opts.Chaos,
+		logBuffer:     opts.LogBuffer,
+		health:        opts.Health,
+	}
+}
+
+// RegisterRoutes registers all admin routes on the router
+func (s *Server) RegisterRoutes(r *gin.Engine) {
+	group := r.Group("/admin")
+
+	// CORS runs before auth so browser preflight requests, which carry no
+	// credentials, are answered directly
+	if cfg := s.configManager.GetConfig(); cfg != nil && len(cfg.Admin.CORS.AllowedOrigins) > 0 {
+		group.Use(middleware.CORS(cfg.Admin.CORS))
+		group.OPTIONS("/*path", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	}
+	group.Use(s.authMiddleware(), s.auditMiddleware())
+
+	group.POST("/match-test", s.handleMatchTest)
+	group.POST("/reset", s.handleReset)
+	group.GET("/snapshot", s.handleGetSnapshot)
+	group.POST("/snapshot/restore", s.handleRestoreSnapshot)
+	group.GET("/audit", s.handleGetAudit)
+	group.GET("/logs", s.handleGetLogs)
+	group.GET("/ws", s.handleWebSocket)
+	group.PUT("/config", s.handleReplaceConfig)
+	group.POST("/config/validate", s.handleValidateConfig)
+	group.GET("/bundle", s.handleExportBundle)
+	group.POST("/bundle", s.handleImportBundle)
+
+	group.GET("/endpoints", s.handleListEndpoints)
+	group.POST("/endpoints", s.handleCreateEndpoint)
+	group.POST("/endpoints/validate", s.handleValidateEndpoint)
+	group.GET("/endpoints/:id", s.handleGetEndpoint)
+	group.PUT("/endpoints/:id", s.handleUpdateEndpoint)
+	group.DELETE("/endpoints/:id", s.handleDeleteEndpoint)
+	group.POST("/import/openapi", s.handleImportOpenAPI)
+	group.POST("/endpoints/:id/enable", s.handleEnableEndpoint)
+	group.POST("/endpoints/:id/disable", s.handleDisableEndpoint)
+
+	group.GET("/files/*path", s.handleGetFile)
+	group.PUT("/files/*path", s.handlePutFile)
+	group.DELETE("/files/*path", s.handleDeleteFile)
+
+	group.POST("/template/preview", s.handleTemplatePreview)
+
+	group.GET("/openapi", s.handleGetOpenAPI)
+	group.GET("/docs", s.handleDocs)
+	group.StaticFS("/docs/assets", docsAssets())
+	group.StaticFS("/ui", ui.FS())
+
+	group.GET("/chaos", s.handleGetChaos)
+	group.POST("/chaos", s.handleSetChaos)
+	group.DELETE("/chaos", s.handleClearChaos)
+
+	group.GET("/health", s.handleGetHealth)
+	group.PUT("/health", s.handleSetHealth)
+	group.DELETE("/health", s.handleClearHealth)
+
+	group.GET("/metrics", s.handleGetMetrics)
+	group.GET("/selfcheck", s.handleSelfCheck)
+	group.GET("/counters", s.handleListCounters)
+	group.DELETE("/counters", s.handleResetCounters)
+	group.GET("/counters/:name", s.handleGetCounter)
+	group.POST("/counters/:name/increment", s.handleIncrementCounter)
+	group.DELETE("/counters/:name", s.handleResetCounter)
+
+	group.GET("/sinks", s.handleListSinks)
+	group.DELETE("/sinks", s.handleClearSinks)
+	group.GET("/sinks/:id", s.handleGetSink)
+	group.DELETE("/sinks/:id", s.handleClearSink)
+
+	group.GET("/scenarios", s.handleListScenarios)
+	group.GET("/scenarios/export", s.handleExportScenarios)
+	group.POST("/scenarios/import", s.handleImportScenarios)
+	group.POST("/scenarios/seed", s.handleSeedScenarios)
+	group.POST("/scenarios/reset-all", s.handleResetAllScenarios)
+	group.GET("/scenarios/:name", s.handleGetScenario)
+	group.GET("/scenarios/:name/history", s.handleGetScenarioHistory)
+	group.GET("/scenarios/:name/diagram", s.handleGetScenarioDiagram)
+	group.PUT("/scenarios/:name/state", s.handleSetScenarioState)
+	group.DELETE("/scenarios/:name/state", s.handleResetScenarioState)
+}
+
+// respondError writes an error body in the same shape used by the mock handler
+func respondError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// badRequest writes a 400 response
+func badRequest(c *gin.Context, message string) {
+	respondError(c, http.StatusBadRequest, "BAD_REQUEST", message)
+}
@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"mock-api-server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultAuditSize = 200
+
+// AuditEntry records one mutating admin call
+type AuditEntry struct {
+	Time       time.Time         `json:"time"`
+	RequestID  string            `json:"request_id,omitempty"`
+	User       string            `json:"user,omitempty"`
+	ClientIP   string            `json:"client_ip"`
+	Method     string            `json:"method"`
+	Route      string            `json:"route"`
+	Path       string            `json:"path"`
+	Params     map[string]string `json:"params,omitempty"`
+	Status     int               `json:"status"`
+	BodySize   int               `json:"body_size"`
+	BodySHA256 string            `json:"body_sha256,omitempty"`
+}
+
+// AuditLog is a fixed-size ring buffer of audit entries
+type AuditLog struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+	next    int
+	full    bool
+}
+
+// NewAuditLog creates an AuditLog holding at most size entries
+func NewAuditLog(size int) *AuditLog {
+	if size <= 0 {
+		size = defaultAuditSize
+	}
+	return &AuditLog{entries: make([]AuditEntry, size)}
+}
+
+// Add appends an entry, overwriting the oldest one when full
+func (a *AuditLog) Add(entry AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.entries[a.next] = entry
+	a.next = (a.next + 1) % len(a.entries)
+	if a.next == 0 {
+		a.full = true
+	}
+}
+
+// Entries returns up to limit entries, newest first. limit <= 0 returns all.
+func (a *AuditLog) Entries(limit int) []AuditEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	count := a.next
+	if a.full {
+		count = len(a.entries)
+	}
+	if limit <= 0 || limit > count {
+		limit = count
+	}
+
+	result := make([]AuditEntry, 0, limit)
+	for i := 0; i < limit; i++ {
+		idx := (a.next - 1 - i + len(a.entries)) % len(a.entries)
+		result = append(result, a.entries[idx])
+	}
+	return result
+}
+
+// auditMiddleware records every mutating admin call after it completes
+func (s *Server) auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isReadOnlyRequest(c) {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if middleware.IsBodyTooLarge(err) {
+				middleware.AbortBodyTooLarge(c, s.configManager.GetConfig())
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+		}
+
+		c.Next()
+
+		entry := AuditEntry{
+			Time:      time.Now(),
+			RequestID: c.GetString("request_id"),
+			User:      c.GetString(adminUserKey),
+			ClientIP:  c.ClientIP(),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			BodySize:  len(body),
+		}
+		if len(c.Params) > 0 {
+			entry.Params = make(map[string]string, len(c.Params))
+			for _, p := range c.Params {
+				entry.Params[p.Key] = p.Value
+			}
+		}
+		if len(body) > 0 {
+			sum := sha256.Sum256(body)
+			entry.BodySHA256 = hex.EncodeToString(sum[:])
+		}
+		s.auditLog.Add(entry)
+	}
+}
+
+// handleGetAudit returns recorded mutating admin calls, newest first
+func (s *Server) handleGetAudit(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	c.JSON(http.StatusOK, gin.H{
+		"entries": s.auditLog.Entries(limit),
+	})
+}
@@ -0,0 +1,35 @@
+package admin
+
+import "testing"
+
+func TestAuditLogRingBuffer(t *testing.T) {
+	log := NewAuditLog(3)
+	for i := 1; i <= 5; i++ {
+		log.Add(AuditEntry{Status: i})
+	}
+
+	entries := log.Entries(0)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for i, want := range []int{5, 4, 3} {
+		if entries[i].Status != want {
+			t.Errorf("entries[%d].Status = %d, want %d", i, entries[i].Status, want)
+		}
+	}
+
+	if limited := log.Entries(2); len(limited) != 2 || limited[0].Status != 5 {
+		t.Errorf("Entries(2) = %+v, want newest two entries", limited)
+	}
+}
+
+func TestAuditLogPartiallyFilled(t *testing.T) {
+	log := NewAuditLog(10)
+	log.Add(AuditEntry{Status: 1})
+	log.Add(AuditEntry{Status: 2})
+
+	entries := log.Entries(0)
+	if len(entries) != 2 || entries[0].Status != 2 || entries[1].Status != 1 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
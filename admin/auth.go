@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	hashedSecretPrefix = "sha256:"
+	adminUserKey       = "admin_user"
+	adminRoleKey       = "admin_role"
+
+	roleAdmin    = "admin"
+	roleReadonly = "readonly"
+)
+
+// authMiddleware enforces admin.auth against HTTP Basic credentials or a Bearer
+// token. The config is read per request so runtime config changes apply.
+func (s *Server) authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := s.configManager.GetConfig()
+		if cfg == nil || !authEnabled(cfg.Admin.Auth) {
+			c.Next()
+			return
+		}
+
+		if user, role, ok := authenticate(c.Request, cfg.Admin.Auth); ok {
+			if role == roleReadonly && !isReadOnlyRequest(c) {
+				respondError(c, http.StatusForbidden, "FORBIDDEN", "read-only admin credentials cannot modify server state")
+				c.Abort()
+				return
+			}
+			c.Set(adminUserKey, user)
+			c.Set(adminRoleKey, role)
+			c.Next()
+			return
+		}
+
+		c.Header("WWW-Authenticate", `Basic realm="mock-admin"`)
+		respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "admin credentials required")
+		c.Abort()
+	}
+}
+
+// authEnabled reports whether any admin credentials are configured
+func authEnabled(auth config.AdminAuth) bool {
+	return len(auth.Users) > 0 || len(auth.Tokens) > 0 ||
+		len(auth.ReadonlyUsers) > 0 || len(auth.ReadonlyTokens) > 0
+}
+
+// authenticate checks the request credentials and returns the caller identity and role
+func authenticate(req *http.Request, auth config.AdminAuth) (string, string, bool) {
+	if header := req.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		if tokenMatches(auth.Tokens, token) {
+			return tokenIdentity(token), roleAdmin, true
+		}
+		if tokenMatches(auth.ReadonlyTokens, token) {
+			return tokenIdentity(token), roleReadonly, true
+		}
+		return "", "", false
+	}
+
+	if user, pass, ok := req.BasicAuth(); ok {
+		if expected, exists := auth.Users[user]; exists && secretMatches(expected, pass) {
+			return user, roleAdmin, true
+		}
+		if expected, exists := auth.ReadonlyUsers[user]; exists && secretMatches(expected, pass) {
+			return user, roleReadonly, true
+		}
+	}
+	return "", "", false
+}
+
+// tokenMatches reports whether token matches any of the configured tokens
+func tokenMatches(tokens []string, token string) bool {
+	for _, expected := range tokens {
+		if secretMatches(expected, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyRoutes are non-GET admin routes that do not change server state
+var readOnlyRoutes = map[string]bool{
+	"/admin/match-test":         true,
+	"/admin/config/validate":    true,
+	"/admin/endpoints/validate": true,
+	"/admin/template/preview":   true,
+}
+
+// isReadOnlyRequest reports whether the request only reads admin state
+func isReadOnlyRequest(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return readOnlyRoutes[c.FullPath()]
+}
+
+// secretMatches compares a presented secret against a configured plain or hashed secret
+func secretMatches(expected, presented string) bool {
+	if presented == "" {
+		return false
+	}
+	if strings.HasPrefix(expected, hashedSecretPrefix) {
+		want := strings.ToLower(strings.TrimPrefix(expected, hashedSecretPrefix))
+		sum := sha256.Sum256([]byte(presented))
+		return subtle.ConstantTimeCompare([]byte(want), []byte(hex.EncodeToString(sum[:]))) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(presented)) == 1
+}
+
+// tokenIdentity returns a loggable identity for a token without revealing it
+func tokenIdentity(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])[:8]
+}
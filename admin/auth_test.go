@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthenticate(t *testing.T) {
+	// sha256("ci-secret")
+	auth := config.AdminAuth{
+		Users:  map[string]string{"alice": "wonderland"},
+		Tokens: []string{"plain-token", "sha256:f82da6e2b2e51c046a2eaf10964ef184b69c0aee9892f10a2a7b657477d407e6"},
+	}
+
+	tests := []struct {
+		name     string
+		setup    func(r *http.Request)
+		expected bool
+	}{
+		{"no credentials", func(r *http.Request) {}, false},
+		{"valid basic", func(r *http.Request) { r.SetBasicAuth("alice", "wonderland") }, true},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("alice", "nope") }, false},
+		{"plain token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer plain-token") }, true},
+		{"hashed token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer ci-secret") }, true},
+		{"wrong token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer other") }, false},
+		{"empty token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/admin/config", nil)
+			tt.setup(req)
+			if _, _, ok := authenticate(req, auth); ok != tt.expected {
+				t.Errorf("authenticate() = %v, want %v", ok, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSecretMatchesHashed(t *testing.T) {
+	// sha256("secret")
+	hashed := "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+	if !secretMatches(hashed, "secret") {
+		t.Error("expected hashed secret to match")
+	}
+	if secretMatches(hashed, "Secret") {
+		t.Error("expected hashed secret not to match a different value")
+	}
+}
+
+func TestAuthenticateReadonlyRole(t *testing.T) {
+	auth := config.AdminAuth{
+		Users:          map[string]string{"alice": "wonderland"},
+		ReadonlyUsers:  map[string]string{"bob": "viewer"},
+		ReadonlyTokens: []string{"dashboard-token"},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/config", nil)
+	req.SetBasicAuth("bob", "viewer")
+	if user, role, ok := authenticate(req, auth); !ok || user != "bob" || role != roleReadonly {
+		t.Errorf("authenticate() = (%q, %q, %v), want (bob, %s, true)", user, role, ok, roleReadonly)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/admin/config", nil)
+	req.Header.Set("Authorization", "Bearer dashboard-token")
+	if _, role, ok := authenticate(req, auth); !ok || role != roleReadonly {
+		t.Errorf("authenticate() role = %q, ok = %v, want %s, true", role, ok, roleReadonly)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/admin/config", nil)
+	req.SetBasicAuth("alice", "wonderland")
+	if _, role, ok := authenticate(req, auth); !ok || role != roleAdmin {
+		t.Errorf("authenticate() role = %q, ok = %v, want %s, true", role, ok, roleAdmin)
+	}
+}
+
+func TestReadonlyRoleValidates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Admin: config.AdminConfig{Enabled: true, Auth: config.AdminAuth{
+		Tokens:         []string{"admin-token"},
+		ReadonlyTokens: []string{"dashboard-token"},
+	}}})
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	definition := "path: /ping\nmethod: GET\n"
+	for path, want := range map[string]int{
+		"/admin/endpoints/validate": http.StatusOK,
+		"/admin/endpoints":          http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(definition))
+		req.Header.Set("Authorization", "Bearer dashboard-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("POST %s: status = %d, want %d: %s", path, w.Code, want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/bundle"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleExportBundle downloads the running config, runtime endpoints
+// included, with all the files it refers to as one archive
+func (s *Server) handleExportBundle(c *gin.Context) {
+	cfg := s.configManager.GetConfig()
+	if cfg == nil {
+		respondError(c, http.StatusServiceUnavailable, "NOT_READY", "configuration not loaded")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Export(&buf, cfg); err != nil {
+		respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="mock-bundle.tar.gz"`)
+	c.Data(http.StatusOK, "application/gzip", buf.Bytes())
+}
+
+// handleImportBundle extracts a posted bundle below <mocks_dir>/bundles and
+// applies its config. Like snapshot restores, the current admin credentials
+// and server.allow_exec_responders are kept. Bundles with validation errors
+// are rejected, and the extracted files of a failed import are removed.
+func (s *Server) handleImportBundle(c *gin.Context) {
+	dir := filepath.Join(s.mocksRoot(), "bundles", strconv.FormatInt(time.Now().UnixNano(), 10))
+	applied := false
+	defer func() {
+		if !applied {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	cfg, err := bundle.Import(c.Request.Body, dir)
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	if s.rejectExecResponders(c, cfg.Endpoints) {
+		return
+	}
+	if current := s.configManager.GetBaseConfig(); current != nil {
+		cfg.Admin.Auth = current.Admin.Auth
+		cfg.Server.AllowExecResponders = current.Server.AllowExecResponders
+	}
+	issues := config.Validate(cfg)
+	if rejectInvalid(c, issues, false) {
+		return
+	}
+
+	s.configManager.SetConfig(cfg)
+	applied = true
+	s.eventBus.Publish(events.TypeConfigReloaded, gin.H{
+		"source":            "bundle",
+		"endpoints_count":   len(cfg.Endpoints),
+		"config_generation": s.configManager.Generation(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":          "applied",
+		"dir":             dir,
+		"endpoints_count": len(cfg.Endpoints),
+		"warnings":        issues,
+	})
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/bundle"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImportBundleRejectsInvalidConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	src := filepath.Join(t.TempDir(), "user.json")
+	if err := os.WriteFile(src, []byte(`{"name":"alice"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var archive bytes.Buffer
+	err := bundle.Export(&archive, &config.Config{Endpoints: []config.Endpoint{{
+		Path: "/users", Method: "GET",
+		Default: config.ResponseConfig{ResponseFile: src},
+		Rules:   []config.Rule{{Conditions: []config.Condition{{Selector: "missing", MatchType: "exact", Value: "x"}}}},
+	}}})
+	if err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+
+	mocksDir := t.TempDir()
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Admin: config.AdminConfig{Enabled: true, MocksDir: mocksDir}})
+	generation := cm.Generation()
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/bundle", &archive))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if cm.Generation() != generation {
+		t.Error("expected the running config to stay active")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(mocksDir, "bundles")); len(entries) != 0 {
+		t.Errorf("expected the extracted files to be removed, found %v", entries)
+	}
+}
@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"mock-api-server/pkg/chaos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setChaosRequest configures global chaos; duration_sec sets an expiry relative to now
+type setChaosRequest struct {
+	chaos.Settings
+	DurationSec int `json:"duration_sec"`
+}
+
+// handleGetChaos returns the active chaos settings
+func (s *Server) handleGetChaos(c *gin.Context) {
+	settings, active := s.chaos.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"active":   active,
+		"settings": settings,
+	})
+}
+
+// handleSetChaos replaces the global chaos settings
+func (s *Server) handleSetChaos(c *gin.Context) {
+	var req setChaosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if req.LatencyMs < 0 {
+		badRequest(c, "latency_ms must not be negative")
+		return
+	}
+	if req.ErrorRate < 0 || req.ErrorRate > 100 {
+		badRequest(c, "error_rate must be between 0 and 100")
+		return
+	}
+	if req.LatencyRate < 0 || req.LatencyRate > 100 {
+		badRequest(c, "latency_rate must be between 0 and 100")
+		return
+	}
+	if req.ErrorStatus != 0 && (req.ErrorStatus < 100 || req.ErrorStatus > 599) {
+		badRequest(c, "error_status must be a valid HTTP status code")
+		return
+	}
+	for status := range req.ErrorStatuses {
+		if status < 100 || status > 599 {
+			badRequest(c, "error_statuses must use valid HTTP status codes")
+			return
+		}
+	}
+	if req.DurationSec > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.DurationSec) * time.Second)
+		req.ExpiresAt = &expiresAt
+	}
+
+	s.chaos.Set(req.Settings)
+	s.handleGetChaos(c)
+}
+
+// handleClearChaos disables global chaos
+func (s *Server) handleClearChaos(c *gin.Context) {
+	s.chaos.Clear()
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package admin
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleReplaceConfig replaces the whole running config with the posted document.
+// The document is parsed and validated before the swap, so on parse or
+// validation errors the previous config stays active. With ?strict=true
+// validation warnings also reject the document.
+func (s *Server) handleReplaceConfig(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		badRequest(c, "failed to read request body: "+err.Error())
+		return
+	}
+	if len(data) == 0 {
+		badRequest(c, "config document is empty")
+		return
+	}
+
+	newCfg, err := config.ParseConfig(data, s.configManager.GetConfigPath())
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	if s.rejectExecResponders(c, newCfg.Endpoints) {
+		return
+	}
+	// The opt-in is not something the admin API can grant itself
+	if current := s.configManager.GetBaseConfig(); current != nil {
+		newCfg.Server.AllowExecResponders = current.Server.AllowExecResponders
+	}
+
+	issues := s.validateDocument(newCfg)
+	if rejectInvalid(c, issues, c.Query("strict") == "true") {
+		return
+	}
+
+	s.configManager.SetConfig(newCfg)
+	s.eventBus.Publish(events.TypeConfigReloaded, gin.H{
+		"source":            "admin",
+		"endpoints_count":   len(newCfg.Endpoints),
+		"config_generation": s.configManager.Generation(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":          "applied",
+		"loaded_at":       s.configManager.GetLoadedAt().Format("2006-01-02T15:04:05Z07:00"),
+		"endpoints_count": len(newCfg.Endpoints),
+		"warnings":        issues,
+	})
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// handleValidateConfig parses and validates a posted config document without applying it
+func (s *Server) handleValidateConfig(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		badRequest(c, "failed to read request body: "+err.Error())
+		return
+	}
+
+	errs := []config.Issue{}
+	warns := []config.Issue{}
+
+	if len(data) == 0 {
+		errs = append(errs, config.Issue{Code: "empty_document", Severity: config.SeverityError, Message: "config document is empty"})
+	} else if cfg, err := config.ParseConfig(data, s.configManager.GetConfigPath()); err != nil {
+		issue := config.Issue{Code: "parse_error", Severity: config.SeverityError, Message: err.Error()}
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+		}
+		errs = append(errs, issue)
+	} else {
+		errs, warns = splitIssues(s.validateDocument(cfg))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":    len(errs) == 0,
+		"errors":   errs,
+		"warnings": warns,
+	})
+}
+
+// validateDocument validates a config parsed from a posted document. Issues
+// in the document itself carry its line numbers but not the path of the
+// config file on disk, which the document does not come from.
+func (s *Server) validateDocument(cfg *config.Config) []config.Issue {
+	issues := config.Validate(cfg)
+	for i := range issues {
+		if issues[i].File == s.configManager.GetConfigPath() {
+			issues[i].File = ""
+		}
+	}
+	return issues
+}
+
+// splitIssues separates validation issues by severity
+func splitIssues(issues []config.Issue) (errs, warns []config.Issue) {
+	errs, warns = []config.Issue{}, []config.Issue{}
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			errs = append(errs, issue)
+		} else {
+			warns = append(warns, issue)
+		}
+	}
+	return errs, warns
+}
+
+// rejectInvalid answers 422 when a config or endpoint to apply has validation
+// errors, or with strict any warnings, and reports whether it did
+func rejectInvalid(c *gin.Context, issues []config.Issue, strict bool) bool {
+	errs, warns := splitIssues(issues)
+	message := "validation found errors"
+	switch {
+	case len(errs) > 0:
+	case strict && len(warns) > 0:
+		message = "validation found warnings and strict mode is enabled"
+	default:
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error": gin.H{
+			"code":    "VALIDATION_FAILED",
+			"message": message,
+		},
+		"errors":   errs,
+		"warnings": warns,
+	})
+	return true
+}
@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateConfigReturnsIssues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{configManager: config.NewConfigManager("config.yaml")}
+	router := gin.New()
+	router.POST("/admin/config/validate", s.handleValidateConfig)
+
+	validate := func(doc string) (bool, []config.Issue, []config.Issue) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/config/validate", strings.NewReader(doc)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Valid    bool           `json:"valid"`
+			Errors   []config.Issue `json:"errors"`
+			Warnings []config.Issue `json:"warnings"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		return resp.Valid, resp.Errors, resp.Warnings
+	}
+
+	valid, errs, warns := validate(`state:
+  backend: redis
+  persist_file: state.json
+endpoints:
+  - path: /users
+    method: GET
+    rules:
+      - conditions:
+          - selector: missing
+            match_type: exact
+            value: x
+`)
+	if valid || len(errs) != 1 || len(warns) != 1 {
+		t.Fatalf("valid=%v errors=%+v warnings=%+v", valid, errs, warns)
+	}
+	if e := errs[0]; e.Code != "unknown_selector" || e.Location != "endpoint[0].rule[0].condition[0]" || e.Line != 9 || e.File != "" {
+		t.Errorf("unexpected error %+v", e)
+	}
+	if w := warns[0]; w.Code != "ignored_setting" || w.Line != 3 {
+		t.Errorf("unexpected warning %+v", w)
+	}
+
+	valid, errs, _ = validate("endpoints: [")
+	if valid || len(errs) != 1 || errs[0].Code != "parse_error" || errs[0].Line == 0 {
+		t.Errorf("valid=%v errors=%+v", valid, errs)
+	}
+}
+
+func TestReplaceConfigKeepsRunningConfigOnErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{ID: "ping", Path: "/ping", Method: "GET"}}})
+	generation := cm.Generation()
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	replace := func(query, doc string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/config"+query, strings.NewReader(doc)))
+		return w
+	}
+
+	invalid := `endpoints:
+  - path: /users
+    method: GET
+    rules:
+      - conditions:
+          - selector: missing
+            match_type: exact
+            value: x
+`
+	w := replace("", invalid)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Errors []config.Issue `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Code != "unknown_selector" {
+		t.Errorf("errors = %+v, want one unknown_selector issue", resp.Errors)
+	}
+	if cm.Generation() != generation {
+		t.Fatal("expected the running config to stay active")
+	}
+
+	warning := "state:\n  backend: redis\n  persist_file: state.json\nendpoints:\n  - path: /users\n    method: GET\n"
+	if w := replace("?strict=true", warning); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("strict: expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if cm.Generation() != generation {
+		t.Fatal("expected strict mode to keep the running config")
+	}
+	if w := replace("", warning); w.Code != http.StatusOK {
+		t.Fatalf("expected warnings alone to apply, got %d: %s", w.Code, w.Body.String())
+	}
+	if cfg := cm.GetConfig(); len(cfg.Endpoints) != 1 || cfg.Endpoints[0].Path != "/users" {
+		t.Errorf("expected the new config to be applied, got %+v", cfg.Endpoints)
+	}
+}
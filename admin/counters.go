@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// incrementCounterRequest adjusts a counter; By defaults to 1
+type incrementCounterRequest struct {
+	By *int64 `json:"by"`
+}
+
+// handleListCounters returns every named counter
+func (s *Server) handleListCounters(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"counters": s.scenarioStore.Counters()})
+}
+
+// handleGetCounter returns one counter; unknown counters read as 0
+func (s *Server) handleGetCounter(c *gin.Context) {
+	name := c.Param("name")
+	c.JSON(http.StatusOK, gin.H{"name": name, "value": s.scenarioStore.GetCounter(name)})
+}
+
+// handleIncrementCounter adds to a counter and returns the new value
+func (s *Server) handleIncrementCounter(c *gin.Context) {
+	var req incrementCounterRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			badRequest(c, "invalid request: "+err.Error())
+			return
+		}
+	}
+	by := int64(1)
+	if req.By != nil {
+		by = *req.By
+	}
+
+	name := c.Param("name")
+	c.JSON(http.StatusOK, gin.H{"name": name, "value": s.scenarioStore.Increment(name, by)})
+}
+
+// handleResetCounter sets one counter back to 0
+func (s *Server) handleResetCounter(c *gin.Context) {
+	name := c.Param("name")
+	s.scenarioStore.ResetCounter(name)
+	c.JSON(http.StatusOK, gin.H{"name": name, "value": 0})
+}
+
+// handleResetCounters sets every counter back to 0
+func (s *Server) handleResetCounters(c *gin.Context) {
+	s.scenarioStore.ResetCounter("")
+	c.JSON(http.StatusOK, gin.H{"cleared": []string{"counters"}})
+}
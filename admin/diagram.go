@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// anyStep is the pseudo step of transitions that apply in every step
+const anyStep = "*"
+
+// flowEdge is one transition of a scenario flow
+type flowEdge struct {
+	From     string
+	To       string
+	Label    string
+	Observed bool // taken from the transition history instead of config
+}
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// handleGetScenarioDiagram renders the transitions configured for a scenario
+// as Mermaid (default) or Graphviz (?format=dot). ?observed=true adds the
+// transitions recorded in the history.
+func (s *Server) handleGetScenarioDiagram(c *gin.Context) {
+	name := c.Param("name")
+	cfg := s.configManager.GetConfig()
+	if cfg == nil {
+		respondError(c, http.StatusServiceUnavailable, "NOT_READY", "configuration not loaded")
+		return
+	}
+
+	edges := configuredEdges(cfg, name)
+	if c.Query("observed") == "true" {
+		edges = append(edges, observedEdges(s.scenarioStore.History(name, ""))...)
+	}
+
+	switch c.DefaultQuery("format", "mermaid") {
+	case "mermaid":
+		c.String(http.StatusOK, renderMermaid(edges))
+	case "dot":
+		c.String(http.StatusOK, renderDOT(name, edges))
+	default:
+		badRequest(c, "format must be mermaid or dot")
+	}
+}
+
+// configuredEdges collects the new_step transitions of every endpoint bound
+// to the scenario, labelled with the request that triggers them
+func configuredEdges(cfg *config.Config, name string) []flowEdge {
+	var edges []flowEdge
+	for _, ep := range cfg.Endpoints {
+		if ep.Scenario != name {
+			continue
+		}
+		trigger := strings.ToUpper(ep.Method) + " " + ep.Path
+		for i, rule := range ep.Rules {
+			if rule.NewStep == "" {
+				continue
+			}
+			from := rule.RequiredStep
+			if from == "" {
+				from = anyStep
+			}
+			edges = append(edges, flowEdge{From: from, To: rule.NewStep, Label: fmt.Sprintf("%s (rule_%d)", trigger, i)})
+		}
+		if ep.Default.NewStep != "" {
+			edges = append(edges, flowEdge{From: anyStep, To: ep.Default.NewStep, Label: trigger + " (default)"})
+		}
+	}
+	return edges
+}
+
+// observedEdges aggregates recorded transitions by from/to step
+func observedEdges(history []state.Transition) []flowEdge {
+	counts := make(map[[2]string]int)
+	var order [][2]string
+	for _, t := range history {
+		key := [2]string{t.PreviousStep, t.Step}
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+
+	edges := make([]flowEdge, 0, len(order))
+	for _, key := range order {
+		edges = append(edges, flowEdge{From: key[0], To: key[1], Label: fmt.Sprintf("observed x%d", counts[key]), Observed: true})
+	}
+	return edges
+}
+
+// flowSteps returns every step used by edges, the default step first
+func flowSteps(edges []flowEdge) []string {
+	seen := map[string]bool{state.DefaultStep: true}
+	var steps []string
+	for _, e := range edges {
+		for _, step := range []string{e.From, e.To} {
+			if !seen[step] {
+				seen[step] = true
+				steps = append(steps, step)
+			}
+		}
+	}
+	sort.Strings(steps)
+	return append([]string{state.DefaultStep}, steps...)
+}
+
+// stepID turns a step name into an identifier both diagram languages accept
+func stepID(step string) string {
+	if step == anyStep {
+		return "any_step"
+	}
+	return "s_" + nonIdentChars.ReplaceAllString(step, "_")
+}
+
+func stepLabel(step string) string {
+	if step == anyStep {
+		return "(any step)"
+	}
+	return step
+}
+
+func renderMermaid(edges []flowEdge) string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	for _, step := range flowSteps(edges) {
+		fmt.Fprintf(&b, "    state \"%s\" as %s\n", escapeQuotes(stepLabel(step)), stepID(step))
+	}
+	fmt.Fprintf(&b, "    [*] --> %s\n", stepID(state.DefaultStep))
+	for _, e := range edges {
+		fmt.Fprintf(&b, "    %s --> %s : %s\n", stepID(e.From), stepID(e.To), strings.ReplaceAll(e.Label, ":", " "))
+	}
+	return b.String()
+}
+
+func renderDOT(name string, edges []flowEdge) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph \"%s\" {\n", escapeQuotes(name))
+	b.WriteString("    rankdir=LR;\n")
+	for _, step := range flowSteps(edges) {
+		shape := "box"
+		if step == anyStep {
+			shape = "plaintext"
+		}
+		fmt.Fprintf(&b, "    %s [label=\"%s\", shape=%s];\n", stepID(step), escapeQuotes(stepLabel(step)), shape)
+	}
+	for _, e := range edges {
+		style := ""
+		if e.Observed {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "    %s -> %s [label=\"%s\"%s];\n", stepID(e.From), stepID(e.To), escapeQuotes(e.Label), style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func escapeQuotes(s string) string {
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
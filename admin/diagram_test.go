@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestScenarioDiagram(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{Path: "/cart", Method: "post", Scenario: "checkout", Default: config.ResponseConfig{NewStep: "cart"}},
+		{Path: "/pay", Method: "POST", Scenario: "checkout", Rules: []config.Rule{
+			{RequiredStep: "cart", ResponseConfig: config.ResponseConfig{NewStep: "paid-out"}},
+		}},
+		{Path: "/other", Method: "GET", Scenario: "login", Default: config.ResponseConfig{NewStep: "in"}},
+	}})
+	store := state.NewScenarioStore()
+	store.RecordTransition("checkout", state.Transition{PreviousStep: "idle", Step: "cart"})
+	store.RecordTransition("checkout", state.Transition{PreviousStep: "idle", Step: "cart"})
+
+	s := &Server{configManager: cm, scenarioStore: store}
+	router := gin.New()
+	router.GET("/admin/scenarios/:name/diagram", s.handleGetScenarioDiagram)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/scenarios/checkout/diagram"+query, nil))
+		return w
+	}
+
+	w := get("")
+	body := w.Body.String()
+	for _, want := range []string{
+		"stateDiagram-v2",
+		"[*] --> s_idle",
+		`state "paid-out" as s_paid_out`,
+		"any_step --> s_cart : POST /cart (default)",
+		"s_cart --> s_paid_out : POST /pay (rule_0)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "/other") || strings.Contains(body, "observed") {
+		t.Errorf("unexpected edges in mermaid output:\n%s", body)
+	}
+
+	w = get("?format=dot&observed=true")
+	body = w.Body.String()
+	if !strings.HasPrefix(body, `digraph "checkout" {`) || !strings.Contains(body, `s_idle -> s_cart [label="observed x2", style=dashed];`) {
+		t.Errorf("unexpected dot output:\n%s", body)
+	}
+
+	if w := get("?format=svg"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown format, got %d", w.Code)
+	}
+}
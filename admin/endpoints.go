@@ -0,0 +1,387 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// endpointView is the admin representation of a configured endpoint
+type endpointView struct {
+	ID          string   `json:"id"`
+	Path        string   `json:"path"`
+	Method      string   `json:"method"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	BasePath    string   `json:"base_path,omitempty"`
+	Source      string   `json:"source"` // file or runtime
+	Enabled     bool     `json:"enabled"`
+	RulesCount  int      `json:"rules_count"`
+}
+
+const (
+	sourceFile    = "file"
+	sourceRuntime = "runtime"
+)
+
+func (s *Server) newEndpointView(ep *config.Endpoint) endpointView {
+	source := sourceFile
+	if s.configManager.IsRuntimeEndpoint(ep.ID) {
+		source = sourceRuntime
+	}
+	return endpointView{
+		ID:          ep.ID,
+		Path:        ep.Path,
+		Method:      ep.Method,
+		Description: ep.Description,
+		Tags:        ep.Tags,
+		BasePath:    ep.BasePath,
+		Source:      source,
+		Enabled:     s.configManager.IsEndpointEnabled(ep.ID),
+		RulesCount:  len(ep.Rules),
+	}
+}
+
+// findEndpointByID returns the endpoint with the given ID from the current config
+func (s *Server) findEndpointByID(id string) *config.Endpoint {
+	cfg := s.configManager.GetConfig()
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.Endpoints {
+		if cfg.Endpoints[i].ID == id {
+			return &cfg.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+// handleListEndpoints lists configured endpoints. Supported query parameters:
+// method, path_contains, tag, base_path, source (file|runtime), enabled (true|false),
+// sort (id|path|method|source, prefix "-" for descending), limit and offset.
+func (s *Server) handleListEndpoints(c *gin.Context) {
+	views := []endpointView{}
+	if cfg := s.configManager.GetConfig(); cfg != nil {
+		for i := range cfg.Endpoints {
+			view := s.newEndpointView(&cfg.Endpoints[i])
+			if matchesEndpointFilters(view, c) {
+				views = append(views, view)
+			}
+		}
+	}
+
+	if sortField := c.Query("sort"); sortField != "" {
+		if !sortEndpointViews(views, sortField) {
+			badRequest(c, "invalid sort field: "+sortField)
+			return
+		}
+	}
+
+	total := len(views)
+	offset, err := parseNonNegative(c.Query("offset"))
+	if err != nil {
+		badRequest(c, "invalid offset: "+err.Error())
+		return
+	}
+	limit, err := parseNonNegative(c.Query("limit"))
+	if err != nil {
+		badRequest(c, "invalid limit: "+err.Error())
+		return
+	}
+
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"endpoints": views[offset:end],
+		"total":     total,
+		"offset":    offset,
+		"limit":     limit,
+	})
+}
+
+// matchesEndpointFilters applies the list query filters to a view
+func matchesEndpointFilters(view endpointView, c *gin.Context) bool {
+	if method := c.Query("method"); method != "" && !strings.EqualFold(view.Method, method) {
+		return false
+	}
+	if contains := c.Query("path_contains"); contains != "" && !strings.Contains(view.Path, contains) {
+		return false
+	}
+	if tag := c.Query("tag"); tag != "" && !hasTag(view.Tags, tag) {
+		return false
+	}
+	if basePath := c.Query("base_path"); basePath != "" && view.BasePath != strings.TrimRight(basePath, "/") {
+		return false
+	}
+	if source := c.Query("source"); source != "" && view.Source != source {
+		return false
+	}
+	if enabled := c.Query("enabled"); enabled != "" && strconv.FormatBool(view.Enabled) != enabled {
+		return false
+	}
+	return true
+}
+
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortEndpointViews sorts views in place by field; returns false for unknown fields
+func sortEndpointViews(views []endpointView, field string) bool {
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var key func(v endpointView) string
+	switch field {
+	case "id":
+		key = func(v endpointView) string { return v.ID }
+	case "path":
+		key = func(v endpointView) string { return v.Path }
+	case "method":
+		key = func(v endpointView) string { return v.Method }
+	case "source":
+		key = func(v endpointView) string { return v.Source }
+	default:
+		return false
+	}
+
+	sort.SliceStable(views, func(i, j int) bool {
+		if desc {
+			return key(views[i]) > key(views[j])
+		}
+		return key(views[i]) < key(views[j])
+	})
+	return true
+}
+
+// parseNonNegative parses an optional non-negative integer query value
+func parseNonNegative(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return n, nil
+}
+
+// handleEnableEndpoint enables an endpoint by ID
+func (s *Server) handleEnableEndpoint(c *gin.Context) {
+	s.setEndpointEnabled(c, true)
+}
+
+// handleDisableEndpoint disables an endpoint by ID; requests to it get 404
+func (s *Server) handleDisableEndpoint(c *gin.Context) {
+	s.setEndpointEnabled(c, false)
+}
+
+func (s *Server) setEndpointEnabled(c *gin.Context, enabled bool) {
+	id := c.Param("id")
+	ep := s.findEndpointByID(id)
+	if ep == nil {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "endpoint not found: "+id)
+		return
+	}
+
+	s.configManager.SetEndpointEnabled(id, enabled)
+	c.JSON(http.StatusOK, s.newEndpointView(ep))
+}
+
+// handleGetEndpoint returns the full definition of an endpoint by ID
+func (s *Server) handleGetEndpoint(c *gin.Context) {
+	id := c.Param("id")
+	ep := s.findEndpointByID(id)
+	if ep == nil {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "endpoint not found: "+id)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"endpoint":   s.newEndpointView(ep),
+		"definition": ep,
+	})
+}
+
+// handleCreateEndpoint adds a runtime endpoint from a YAML or JSON
+// definition. Definitions with validation errors are rejected with 422.
+func (s *Server) handleCreateEndpoint(c *gin.Context) {
+	ep, ok := bindEndpoint(c)
+	if !ok {
+		return
+	}
+	if s.rejectExecResponders(c, []config.Endpoint{ep}) {
+		return
+	}
+
+	issues := s.endpointIssues(ep)
+	if rejectInvalid(c, issues, false) {
+		return
+	}
+
+	created, err := s.configManager.AddRuntimeEndpoint(ep)
+	if err != nil {
+		respondEndpointError(c, err, ep.ID)
+		return
+	}
+
+	s.eventBus.Publish(events.TypeEndpointAdded, s.newEndpointView(&created))
+	errs, warns := splitIssues(issues)
+	c.JSON(http.StatusCreated, gin.H{
+		"endpoint": s.newEndpointView(&created),
+		"errors":   errs,
+		"warnings": warns,
+	})
+}
+
+// handleUpdateEndpoint replaces a runtime endpoint by ID, rejecting
+// definitions with validation errors like handleCreateEndpoint
+func (s *Server) handleUpdateEndpoint(c *gin.Context) {
+	ep, ok := bindEndpoint(c)
+	if !ok {
+		return
+	}
+	if s.rejectExecResponders(c, []config.Endpoint{ep}) {
+		return
+	}
+
+	issues := s.endpointIssues(ep)
+	if rejectInvalid(c, issues, false) {
+		return
+	}
+
+	id := c.Param("id")
+	updated, err := s.configManager.UpdateRuntimeEndpoint(id, ep)
+	if err != nil {
+		respondEndpointError(c, err, id)
+		return
+	}
+
+	s.eventBus.Publish(events.TypeEndpointUpdated, s.newEndpointView(&updated))
+	errs, warns := splitIssues(issues)
+	c.JSON(http.StatusOK, gin.H{
+		"endpoint": s.newEndpointView(&updated),
+		"errors":   errs,
+		"warnings": warns,
+	})
+}
+
+// handleValidateEndpoint checks an endpoint definition without adding it
+func (s *Server) handleValidateEndpoint(c *gin.Context) {
+	ep, ok := bindEndpoint(c)
+	if !ok {
+		return
+	}
+	errs, warns := splitIssues(s.endpointIssues(ep))
+	c.JSON(http.StatusOK, gin.H{
+		"valid":    len(errs) == 0,
+		"errors":   errs,
+		"warnings": warns,
+	})
+}
+
+// handleDeleteEndpoint removes a runtime endpoint by ID
+func (s *Server) handleDeleteEndpoint(c *gin.Context) {
+	id := c.Param("id")
+	if err := s.configManager.DeleteRuntimeEndpoint(id); err != nil {
+		respondEndpointError(c, err, id)
+		return
+	}
+
+	s.eventBus.Publish(events.TypeEndpointDeleted, gin.H{"id": id})
+	c.Status(http.StatusNoContent)
+}
+
+// bindEndpoint decodes an endpoint definition from the request body. YAML is
+// used so the field names match the config files; JSON is valid YAML.
+func bindEndpoint(c *gin.Context) (config.Endpoint, bool) {
+	var ep config.Endpoint
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		badRequest(c, "failed to read request body: "+err.Error())
+		return ep, false
+	}
+	if err := yaml.Unmarshal(data, &ep); err != nil {
+		badRequest(c, "invalid endpoint definition: "+err.Error())
+		return ep, false
+	}
+	if !strings.HasPrefix(ep.Path, "/") {
+		badRequest(c, "path is required and must start with '/'")
+		return ep, false
+	}
+	if ep.Method == "" && ep.Mode != config.EndpointModeCRUD && ep.Mode != config.EndpointModeSink {
+		badRequest(c, "method is required")
+		return ep, false
+	}
+	return ep, true
+}
+
+// endpointIssues runs config validation on a single endpoint
+func (s *Server) endpointIssues(ep config.Endpoint) []config.Issue {
+	cfg := &config.Config{
+		Server:    config.ServerConfig{AllowExecResponders: s.execAllowed()},
+		Endpoints: []config.Endpoint{ep},
+	}
+	return config.Validate(cfg)
+}
+
+// execAllowed reports whether definitions posted to the admin API may run
+// responder commands: the server must opt in and admin auth be configured
+func (s *Server) execAllowed() bool {
+	current := s.configManager.GetBaseConfig()
+	return current != nil && current.Server.AllowExecResponders && authEnabled(current.Admin.Auth)
+}
+
+// rejectExecResponders answers 403 when one of the admin-supplied endpoints
+// runs a responder command and execAllowed does not permit it
+func (s *Server) rejectExecResponders(c *gin.Context, endpoints []config.Endpoint) bool {
+	if s.execAllowed() {
+		return false
+	}
+	for _, ep := range endpoints {
+		if ep.UsesCommandResponder() {
+			respondError(c, http.StatusForbidden, "EXEC_DISABLED",
+				"endpoint "+ep.ID+" uses a responder command, which the admin API only accepts with server.allow_exec_responders and admin auth")
+			return true
+		}
+	}
+	return false
+}
+
+// respondEndpointError maps ConfigManager errors to HTTP responses
+func respondEndpointError(c *gin.Context, err error, id string) {
+	switch {
+	case errors.Is(err, config.ErrEndpointNotFound):
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "endpoint not found: "+id)
+	case errors.Is(err, config.ErrEndpointExists):
+		respondError(c, http.StatusConflict, "CONFLICT", "endpoint id already exists: "+id)
+	case errors.Is(err, config.ErrNotRuntimeEndpoint):
+		respondError(c, http.StatusConflict, "CONFLICT", err.Error())
+	default:
+		respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
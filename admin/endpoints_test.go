@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestListEndpointsFiltersAndPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "c", Path: "/users/:id", Method: "GET", Tags: []string{"users"}},
+		{ID: "a", Path: "/users", Method: "POST", Tags: []string{"Users", "write"}},
+		{ID: "b", Path: "/orders", Method: "GET"},
+	}})
+	if _, err := cm.AddRuntimeEndpoint(config.Endpoint{ID: "d", Path: "/users/me", Method: "GET"}); err != nil {
+		t.Fatalf("AddRuntimeEndpoint returned error: %v", err)
+	}
+
+	s := &Server{configManager: cm}
+	router := gin.New()
+	router.GET("/admin/endpoints", s.handleListEndpoints)
+
+	tests := []struct {
+		query   string
+		total   int
+		wantIDs []string
+	}{
+		{"", 4, []string{"d", "c", "a", "b"}},
+		{"?method=get&sort=path", 3, []string{"b", "c", "d"}},
+		{"?path_contains=/users&sort=-id&limit=2", 3, []string{"d", "c"}},
+		{"?path_contains=/users&sort=-id&limit=2&offset=2", 3, []string{"a"}},
+		{"?source=runtime", 1, []string{"d"}},
+		{"?tag=users&sort=id", 2, []string{"a", "c"}},
+		{"?offset=10", 4, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/endpoints"+tt.query, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var body struct {
+				Endpoints []endpointView `json:"endpoints"`
+				Total     int            `json:"total"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if body.Total != tt.total {
+				t.Errorf("total = %d, want %d", body.Total, tt.total)
+			}
+			ids := make([]string, len(body.Endpoints))
+			for i, ep := range body.Endpoints {
+				ids[i] = ep.ID
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/endpoints?sort=bogus", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid sort, got %d", w.Code)
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{})
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	tests := []struct {
+		name  string
+		body  string
+		code  int
+		valid bool
+	}{
+		{"valid", "path: /ping\nmethod: GET\ndefault:\n  status_code: 200\n", http.StatusOK, true},
+		{"invalid selector", "path: /ping\nmethod: GET\nselectors:\n  - name: x\n    type: cookie_jar\n", http.StatusOK, false},
+		{"missing path", "method: GET\n", http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/endpoints/validate", strings.NewReader(tt.body)))
+			if w.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			var body struct {
+				Valid    bool           `json:"valid"`
+				Errors   []config.Issue `json:"errors"`
+				Warnings []config.Issue `json:"warnings"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if body.Valid != tt.valid {
+				t.Errorf("valid = %v, want %v (errors %v)", body.Valid, tt.valid, body.Errors)
+			}
+			if !tt.valid && (len(body.Errors) != 1 || body.Errors[0].Code != "invalid_selector_type") {
+				t.Errorf("errors = %v, want one invalid_selector_type issue", body.Errors)
+			}
+		})
+	}
+
+	if cfg := cm.GetConfig(); len(cfg.Endpoints) != 0 {
+		t.Errorf("expected validation not to add endpoints, got %d", len(cfg.Endpoints))
+	}
+}
+
+func TestCreateAndUpdateRejectInvalidEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{})
+	if _, err := cm.AddRuntimeEndpoint(config.Endpoint{ID: "ping", Path: "/ping", Method: "GET"}); err != nil {
+		t.Fatalf("AddRuntimeEndpoint returned error: %v", err)
+	}
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	invalid := "path: /users\nmethod: GET\nselectors:\n  - name: x\n    type: cookie_jar\n"
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/admin/endpoints", strings.NewReader(invalid)),
+		httptest.NewRequest(http.MethodPut, "/admin/endpoints/ping", strings.NewReader(invalid)),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s: expected 422, got %d: %s", req.Method, w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "invalid_selector_type") {
+			t.Errorf("%s: expected the validation error in the response, got %s", req.Method, w.Body.String())
+		}
+	}
+
+	eps := cm.GetConfig().Endpoints
+	if len(eps) != 1 || eps[0].Path != "/ping" {
+		t.Errorf("expected only the unchanged ping endpoint, got %+v", eps)
+	}
+}
+
+func TestExecRespondersRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	endpoint := "path: /quote\nmethod: GET\ndefault:\n  responder:\n    command: [\"sh\", \"-c\", \"id\"]\n"
+	document := "endpoints:\n  - path: /quote\n    method: GET\n    default:\n      responder:\n        command: [\"sh\", \"-c\", \"id\"]\n"
+	auth := config.AdminAuth{Tokens: []string{"secret"}}
+
+	tests := []struct {
+		name   string
+		server config.ServerConfig
+		auth   config.AdminAuth
+		code   int
+	}{
+		{"not opted in", config.ServerConfig{}, auth, http.StatusForbidden},
+		{"opted in without admin auth", config.ServerConfig{AllowExecResponders: true}, config.AdminAuth{}, http.StatusForbidden},
+		{"opted in with admin auth", config.ServerConfig{AllowExecResponders: true}, auth, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := config.NewConfigManager("config.yaml")
+			cm.SetConfig(&config.Config{Server: tt.server, Admin: config.AdminConfig{Enabled: true, Auth: tt.auth}})
+			router := gin.New()
+			NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/admin/endpoints", strings.NewReader(endpoint))
+			req.Header.Set("Authorization", "Bearer secret")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.code {
+				t.Fatalf("create: expected %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code != http.StatusForbidden {
+				return
+			}
+			if !strings.Contains(w.Body.String(), "EXEC_DISABLED") {
+				t.Errorf("expected EXEC_DISABLED, got %s", w.Body.String())
+			}
+			if n := len(cm.GetConfig().Endpoints); n != 0 {
+				t.Errorf("expected no endpoint to be added, got %d", n)
+			}
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPut, "/admin/config", strings.NewReader(document))
+			req.Header.Set("Authorization", "Bearer secret")
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("replace config: expected 403, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
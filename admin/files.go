@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errPathOutsideRoot = errors.New("path escapes the mocks directory")
+
+// fileInfoView describes a file under the mocks directory
+type fileInfoView struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime string `json:"mod_time"`
+}
+
+// mocksRoot returns the configured mocks directory
+func (s *Server) mocksRoot() string {
+	if cfg := s.configManager.GetConfig(); cfg != nil && cfg.Admin.MocksDir != "" {
+		return cfg.Admin.MocksDir
+	}
+	return "./mocks"
+}
+
+// resolveMockPath maps a request path onto the mocks directory, rejecting
+// anything that would resolve outside of it, including through symlinks
+func resolveMockPath(root, requestPath string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	// Cleaning against "/" drops any leading ".." segments
+	cleaned := filepath.Clean("/" + filepath.FromSlash(requestPath))
+	full := filepath.Join(absRoot, cleaned)
+
+	realRoot, err := evalExistingSymlinks(absRoot)
+	if err != nil {
+		return "", err
+	}
+	realFull, err := evalExistingSymlinks(full)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(realRoot, realFull)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errPathOutsideRoot
+	}
+	return full, nil
+}
+
+// evalExistingSymlinks resolves symlinks in the longest existing prefix of
+// path, so files about to be created are checked by their parent directory.
+// A dangling symlink is resolved to its target, which a write would create.
+func evalExistingSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		return evalExistingSymlinks(target)
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	resolvedParent, err := evalExistingSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(path)), nil
+}
+
+// handleGetFile returns a file's content, or lists files when the path is a directory
+func (s *Server) handleGetFile(c *gin.Context) {
+	root := s.mocksRoot()
+	full, err := resolveMockPath(root, c.Param("path"))
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	info, err := os.Stat(full)
+	if err != nil {
+		respondFileError(c, err)
+		return
+	}
+
+	if info.IsDir() {
+		files, err := listFiles(full, root)
+		if err != nil {
+			respondFileError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"root": root, "files": files})
+		return
+	}
+
+	content, err := os.ReadFile(full)
+	if err != nil {
+		respondFileError(c, err)
+		return
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(full))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Data(http.StatusOK, contentType, content)
+}
+
+// handlePutFile creates or overwrites a file with the request body
+func (s *Server) handlePutFile(c *gin.Context) {
+	full, err := resolveMockPath(s.mocksRoot(), c.Param("path"))
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+	if info, err := os.Stat(full); err == nil && info.IsDir() {
+		badRequest(c, "path is a directory")
+		return
+	}
+
+	content, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		badRequest(c, "failed to read request body: "+err.Error())
+		return
+	}
+
+	_, statErr := os.Stat(full)
+	created := os.IsNotExist(statErr)
+
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		respondFileError(c, err)
+		return
+	}
+	if err := os.WriteFile(full, content, 0o644); err != nil {
+		respondFileError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"path": c.Param("path"), "size": len(content)})
+}
+
+// handleDeleteFile removes a single file
+func (s *Server) handleDeleteFile(c *gin.Context) {
+	full, err := resolveMockPath(s.mocksRoot(), c.Param("path"))
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+	if info, err := os.Stat(full); err == nil && info.IsDir() {
+		badRequest(c, "path is a directory")
+		return
+	}
+
+	if err := os.Remove(full); err != nil {
+		respondFileError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// listFiles walks dir and returns all regular files relative to root
+func listFiles(dir, root string) ([]fileInfoView, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []fileInfoView{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, fileInfoView{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime().Format("2006-01-02T15:04:05Z07:00"),
+		})
+		return nil
+	})
+	return files, err
+}
+
+// respondFileError maps filesystem errors to HTTP responses
+func respondFileError(c *gin.Context, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "file not found")
+		return
+	}
+	respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+}
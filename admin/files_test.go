@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveMockPath(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"nested file", "/user/admin.json", filepath.Join(root, "user", "admin.json"), false},
+		{"root", "/", root, false},
+		{"parent traversal", "/../secret.txt", filepath.Join(root, "secret.txt"), false},
+		{"deep traversal", "/user/../../../etc/passwd", filepath.Join(root, "etc", "passwd"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveMockPath(root, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveMockPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveMockPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMockPathSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"escape":   outside,
+		"file":     filepath.Join(outside, "secret.txt"),
+		"dangling": filepath.Join(outside, "new.txt"),
+		"inside":   filepath.Join(root, "data"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks unsupported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/escape/secret.txt", true},
+		{"/escape/created.txt", true},
+		{"/file", true},
+		{"/dangling", true},
+		{"/inside/user.json", false},
+		{"/data/new/user.json", false},
+	}
+	for _, tt := range tests {
+		if _, err := resolveMockPath(root, tt.path); (err != nil) != tt.wantErr {
+			t.Errorf("resolveMockPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setHealthRequest forces the health endpoints to fail with status_code (default 503)
+type setHealthRequest struct {
+	StatusCode int    `json:"status_code"`
+	Message    string `json:"message"`
+}
+
+// handleGetHealth returns the forced health state
+func (s *Server) handleGetHealth(c *gin.Context) {
+	if s.health == nil {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "health check is not available")
+		return
+	}
+
+	statusCode, message, forced := s.health.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"forced_unhealthy": forced,
+		"status_code":      statusCode,
+		"message":          message,
+	})
+}
+
+// handleSetHealth forces /health and /health/ready to report unhealthy
+func (s *Server) handleSetHealth(c *gin.Context) {
+	if s.health == nil {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "health check is not available")
+		return
+	}
+
+	var req setHealthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if req.StatusCode != 0 && (req.StatusCode < 100 || req.StatusCode > 599) {
+		badRequest(c, "status_code must be a valid HTTP status code")
+		return
+	}
+
+	s.health.ForceUnhealthy(req.StatusCode, req.Message)
+	s.handleGetHealth(c)
+}
+
+// handleClearHealth restores normal health reporting
+func (s *Server) handleClearHealth(c *gin.Context) {
+	if s.health != nil {
+		s.health.Clear()
+	}
+	c.Status(http.StatusNoContent)
+}
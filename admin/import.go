@@ -0,0 +1,190 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+	"mock-api-server/pkg/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	openAPIParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+	unsafeFileChars     = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	unsafeParamChars    = regexp.MustCompile(`\W+`)
+)
+
+// importSkip reports an operation that was not imported
+type importSkip struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// handleImportOpenAPI creates runtime endpoints for every operation of an
+// uploaded OpenAPI document. The lowest 2xx response becomes the default
+// response; its body is written to <mocks_dir>/openapi. Existing endpoints
+// are skipped unless ?replace=true, which only replaces runtime endpoints.
+func (s *Server) handleImportOpenAPI(c *gin.Context) {
+	data, err := readUpload(c)
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	doc, err := openapi.Parse(data)
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	replace := c.Query("replace") == "true"
+	outDir := filepath.Join(s.mocksRoot(), "openapi")
+
+	created := []endpointView{}
+	skipped := []importSkip{}
+
+	for _, op := range doc.Operations() {
+		ep := config.Endpoint{
+			ID:          op.OperationID,
+			Path:        importPath(op.Path),
+			Method:      op.Method,
+			Description: op.Summary,
+			Tags:        op.Tags,
+		}
+		if ep.ID == "" {
+			ep.ID = config.EndpointID(ep.Method, ep.Path)
+		}
+
+		if !replace && s.findEndpointByID(ep.ID) != nil {
+			skipped = append(skipped, importSkip{Method: op.Method, Path: op.Path, Reason: config.ErrEndpointExists.Error()})
+			continue
+		}
+
+		// The body is staged beside its final name and only moved into place
+		// once the endpoint is accepted, so rejected operations leave no files
+		var staged string
+		resp, ok := op.PrimaryResponse()
+		ep.Default.StatusCode = http.StatusOK
+		if ok {
+			if resp.Status != 0 {
+				ep.Default.StatusCode = resp.Status
+			}
+			if body := doc.Example(resp); body != nil {
+				tmp, file, err := stageImportedBody(outDir, ep.ID, ep.Default.StatusCode, body)
+				if err != nil {
+					skipped = append(skipped, importSkip{Method: op.Method, Path: op.Path, Reason: err.Error()})
+					continue
+				}
+				staged = tmp
+				ep.Default.ResponseFile = file
+			}
+		}
+
+		added, err := s.configManager.AddRuntimeEndpoint(ep)
+		isNew := err == nil
+		if errors.Is(err, config.ErrEndpointExists) {
+			added, err = s.configManager.UpdateRuntimeEndpoint(ep.ID, ep)
+		}
+		if err == nil && staged != "" {
+			if err = os.Rename(staged, ep.Default.ResponseFile); err != nil && isNew {
+				s.configManager.DeleteRuntimeEndpoint(ep.ID)
+			}
+		}
+		if err != nil {
+			if staged != "" {
+				os.Remove(staged)
+			}
+			skipped = append(skipped, importSkip{Method: op.Method, Path: op.Path, Reason: err.Error()})
+			continue
+		}
+
+		view := s.newEndpointView(&added)
+		s.eventBus.Publish(events.TypeEndpointAdded, view)
+		created = append(created, view)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"created": created,
+		"skipped": skipped,
+	})
+}
+
+// readUpload returns the spec from a multipart "file" field or the raw body
+func readUpload(c *gin.Context) ([]byte, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("multipart upload must contain a 'file' field")
+		}
+		f, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("OpenAPI document is empty")
+	}
+	return data, nil
+}
+
+// importPath converts OpenAPI {param} segments to :param. Names such as
+// {order-id} or {user.id} are reduced to word characters.
+func importPath(path string) string {
+	return openAPIParamPattern.ReplaceAllStringFunc(path, func(m string) string {
+		name := strings.Trim(unsafeParamChars.ReplaceAllString(m[1:len(m)-1], "_"), "_")
+		if name == "" {
+			name = "param"
+		}
+		return ":" + name
+	})
+}
+
+// stageImportedBody writes an example body to a temporary file in dir and
+// returns it with the response file name it should be renamed to
+func stageImportedBody(dir, id string, status int, body interface{}) (string, string, error) {
+	content, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	name := unsafeFileChars.ReplaceAllString(id, "_") + "_" + strconv.Itoa(status) + ".json"
+	tmp, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return "", "", err
+	}
+	return tmp.Name(), filepath.Join(dir, name), nil
+}
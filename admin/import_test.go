@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImportOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mocks := t.TempDir()
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{
+		Admin:     config.AdminConfig{MocksDir: mocks},
+		Endpoints: []config.Endpoint{{ID: "listOrders", Path: "/orders", Method: "GET"}},
+	})
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	spec := `{
+  "openapi": "3.0.0",
+  "paths": {
+    "/orders": {"get": {"operationId": "listOrders", "responses": {"200": {"description": "ok", "content": {"application/json": {"example": []}}}}}},
+    "/orders/{order-id}": {"get": {"operationId": "getOrder", "responses": {"200": {"description": "ok", "content": {"application/json": {"example": {"id": 1}}}}}}}
+  }
+}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/import/openapi?replace=true", strings.NewReader(spec)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Created []struct {
+			ID   string `json:"id"`
+			Path string `json:"path"`
+		} `json:"created"`
+		Skipped []importSkip `json:"skipped"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Created) != 1 || resp.Created[0].Path != "/orders/:order_id" {
+		t.Errorf("created = %+v, want getOrder on /orders/:order_id", resp.Created)
+	}
+	if len(resp.Skipped) != 1 || resp.Skipped[0].Path != "/orders" {
+		t.Errorf("skipped = %+v, want the config endpoint /orders", resp.Skipped)
+	}
+
+	// Only the accepted operation leaves a response file behind
+	entries, err := os.ReadDir(filepath.Join(mocks, "openapi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != 1 || names[0] != "getOrder_200.json" {
+		t.Errorf("files = %v, want [getOrder_200.json]", names)
+	}
+}
@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultLogLines = 100
+
+// handleGetLogs returns recent structured log entries, oldest first.
+// Query parameters: lines (default 100) and level (minimum level, default debug).
+func (s *Server) handleGetLogs(c *gin.Context) {
+	if s.logBuffer == nil {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "log buffer is not available")
+		return
+	}
+
+	lines := defaultLogLines
+	if value := c.Query("lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			badRequest(c, "lines must be a positive integer")
+			return
+		}
+		lines = n
+	}
+
+	minLevel := zapcore.DebugLevel
+	if value := c.Query("level"); value != "" {
+		if err := minLevel.UnmarshalText([]byte(value)); err != nil {
+			badRequest(c, "invalid level: "+value)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": s.logBuffer.Entries(lines, minLevel),
+	})
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// matchTestRequest is a sample request to evaluate against the current config
+type matchTestRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Query   map[string]string `json:"query"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// handleMatchTest evaluates a sample request without producing a mock response
+func (s *Server) handleMatchTest(c *gin.Context) {
+	var sample matchTestRequest
+	if err := c.ShouldBindJSON(&sample); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if sample.Path == "" {
+		badRequest(c, "path is required")
+		return
+	}
+	if sample.Method == "" {
+		sample.Method = http.MethodGet
+	}
+
+	u, err := url.Parse(sample.Path)
+	if err != nil {
+		badRequest(c, "invalid path: "+err.Error())
+		return
+	}
+	if len(sample.Query) > 0 {
+		q := u.Query()
+		for k, v := range sample.Query {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(sample.Method), u.String(), bytes.NewReader(sampleBody(sample.Body)))
+	if err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	for k, v := range sample.Headers {
+		req.Header.Set(k, v)
+	}
+
+	c.JSON(http.StatusOK, s.mockHandler.Explain(req))
+}
+
+// sampleBody returns the raw body to send; a JSON string is used verbatim
+// so non-JSON payloads can be tested too
+func sampleBody(raw json.RawMessage) []byte {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return []byte(str)
+	}
+	return raw
+}
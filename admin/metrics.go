@@ -0,0 +1,108 @@
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scenarioMetrics describes the state one scenario holds
+type scenarioMetrics struct {
+	Name       string `json:"name"`
+	Partitions int    `json:"partitions"`
+	TTLSec     int    `json:"ttl_sec"` // configured partition TTL, 0 keeps partitions forever
+	// OldestAgeSec is how long ago the least recently seen partition was
+	// used; partitions far older than the TTL, or growing without one, leak
+	OldestAgeSec int64 `json:"oldest_age_sec"`
+	// TTLExpired counts partitions reset by the TTL within the kept history
+	TTLExpired int `json:"ttl_expired"`
+}
+
+// metricsTickInterval is how often metrics events are published
+const metricsTickInterval = 5 * time.Second
+
+// handleGetMetrics reports scenario store statistics, so partitions piling
+// up for lack of a ttl_sec show before they become a memory problem
+func (s *Server) handleGetMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, s.metrics())
+}
+
+// StartMetricsTicker publishes the metrics as an event every interval while
+// the event bus has subscribers, so dashboards need not poll. The returned
+// function stops it.
+func (s *Server) StartMetricsTicker(interval time.Duration) func() {
+	if interval <= 0 {
+		interval = metricsTickInterval
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if s.eventBus.SubscriberCount() > 0 {
+					s.eventBus.Publish(events.TypeMetrics, s.metrics())
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// metrics collects the statistics reported by /admin/metrics
+func (s *Server) metrics() gin.H {
+	ttls := make(map[string]int)
+	if cfg := s.configManager.GetConfig(); cfg != nil {
+		for name, sc := range cfg.Scenarios {
+			ttls[name] = sc.TTLSec
+		}
+	}
+
+	now := time.Now()
+	total := 0
+	var oldest int64
+	list := make([]scenarioMetrics, 0)
+	for _, name := range s.scenarioStore.Scenarios() {
+		m := scenarioMetrics{Name: name, TTLSec: ttls[name]}
+		for _, ps := range s.scenarioStore.Partitions(name) {
+			m.Partitions++
+			seen := ps.LastSeenAt
+			if seen.IsZero() {
+				seen = ps.UpdatedAt
+			}
+			if age := int64(now.Sub(seen).Seconds()); age > m.OldestAgeSec {
+				m.OldestAgeSec = age
+			}
+		}
+		for _, t := range s.scenarioStore.History(name, "") {
+			if t.Source == "ttl" {
+				m.TTLExpired++
+			}
+		}
+		total += m.Partitions
+		if m.OldestAgeSec > oldest {
+			oldest = m.OldestAgeSec
+		}
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return gin.H{
+		"scenarios": gin.H{
+			"count":          len(list),
+			"partitions":     total,
+			"oldest_age_sec": oldest,
+			"by_scenario":    list,
+		},
+		"counters": len(s.scenarioStore.Counters()),
+	}
+}
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Scenarios: map[string]config.ScenarioConfig{"checkout": {TTLSec: 60}}})
+	store := state.NewScenarioStore()
+	store.SetStep("checkout", "u1", "paid")
+	store.SetStep("checkout", "u2", "cart")
+	store.SetStep("login", "", "authenticated")
+	store.RecordTransition("checkout", state.Transition{Partition: "u3", PreviousStep: "cart", Step: state.DefaultStep, Source: "ttl"})
+
+	s := &Server{configManager: cm, scenarioStore: store}
+	router := gin.New()
+	router.GET("/admin/metrics", s.handleGetMetrics)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var body struct {
+		Scenarios struct {
+			Count      int               `json:"count"`
+			Partitions int               `json:"partitions"`
+			ByScenario []scenarioMetrics `json:"by_scenario"`
+		} `json:"scenarios"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Scenarios.Count != 2 || body.Scenarios.Partitions != 3 {
+		t.Errorf("expected 2 scenarios with 3 partitions, got %+v", body.Scenarios)
+	}
+	checkout := body.Scenarios.ByScenario[0]
+	if checkout.Name != "checkout" || checkout.Partitions != 2 || checkout.TTLSec != 60 || checkout.TTLExpired != 1 {
+		t.Errorf("unexpected checkout metrics %+v", checkout)
+	}
+	if login := body.Scenarios.ByScenario[1]; login.Name != "login" || login.TTLSec != 0 {
+		t.Errorf("unexpected login metrics %+v", login)
+	}
+}
+
+func TestMetricsTicker(t *testing.T) {
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{})
+	store := state.NewScenarioStore()
+	store.SetStep("checkout", "u1", "paid")
+	bus := events.NewBus()
+	s := &Server{configManager: cm, scenarioStore: store, eventBus: bus}
+
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	stop := s.StartMetricsTicker(10 * time.Millisecond)
+	defer stop()
+
+	select {
+	case event := <-ch:
+		if event.Type != events.TypeMetrics {
+			t.Fatalf("event type = %q, want %q", event.Type, events.TypeMetrics)
+		}
+		scenarios := event.Data.(gin.H)["scenarios"].(gin.H)
+		if scenarios["partitions"] != 1 {
+			t.Errorf("partitions = %v, want 1", scenarios["partitions"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a metrics event")
+	}
+}
@@ -0,0 +1,364 @@
+package admin
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// docsFiles holds the API docs page and its assets, so the page works
+// without network access
+//
+//go:embed static
+var docsFiles embed.FS
+
+var pathParamPattern = regexp.MustCompile(`:(\w+)`)
+
+// openAPIMethods are the HTTP methods OpenAPI can describe as operations
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// handleGetOpenAPI serves the generated OpenAPI document as JSON, or YAML with ?format=yaml
+func (s *Server) handleGetOpenAPI(c *gin.Context) {
+	spec := s.getOpenAPISpec()
+
+	if c.Query("format") == "yaml" {
+		data, err := yaml.Marshal(spec)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="openapi.yaml"`)
+		c.Data(http.StatusOK, "application/yaml", data)
+		return
+	}
+
+	c.JSON(http.StatusOK, spec)
+}
+
+// handleDocs serves a browsable page for the generated OpenAPI document
+func (s *Server) handleDocs(c *gin.Context) {
+	page, err := docsFiles.ReadFile("static/docs.html")
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// docsAssets returns the scripts and styles of the docs page
+func docsAssets() http.FileSystem {
+	sub, err := fs.Sub(docsFiles, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(sub)
+}
+
+// getOpenAPISpec builds an OpenAPI 3 document describing the enabled mock endpoints
+func (s *Server) getOpenAPISpec() map[string]interface{} {
+	paths := make(map[string]interface{})
+
+	if cfg := s.configManager.GetConfig(); cfg != nil {
+		for i := range cfg.Endpoints {
+			ep := &cfg.Endpoints[i]
+			method := strings.ToLower(ep.Method)
+			if !openAPIMethods[method] || !s.configManager.IsEndpointEnabled(ep.ID) {
+				continue
+			}
+
+			openAPIPath := pathParamPattern.ReplaceAllString(ep.Path, "{$1}")
+			item, ok := paths[openAPIPath].(map[string]interface{})
+			if !ok {
+				item = make(map[string]interface{})
+				paths[openAPIPath] = item
+			}
+			// The first endpoint for a route wins, as in request matching
+			if _, exists := item[method]; !exists {
+				item[method] = buildOperation(ep)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Mock API Server",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+	}
+}
+
+// buildOperation describes a single endpoint as an OpenAPI operation
+func buildOperation(ep *config.Endpoint) map[string]interface{} {
+	op := map[string]interface{}{
+		"operationId": ep.ID,
+		"responses":   buildResponses(ep),
+	}
+	if ep.Description != "" {
+		op["summary"] = ep.Description
+	}
+	if len(ep.Tags) > 0 {
+		op["tags"] = ep.Tags
+	}
+	if params := buildParameters(ep); len(params) > 0 {
+		op["parameters"] = params
+	}
+	if body := buildRequestBody(ep); body != nil {
+		op["requestBody"] = body
+	}
+	return op
+}
+
+// buildParameters derives parameters from the path pattern and non-body selectors
+func buildParameters(ep *config.Endpoint) []map[string]interface{} {
+	var params []map[string]interface{}
+	seen := make(map[string]bool)
+
+	add := func(name, in string) {
+		key := in + ":" + name
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       in,
+			"required": in == "path",
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	for _, m := range pathParamPattern.FindAllStringSubmatch(ep.Path, -1) {
+		add(m[1], "path")
+	}
+	for _, sel := range ep.Selectors {
+		switch strings.ToLower(sel.Type) {
+		case "query", "header":
+			add(sel.Key, strings.ToLower(sel.Type))
+		}
+	}
+	return params
+}
+
+// responseSource is one way an endpoint can produce a given status code
+type responseSource struct {
+	name string
+	file string
+}
+
+// buildResponses lists each status code the endpoint can return, which rules
+// produce it, and example bodies read from the response files
+func buildResponses(ep *config.Endpoint) map[string]interface{} {
+	sources := make(map[int][]responseSource)
+	addSource := func(code int, name, file string) {
+		if code == 0 {
+			code = http.StatusOK
+		}
+		sources[code] = append(sources[code], responseSource{name: name, file: file})
+	}
+
+	for i, rule := range ep.Rules {
+		addSource(rule.StatusCode, "rule_"+strconv.Itoa(i), rule.ResponseFile)
+	}
+	if ep.Default.RandomResponses != nil && ep.Default.RandomResponses.Enabled {
+		for i, rr := range ep.Default.RandomResponses.Files {
+			addSource(rr.StatusCode, "default_random_"+strconv.Itoa(i), rr.File)
+		}
+	} else {
+		addSource(ep.Default.StatusCode, "default", ep.Default.ResponseFile)
+	}
+
+	codes := make([]int, 0, len(sources))
+	for code := range sources {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	responses := make(map[string]interface{}, len(codes))
+	for _, code := range codes {
+		description := http.StatusText(code)
+		if description == "" {
+			description = "Status " + strconv.Itoa(code)
+		}
+
+		names := make([]string, 0, len(sources[code]))
+		for _, src := range sources[code] {
+			names = append(names, src.name)
+		}
+		response := map[string]interface{}{
+			"description": description + " (" + strings.Join(uniqueStrings(names), ", ") + ")",
+		}
+		if content := buildResponseContent(sources[code]); content != nil {
+			response["content"] = content
+		}
+		responses[strconv.Itoa(code)] = response
+	}
+	return responses
+}
+
+// buildResponseContent embeds every distinct JSON response file as a named
+// example, with a schema inferred from the first one
+func buildResponseContent(sources []responseSource) map[string]interface{} {
+	examples := make(map[string]interface{})
+	seenFiles := make(map[string]bool)
+	var schema map[string]interface{}
+
+	for _, src := range sources {
+		if src.file == "" || seenFiles[src.file] {
+			continue
+		}
+		seenFiles[src.file] = true
+
+		value, ok := readJSONExample(src.file)
+		if !ok {
+			continue
+		}
+		if schema == nil {
+			schema = inferSchema(value)
+		}
+		examples[src.name] = map[string]interface{}{
+			"summary": src.file,
+			"value":   value,
+		}
+	}
+
+	if len(examples) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema":   schema,
+			"examples": examples,
+		},
+	}
+}
+
+// readJSONExample reads a response file and decodes it as JSON
+func readJSONExample(file string) (interface{}, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// inferSchema derives a JSON schema from an example value
+func inferSchema(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			properties[key] = inferSchema(child)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case []interface{}:
+		items := map[string]interface{}{}
+		if len(v) > 0 {
+			items = inferSchema(v[0])
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case float64:
+		if v == float64(int64(v)) {
+			return map[string]interface{}{"type": "integer"}
+		}
+		return map[string]interface{}{"type": "number"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{"nullable": true}
+	}
+}
+
+// buildRequestBody infers a request body schema from the gjson paths of body
+// selectors. Paths using gjson modifiers or wildcards are skipped.
+func buildRequestBody(ep *config.Endpoint) map[string]interface{} {
+	var root map[string]interface{}
+
+	for _, sel := range ep.Selectors {
+		if !strings.EqualFold(sel.Type, "body") || sel.Key == "" || strings.ContainsAny(sel.Key, "#*?@|\\") {
+			continue
+		}
+		if root == nil {
+			root = map[string]interface{}{}
+		}
+		addSchemaPath(root, strings.Split(sel.Key, "."))
+	}
+
+	if root == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": root},
+		},
+	}
+}
+
+// addSchemaPath merges a dotted path into schema; numeric segments are array indexes
+func addSchemaPath(schema map[string]interface{}, segments []string) {
+	if len(segments) == 0 {
+		if _, typed := schema["type"]; !typed {
+			schema["type"] = "string"
+		}
+		return
+	}
+
+	segment := segments[0]
+	if _, err := strconv.Atoi(segment); err == nil {
+		schema["type"] = "array"
+		items, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			items = map[string]interface{}{}
+			schema["items"] = items
+		}
+		addSchemaPath(items, segments[1:])
+		return
+	}
+
+	schema["type"] = "object"
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		properties = map[string]interface{}{}
+		schema["properties"] = properties
+	}
+	child, ok := properties[segment].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		properties[segment] = child
+	}
+	addSchemaPath(child, segments[1:])
+}
+
+// uniqueStrings removes duplicates while keeping order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
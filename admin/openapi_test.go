@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{
+			ID:     "get-user",
+			Path:   "/users/:id",
+			Method: "GET",
+			Tags:   []string{"users"},
+			Selectors: []config.Selector{
+				{Name: "id", Type: "path", Key: "id"},
+				{Name: "type", Type: "query", Key: "type"},
+			},
+			Rules: []config.Rule{
+				{ResponseConfig: config.ResponseConfig{StatusCode: 404}},
+			},
+		},
+		{ID: "any", Path: "/any", Method: "ANY"},
+	}})
+	s := &Server{configManager: cm}
+
+	spec := s.getOpenAPISpec()
+	paths := spec["paths"].(map[string]interface{})
+	if len(paths) != 1 {
+		t.Fatalf("expected only describable methods in spec, got %v", paths)
+	}
+
+	item, ok := paths["/users/{id}"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected /users/{id} path, got %v", paths)
+	}
+	op := item["get"].(map[string]interface{})
+	if op["operationId"] != "get-user" {
+		t.Errorf("expected operationId get-user, got %v", op["operationId"])
+	}
+	if tags, _ := op["tags"].([]string); len(tags) != 1 || tags[0] != "users" {
+		t.Errorf("expected endpoint tags on the operation, got %v", op["tags"])
+	}
+
+	params := op["parameters"].([]map[string]interface{})
+	if len(params) != 2 || params[0]["in"] != "path" || params[1]["in"] != "query" {
+		t.Errorf("unexpected parameters: %v", params)
+	}
+
+	responses := op["responses"].(map[string]interface{})
+	if _, ok := responses["404"]; !ok {
+		t.Errorf("expected 404 response from rule, got %v", responses)
+	}
+	if _, ok := responses["200"]; !ok {
+		t.Errorf("expected 200 response from default, got %v", responses)
+	}
+}
+
+func TestBuildRequestBodyFromBodySelectors(t *testing.T) {
+	ep := &config.Endpoint{Selectors: []config.Selector{
+		{Name: "order_id", Type: "body", Key: "0.order_id"},
+		{Name: "user", Type: "body", Key: "0.user.id"},
+		{Name: "all", Type: "body", Key: "items.#.id"},
+		{Name: "h", Type: "header", Key: "X-Test"},
+	}}
+
+	body := buildRequestBody(ep)
+	if body == nil {
+		t.Fatal("expected request body")
+	}
+	schema := body["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	if schema["type"] != "array" {
+		t.Fatalf("expected array root, got %v", schema)
+	}
+	item := schema["items"].(map[string]interface{})
+	props := item["properties"].(map[string]interface{})
+	if props["order_id"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("expected order_id string property, got %v", props)
+	}
+	user := props["user"].(map[string]interface{})
+	if user["type"] != "object" {
+		t.Errorf("expected nested user object, got %v", user)
+	}
+	if len(props) != 2 {
+		t.Errorf("expected wildcard selector to be skipped, got %v", props)
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	schema := inferSchema(map[string]interface{}{
+		"id":    float64(1),
+		"price": 9.5,
+		"tags":  []interface{}{"a"},
+	})
+	props := schema["properties"].(map[string]interface{})
+	if props["id"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("expected integer id, got %v", props["id"])
+	}
+	if props["price"].(map[string]interface{})["type"] != "number" {
+		t.Errorf("expected number price, got %v", props["price"])
+	}
+	if props["tags"].(map[string]interface{})["items"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("expected string array items, got %v", props["tags"])
+	}
+}
+
+func TestDocsServedWithoutNetwork(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{})
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	for _, path := range []string{"/admin/docs", "/admin/docs/assets/docs.js", "/admin/docs/assets/docs.css"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status = %d, want 200", path, w.Code)
+		}
+		if body := w.Body.String(); strings.Contains(body, "https://") {
+			t.Errorf("GET %s: page loads remote assets", path)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"net/http"
+
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleReset returns all runtime state to what the config files define:
+// scenario states, counters, crud collections, idempotent responses, auth
+// challenge attempts, sink recordings, runtime and disabled endpoints, chaos
+// settings and any forced health failure. The audit log is kept so the reset itself stays traceable.
+func (s *Server) handleReset(c *gin.Context) {
+	s.scenarioStore.ResetAll()
+	s.scenarioStore.ResetCounter("")
+	if s.mockHandler != nil {
+		s.mockHandler.ResetResources()
+		s.mockHandler.ResetIdempotency()
+		s.mockHandler.ResetChallenges()
+		s.mockHandler.Sinks().ClearAll()
+	}
+	s.configManager.ResetRuntime()
+	s.chaos.Clear()
+	if s.health != nil {
+		s.health.Clear()
+	}
+
+	cleared := []string{"scenarios", "counters", "resources", "idempotency", "challenges", "sinks", "runtime_endpoints", "disabled_endpoints", "chaos", "health"}
+	s.eventBus.Publish(events.TypeReset, gin.H{"cleared": cleared})
+	c.JSON(http.StatusOK, gin.H{"cleared": cleared})
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scenarioExportVersion is bumped when ScenarioExport changes incompatibly
+const scenarioExportVersion = 1
+
+// ScenarioExport is the steps and variables of scenario partitions, without
+// the rest of the runtime state captured by Snapshot
+type ScenarioExport struct {
+	Version    int                               `json:"version"`
+	ExportedAt time.Time                         `json:"exported_at"`
+	Scenarios  map[string][]state.PartitionState `json:"scenarios"`
+}
+
+// handleExportScenarios returns the state of every scenario, or only those
+// named by repeated ?scenario= parameters
+func (s *Server) handleExportScenarios(c *gin.Context) {
+	scenarios := s.scenarioStore.Snapshot()
+	if names := c.QueryArray("scenario"); len(names) > 0 {
+		filtered := make(map[string][]state.PartitionState, len(names))
+		for _, name := range names {
+			if partitions, ok := scenarios[name]; ok {
+				filtered[name] = partitions
+			}
+		}
+		scenarios = filtered
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="mock-scenarios.json"`)
+	c.JSON(http.StatusOK, ScenarioExport{
+		Version:    scenarioExportVersion,
+		ExportedAt: time.Now(),
+		Scenarios:  scenarios,
+	})
+}
+
+// handleImportScenarios loads an export. By default it replaces all scenario
+// state; ?mode=merge only overwrites the partitions contained in the export.
+func (s *Server) handleImportScenarios(c *gin.Context) {
+	var export ScenarioExport
+	if err := c.ShouldBindJSON(&export); err != nil {
+		badRequest(c, "invalid export: "+err.Error())
+		return
+	}
+	if export.Version != scenarioExportVersion {
+		badRequest(c, "unsupported export version")
+		return
+	}
+
+	imported := 0
+	switch mode := c.DefaultQuery("mode", "replace"); mode {
+	case "replace":
+		s.scenarioStore.Restore(export.Scenarios)
+		for _, partitions := range export.Scenarios {
+			imported += len(partitions)
+		}
+	case "merge":
+		for name, partitions := range export.Scenarios {
+			for _, ps := range partitions {
+				s.scenarioStore.Reset(name, ps.Partition)
+				if len(ps.Variables) > 0 {
+					s.scenarioStore.SetVariables(name, ps.Partition, ps.Variables)
+				}
+				s.scenarioStore.SetStep(name, ps.Partition, ps.Step)
+				imported++
+			}
+		}
+	default:
+		badRequest(c, "mode must be replace or merge")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imported": imported, "scenarios": len(export.Scenarios)})
+}
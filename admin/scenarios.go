@@ -0,0 +1,198 @@
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"mock-api-server/pkg/events"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setScenarioStateRequest forces a scenario partition into a step and/or
+// merges variables into it
+type setScenarioStateRequest struct {
+	Partition string            `json:"partition"`
+	Step      string            `json:"step"`
+	Variables map[string]string `json:"variables"`
+}
+
+// seedPartition is the state one partition is seeded with
+type seedPartition struct {
+	Step      string            `json:"step"`
+	Variables map[string]string `json:"variables"`
+}
+
+// handleSeedScenarios arranges many partitions in one call. The body maps
+// scenario -> partition -> {step, variables}; partitions not mentioned are
+// left as they are.
+func (s *Server) handleSeedScenarios(c *gin.Context) {
+	var req map[string]map[string]seedPartition
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	for name, partitions := range req {
+		for partition, seed := range partitions {
+			if strings.TrimSpace(seed.Step) == "" && len(seed.Variables) == 0 {
+				badRequest(c, "step or variables is required for "+name+"/"+partition)
+				return
+			}
+		}
+	}
+
+	seeded := 0
+	for name, partitions := range req {
+		for partition, seed := range partitions {
+			if len(seed.Variables) > 0 {
+				s.scenarioStore.SetVariables(name, partition, seed.Variables)
+			}
+			if strings.TrimSpace(seed.Step) != "" {
+				s.setStep(c, name, partition, seed.Step)
+			}
+			seeded++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"seeded": seeded})
+}
+
+// handleResetAllScenarios returns every scenario partition to the idle step
+// and clears variables and history
+func (s *Server) handleResetAllScenarios(c *gin.Context) {
+	s.scenarioStore.ResetAll()
+	s.eventBus.Publish(events.TypeReset, gin.H{"cleared": []string{"scenarios"}})
+	c.JSON(http.StatusOK, gin.H{"cleared": []string{"scenarios"}})
+}
+
+// scenarioSummary is one entry of the scenario list
+type scenarioSummary struct {
+	Name       string `json:"name"`
+	Configured bool   `json:"configured"` // bound to an endpoint or listed under scenarios
+	Partitions int    `json:"partitions"` // partitions that have left the idle step
+}
+
+// handleListScenarios lists the scenarios referenced by the config and those
+// that currently hold state
+func (s *Server) handleListScenarios(c *gin.Context) {
+	summaries := make(map[string]*scenarioSummary)
+	summary := func(name string) *scenarioSummary {
+		if summaries[name] == nil {
+			summaries[name] = &scenarioSummary{Name: name}
+		}
+		return summaries[name]
+	}
+
+	if cfg := s.configManager.GetConfig(); cfg != nil {
+		for _, ep := range cfg.Endpoints {
+			if ep.Scenario != "" {
+				summary(ep.Scenario).Configured = true
+			}
+		}
+		for name := range cfg.Scenarios {
+			summary(name).Configured = true
+		}
+	}
+	for _, name := range s.scenarioStore.Scenarios() {
+		summary(name).Partitions = len(s.scenarioStore.Partitions(name))
+	}
+
+	list := make([]scenarioSummary, 0, len(summaries))
+	for _, sum := range summaries {
+		list = append(list, *sum)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	c.JSON(http.StatusOK, gin.H{"scenarios": list})
+}
+
+// handleGetScenario lists the partitions of a scenario and their current steps
+func (s *Server) handleGetScenario(c *gin.Context) {
+	name := c.Param("name")
+	c.JSON(http.StatusOK, gin.H{
+		"name":       name,
+		"partitions": s.scenarioStore.Partitions(name),
+	})
+}
+
+// handleGetScenarioHistory lists the recorded transitions of a scenario,
+// optionally restricted to ?partition=
+func (s *Server) handleGetScenarioHistory(c *gin.Context) {
+	name := c.Param("name")
+	c.JSON(http.StatusOK, gin.H{
+		"name":        name,
+		"transitions": s.scenarioStore.History(name, c.Query("partition")),
+	})
+}
+
+// handleSetScenarioState sets the step of one scenario partition
+func (s *Server) handleSetScenarioState(c *gin.Context) {
+	var req setScenarioStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Step) == "" && len(req.Variables) == 0 {
+		badRequest(c, "step or variables is required")
+		return
+	}
+
+	name := c.Param("name")
+	if len(req.Variables) > 0 {
+		s.scenarioStore.SetVariables(name, req.Partition, req.Variables)
+	}
+	if strings.TrimSpace(req.Step) != "" {
+		s.setStep(c, name, req.Partition, req.Step)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      name,
+		"partition": req.Partition,
+		"step":      s.scenarioStore.GetStep(name, req.Partition),
+		"variables": s.scenarioStore.GetVariables(name, req.Partition),
+	})
+}
+
+// handleResetScenarioState returns one partition (?partition=) of a scenario
+// to the idle step and drops its variables
+func (s *Server) handleResetScenarioState(c *gin.Context) {
+	name := c.Param("name")
+	partition := c.Query("partition")
+	previous := s.scenarioStore.GetStep(name, partition)
+	s.scenarioStore.Reset(name, partition)
+	if previous != state.DefaultStep {
+		s.recordTransition(c, name, partition, previous, state.DefaultStep)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name":      name,
+		"partition": partition,
+		"step":      state.DefaultStep,
+	})
+}
+
+// setStep moves a partition to step, recording and publishing the transition
+func (s *Server) setStep(c *gin.Context, name, partition, step string) {
+	previous := s.scenarioStore.GetStep(name, partition)
+	s.scenarioStore.SetStep(name, partition, step)
+	s.recordTransition(c, name, partition, previous, step)
+}
+
+// recordTransition records and publishes a step change made through the admin API
+func (s *Server) recordTransition(c *gin.Context, name, partition, previous, step string) {
+	s.scenarioStore.RecordTransition(name, state.Transition{
+		Partition:    partition,
+		PreviousStep: previous,
+		Step:         step,
+		Source:       "admin",
+		RequestID:    c.GetString("request_id"),
+	})
+	s.eventBus.Publish(events.TypeScenarioTransition, gin.H{
+		"scenario":      name,
+		"partition":     partition,
+		"previous_step": previous,
+		"step":          step,
+		"source":        "admin",
+	})
+}
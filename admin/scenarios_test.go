@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSeedAndResetAllScenarios(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := state.NewScenarioStore()
+	s := &Server{scenarioStore: store, eventBus: events.NewBus()}
+	router := gin.New()
+	router.POST("/admin/scenarios/seed", s.handleSeedScenarios)
+	router.POST("/admin/scenarios/reset-all", s.handleResetAllScenarios)
+
+	body := `{
+		"checkout": {"u1": {"step": "paid", "variables": {"order": "A1"}}, "u2": {"variables": {"order": "B2"}}},
+		"login": {"": {"step": "authenticated"}}
+	}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/scenarios/seed", strings.NewReader(body)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"seeded":3`) {
+		t.Fatalf("unexpected seed response %d: %s", w.Code, w.Body.String())
+	}
+
+	if step := store.GetStep("checkout", "u1"); step != "paid" {
+		t.Errorf("expected u1 seeded to paid, got %q", step)
+	}
+	if vars := store.GetVariables("checkout", "u2"); vars["order"] != "B2" {
+		t.Errorf("expected u2 variables to be seeded, got %v", vars)
+	}
+	if step := store.GetStep("login", state.DefaultPartition); step != "authenticated" {
+		t.Errorf("expected default partition seeded, got %q", step)
+	}
+	if history := store.History("checkout", "u1"); len(history) != 1 || history[0].Source != "admin" {
+		t.Errorf("expected seeded step to be recorded, got %+v", history)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/scenarios/seed", strings.NewReader(`{"checkout": {"u3": {}}}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty seed entry, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/scenarios/reset-all", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from reset-all, got %d", w.Code)
+	}
+	if names := store.Scenarios(); len(names) != 0 {
+		t.Errorf("expected no scenarios after reset-all, got %v", names)
+	}
+}
+
+func TestExportImportScenarios(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{})
+	source := state.NewScenarioStore()
+	source.SetVariables("checkout", "u1", map[string]string{"order": "A1"})
+	source.SetStep("checkout", "u1", "paid")
+	source.SetStep("login", "u1", "authenticated")
+
+	// Register the full route set so static and :name scenario routes are checked for conflicts
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, ScenarioStore: source, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/scenarios/export?scenario=checkout", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("export returned %d: %s", w.Code, w.Body.String())
+	}
+	exported := w.Body.String()
+	if strings.Contains(exported, "login") || !strings.Contains(exported, `"order":"A1"`) {
+		t.Fatalf("unexpected export: %s", exported)
+	}
+
+	target := state.NewScenarioStore()
+	target.SetStep("checkout", "u2", "cart")
+	target.SetStep("other", "x", "y")
+	targetRouter := gin.New()
+	NewServer(Options{ConfigManager: cm, ScenarioStore: target, EventBus: events.NewBus()}).RegisterRoutes(targetRouter)
+
+	w = httptest.NewRecorder()
+	targetRouter.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/scenarios/import?mode=merge", strings.NewReader(exported)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge import returned %d: %s", w.Code, w.Body.String())
+	}
+	if step := target.GetStep("checkout", "u1"); step != "paid" || target.GetVariables("checkout", "u1")["order"] != "A1" {
+		t.Errorf("expected imported step and variables, got %q %v", step, target.GetVariables("checkout", "u1"))
+	}
+	if step := target.GetStep("other", "x"); step != "y" {
+		t.Errorf("expected merge to keep other scenarios, got %q", step)
+	}
+
+	w = httptest.NewRecorder()
+	targetRouter.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/scenarios/import", strings.NewReader(exported)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("replace import returned %d: %s", w.Code, w.Body.String())
+	}
+	if names := target.Scenarios(); len(names) != 1 || names[0] != "checkout" {
+		t.Errorf("expected replace to keep only imported scenarios, got %v", names)
+	}
+}
+
+func TestListAndResetScenarios(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{ID: "pay", Path: "/pay", Method: "POST", Scenario: "checkout"}}})
+	store := state.NewScenarioStore()
+	store.SetStep("checkout", "u1", "paid")
+	store.SetStep("legacy", "", "done")
+
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, ScenarioStore: store, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/scenarios", nil))
+	want := `{"scenarios":[{"name":"checkout","configured":true,"partitions":1},{"name":"legacy","configured":false,"partitions":1}]}`
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Fatalf("unexpected list response %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/scenarios/checkout/state?partition=u1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("reset returned %d: %s", w.Code, w.Body.String())
+	}
+	if step := store.GetStep("checkout", "u1"); step != state.DefaultStep {
+		t.Errorf("expected u1 reset to %q, got %q", state.DefaultStep, step)
+	}
+	if history := store.History("checkout", "u1"); len(history) != 1 || history[0].PreviousStep != "paid" {
+		t.Errorf("expected reset to be recorded, got %+v", history)
+	}
+}
@@ -0,0 +1,20 @@
+package admin
+
+import (
+	"net/http"
+
+	"mock-api-server/pkg/selfcheck"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleSelfCheck runs the deep checks of the current config: response
+// files parse as their content type, templates render, hook hosts resolve
+func (s *Server) handleSelfCheck(c *gin.Context) {
+	cfg := s.configManager.GetConfig()
+	if cfg == nil {
+		respondError(c, http.StatusServiceUnavailable, "NOT_READY", "configuration not loaded")
+		return
+	}
+	c.JSON(http.StatusOK, selfcheck.Run(c.Request.Context(), cfg, nil))
+}
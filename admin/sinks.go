@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"mock-api-server/config"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sinkSummary describes one sink endpoint and what it has received
+type sinkSummary struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	Received int    `json:"received"` // requests since the last clear
+	Kept     int    `json:"kept"`     // requests still held
+}
+
+// sinkStore returns the handler's sink recordings, nil without a handler
+func (s *Server) sinkStore() *state.SinkStore {
+	if s.mockHandler == nil {
+		return nil
+	}
+	return s.mockHandler.Sinks()
+}
+
+// handleListSinks lists every sink endpoint with its request counts
+func (s *Server) handleListSinks(c *gin.Context) {
+	sinks := []sinkSummary{}
+	store := s.sinkStore()
+	if cfg := s.configManager.GetConfig(); cfg != nil && store != nil {
+		for _, ep := range cfg.Endpoints {
+			if ep.Mode != config.EndpointModeSink {
+				continue
+			}
+			sinks = append(sinks, sinkSummary{
+				ID:       ep.ID,
+				Path:     ep.Path,
+				Received: store.Total(ep.ID),
+				Kept:     len(store.Entries(ep.ID)),
+			})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"sinks": sinks})
+}
+
+// handleGetSink returns the requests a sink endpoint received, oldest first.
+// ?limit=N returns only the newest N.
+func (s *Server) handleGetSink(c *gin.Context) {
+	id := c.Param("id")
+	ep := s.findEndpointByID(id)
+	if ep == nil || ep.Mode != config.EndpointModeSink {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "sink endpoint not found: "+id)
+		return
+	}
+
+	entries := []state.SinkEntry{}
+	total := 0
+	if store := s.sinkStore(); store != nil {
+		entries = append(entries, store.Entries(id)...)
+		total = store.Total(id)
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			badRequest(c, "limit must be a non-negative integer")
+			return
+		}
+		if limit < len(entries) {
+			entries = entries[len(entries)-limit:]
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "received": total, "requests": entries})
+}
+
+// handleClearSink forgets what one sink endpoint received
+func (s *Server) handleClearSink(c *gin.Context) {
+	id := c.Param("id")
+	ep := s.findEndpointByID(id)
+	if ep == nil || ep.Mode != config.EndpointModeSink {
+		respondError(c, http.StatusNotFound, "NOT_FOUND", "sink endpoint not found: "+id)
+		return
+	}
+	if store := s.sinkStore(); store != nil {
+		store.Clear(id)
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "received": 0})
+}
+
+// handleClearSinks forgets what every sink endpoint received
+func (s *Server) handleClearSinks(c *gin.Context) {
+	if store := s.sinkStore(); store != nil {
+		store.ClearAll()
+	}
+	c.JSON(http.StatusOK, gin.H{"cleared": []string{"sinks"}})
+}
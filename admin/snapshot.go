@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/chaos"
+	"mock-api-server/pkg/events"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+const snapshotVersion = 1
+
+// Snapshot is the complete runtime state of the server. Admin credentials are
+// never included, and restoring keeps the credentials of the target instance.
+type Snapshot struct {
+	Version           int                               `json:"version"`
+	CreatedAt         time.Time                         `json:"created_at"`
+	Config            *config.Config                    `json:"config"`
+	RuntimeEndpoints  []config.Endpoint                 `json:"runtime_endpoints"`
+	DisabledEndpoints []string                          `json:"disabled_endpoints"`
+	Scenarios         map[string][]state.PartitionState `json:"scenarios"`
+	Counters          map[string]int64                  `json:"counters,omitempty"`
+	Chaos             *chaos.Settings                   `json:"chaos,omitempty"`
+}
+
+// handleGetSnapshot returns the current runtime state as a downloadable document
+func (s *Server) handleGetSnapshot(c *gin.Context) {
+	snapshot := Snapshot{
+		Version:           snapshotVersion,
+		CreatedAt:         time.Now(),
+		Config:            s.configManager.GetBaseConfig(),
+		RuntimeEndpoints:  s.configManager.RuntimeEndpoints(),
+		DisabledEndpoints: s.configManager.DisabledEndpoints(),
+		Scenarios:         s.scenarioStore.Snapshot(),
+		Counters:          s.scenarioStore.Counters(),
+	}
+	if settings, active := s.chaos.Get(); active {
+		snapshot.Chaos = &settings
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="mock-snapshot.json"`)
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// handleRestoreSnapshot replaces the config and all runtime state with a
+// snapshot. Snapshots with validation errors are rejected before anything is
+// swapped in.
+func (s *Server) handleRestoreSnapshot(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		badRequest(c, "failed to read request body: "+err.Error())
+		return
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		badRequest(c, "invalid snapshot: "+err.Error())
+		return
+	}
+	if snapshot.Version != snapshotVersion {
+		badRequest(c, "unsupported snapshot version")
+		return
+	}
+	if snapshot.Config == nil {
+		badRequest(c, "snapshot has no config")
+		return
+	}
+
+	if s.rejectExecResponders(c, snapshot.Config.Endpoints) || s.rejectExecResponders(c, snapshot.RuntimeEndpoints) {
+		return
+	}
+	// Credentials and the exec opt-in are not part of snapshots; keep the
+	// ones currently in effect
+	if current := s.configManager.GetBaseConfig(); current != nil {
+		snapshot.Config.Admin.Auth = current.Admin.Auth
+		snapshot.Config.Server.AllowExecResponders = current.Server.AllowExecResponders
+	}
+	// Runtime endpoints are checked alongside the config they are added to
+	full := *snapshot.Config
+	full.Endpoints = append(slices.Clone(snapshot.Config.Endpoints), snapshot.RuntimeEndpoints...)
+	issues := config.Validate(&full)
+	if rejectInvalid(c, issues, false) {
+		return
+	}
+
+	s.configManager.SetConfig(snapshot.Config)
+	s.configManager.RestoreRuntime(snapshot.RuntimeEndpoints, snapshot.DisabledEndpoints)
+	s.scenarioStore.Restore(snapshot.Scenarios)
+	s.scenarioStore.ResetCounter("")
+	for name, value := range snapshot.Counters {
+		s.scenarioStore.Increment(name, value)
+	}
+	if snapshot.Chaos != nil {
+		s.chaos.Set(*snapshot.Chaos)
+	} else {
+		s.chaos.Clear()
+	}
+
+	s.eventBus.Publish(events.TypeConfigReloaded, gin.H{
+		"source":            "snapshot",
+		"endpoints_count":   len(s.configManager.GetConfig().Endpoints),
+		"config_generation": s.configManager.Generation(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "restored",
+		"warnings": issues,
+	})
+}
@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRestoreSnapshotRejectsInvalidConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{ID: "ping", Path: "/ping", Method: "GET"}}})
+	generation := cm.Generation()
+	router := gin.New()
+	NewServer(Options{ConfigManager: cm, EventBus: events.NewBus()}).RegisterRoutes(router)
+
+	snapshot := `{"version": 1, "config": {"endpoints": [{"path": "/users", "method": "GET",
+		"rules": [{"conditions": [{"selector": "missing", "match_type": "exact", "value": "x"}]}]}]}}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/snapshot/restore", strings.NewReader(snapshot)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "unknown_selector") {
+		t.Errorf("expected the validation error in the response, got %s", w.Body.String())
+	}
+	if cm.Generation() != generation {
+		t.Error("expected the running config to stay active")
+	}
+}
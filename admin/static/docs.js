@@ -0,0 +1,179 @@
+// Renders the generated OpenAPI document without external assets, so the
+// docs page works offline. Operations can be tried against this server.
+(function () {
+  "use strict";
+
+  var methods = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+  var container = document.getElementById("operations");
+  var filter = document.getElementById("filter");
+  var spec = null;
+
+  function esc(value) {
+    return String(value === undefined || value === null ? "" : value)
+      .replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;").replace(/"/g, "&quot;");
+  }
+
+  function json(value) {
+    return JSON.stringify(value, null, 2);
+  }
+
+  // resolve follows a local "#/..." reference
+  function resolve(node) {
+    if (!node || typeof node.$ref !== "string" || node.$ref.indexOf("#/") !== 0) return node;
+    return node.$ref.slice(2).split("/").reduce(function (cur, key) {
+      return cur ? cur[key.replace(/~1/g, "/").replace(/~0/g, "~")] : undefined;
+    }, spec);
+  }
+
+  function operations() {
+    var list = [];
+    Object.keys(spec.paths || {}).sort().forEach(function (path) {
+      var item = spec.paths[path];
+      methods.forEach(function (method) {
+        if (item[method]) list.push({ path: path, method: method, op: item[method], shared: item.parameters || [] });
+      });
+    });
+    return list;
+  }
+
+  function example(media) {
+    if (!media) return undefined;
+    if (media.example !== undefined) return media.example;
+    var names = Object.keys(media.examples || {});
+    if (names.length) return resolve(media.examples[names[0]]).value;
+    return undefined;
+  }
+
+  function renderParameters(params) {
+    if (!params.length) return "";
+    return "<h4>Parameters</h4><table><tr><th>Name</th><th>In</th><th>Type</th><th>Description</th></tr>" +
+      params.map(function (p) {
+        var schema = resolve(p.schema) || {};
+        return "<tr><td class=\"path\">" + esc(p.name) + (p.required ? " *" : "") + "</td><td>" + esc(p["in"]) +
+          "</td><td>" + esc(schema.type) + "</td><td>" + esc(p.description) + "</td></tr>";
+      }).join("") + "</table>";
+  }
+
+  function renderContent(content) {
+    return Object.keys(content || {}).map(function (type) {
+      var media = content[type];
+      var media_example = example(media);
+      var shown = media_example !== undefined ? media_example : resolve(media.schema);
+      return "<div class=\"muted\">" + esc(type) + (media_example !== undefined ? "" : " (schema)") + "</div>" +
+        (shown !== undefined ? "<pre>" + esc(json(shown)) + "</pre>" : "");
+    }).join("");
+  }
+
+  function renderResponses(responses) {
+    return "<h4>Responses</h4>" + Object.keys(responses || {}).sort().map(function (code) {
+      var r = resolve(responses[code]);
+      return "<div><strong>" + esc(code) + "</strong> " + esc(r.description) + "</div>" + renderContent(r.content);
+    }).join("");
+  }
+
+  function renderTry(entry, params) {
+    var body = resolve(entry.op.requestBody);
+    var bodyType = body ? Object.keys(body.content || {})[0] : "";
+    var bodyExample = body ? example(body.content[bodyType]) : undefined;
+    return "<h4>Try it</h4><form class=\"try\">" +
+      params.map(function (p) {
+        return "<label>" + esc(p["in"]) + " <span class=\"path\">" + esc(p.name) + "</span>" +
+          "<input class=\"param\" data-name=\"" + esc(p.name) + "\" data-in=\"" + esc(p["in"]) + "\"></label>";
+      }).join("") +
+      (body ? "<label>Body (" + esc(bodyType) + ")<textarea data-type=\"" + esc(bodyType) + "\">" +
+        esc(bodyExample !== undefined ? json(bodyExample) : "") + "</textarea></label>" : "") +
+      "<button type=\"submit\">Send</button><div class=\"result\"></div></form>";
+  }
+
+  function send(form, entry) {
+    var path = entry.path;
+    var query = [];
+    var headers = {};
+    Array.prototype.forEach.call(form.querySelectorAll("input.param"), function (input) {
+      var name = input.getAttribute("data-name");
+      var value = input.value;
+      switch (input.getAttribute("data-in")) {
+        case "path": path = path.split("{" + name + "}").join(encodeURIComponent(value)); break;
+        case "query": if (value !== "") query.push(encodeURIComponent(name) + "=" + encodeURIComponent(value)); break;
+        case "header": if (value !== "") headers[name] = value; break;
+      }
+    });
+    var init = { method: entry.method.toUpperCase(), headers: headers };
+    var textarea = form.querySelector("textarea");
+    if (textarea && textarea.value !== "") {
+      headers["Content-Type"] = textarea.getAttribute("data-type");
+      init.body = textarea.value;
+    }
+    var url = path + (query.length ? "?" + query.join("&") : "");
+    var result = form.querySelector(".result");
+    result.innerHTML = "<p class=\"muted\">Sending…</p>";
+    fetch(url, init).then(function (resp) {
+      return resp.text().then(function (text) {
+        var lines = [];
+        resp.headers.forEach(function (value, name) { lines.push(name + ": " + value); });
+        var pretty = text;
+        try { pretty = json(JSON.parse(text)); } catch (e) { /* not JSON */ }
+        result.innerHTML = "<p class=\"" + (resp.ok ? "status-ok" : "status-err") + "\">" + esc(init.method + " " + url) +
+          " → " + esc(resp.status) + "</p><pre>" + esc(lines.join("\n")) + "</pre><pre>" + esc(pretty) + "</pre>";
+      });
+    }).catch(function (err) {
+      result.innerHTML = "<p class=\"status-err\">" + esc(err.message) + "</p>";
+    });
+  }
+
+  function render() {
+    var q = filter.value.toLowerCase();
+    var groups = {};
+    operations().forEach(function (entry) {
+      var text = [entry.method, entry.path, entry.op.summary, (entry.op.tags || []).join(" ")].join(" ").toLowerCase();
+      if (q && text.indexOf(q) === -1) return;
+      (entry.op.tags && entry.op.tags.length ? entry.op.tags : ["default"]).forEach(function (tag) {
+        (groups[tag] = groups[tag] || []).push(entry);
+      });
+    });
+
+    var tags = Object.keys(groups).sort();
+    if (!tags.length) {
+      container.innerHTML = "<p class=\"muted\">No operations.</p>";
+      return;
+    }
+    container.innerHTML = "";
+    tags.forEach(function (tag) {
+      var heading = document.createElement("h2");
+      heading.textContent = tag;
+      container.appendChild(heading);
+      groups[tag].forEach(function (entry) {
+        var params = entry.shared.concat(entry.op.parameters || []).map(resolve);
+        var details = document.createElement("details");
+        details.className = "op";
+        details.innerHTML = "<summary><span class=\"method " + esc(entry.method) + "\">" + esc(entry.method) +
+          "</span><span class=\"path\">" + esc(entry.path) + "</span><span class=\"muted\">" + esc(entry.op.summary) +
+          "</span></summary><div class=\"body\">" +
+          (entry.op.description ? "<p>" + esc(entry.op.description) + "</p>" : "") +
+          (entry.op.operationId ? "<p class=\"muted\">operationId: " + esc(entry.op.operationId) + "</p>" : "") +
+          renderParameters(params) +
+          (entry.op.requestBody ? "<h4>Request body</h4>" + renderContent(resolve(entry.op.requestBody).content) : "") +
+          renderResponses(entry.op.responses) + renderTry(entry, params) + "</div>";
+        details.querySelector("form.try").addEventListener("submit", function (e) {
+          e.preventDefault();
+          send(e.target, entry);
+        });
+        container.appendChild(details);
+      });
+    });
+  }
+
+  filter.addEventListener("input", function () { if (spec) render(); });
+
+  fetch("openapi").then(function (resp) {
+    if (!resp.ok) throw new Error("failed to load openapi: " + resp.status);
+    return resp.json();
+  }).then(function (doc) {
+    spec = doc;
+    document.getElementById("title").textContent = (doc.info && doc.info.title) || "API Docs";
+    document.getElementById("version").textContent = (doc.info && doc.info.version) || "";
+    render();
+  }).catch(function (err) {
+    container.innerHTML = "<p class=\"status-err\">" + esc(err.message) + "</p>";
+  });
+})();
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mock-api-server/pkg/template"
+
+	"github.com/gin-gonic/gin"
+)
+
+// engineSimple is the {{.name}} placeholder engine used for response files
+const engineSimple = "simple"
+
+// templatePreviewRequest is a template to render with sample values
+type templatePreviewRequest struct {
+	Template     string            `json:"template"`
+	ResponseFile string            `json:"response_file"`
+	Engine       string            `json:"engine"`
+	Values       map[string]string `json:"values"`
+}
+
+// handleTemplatePreview renders a template body or response file with sample values
+func (s *Server) handleTemplatePreview(c *gin.Context) {
+	var req templatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, "invalid request: "+err.Error())
+		return
+	}
+	if req.Engine == "" {
+		req.Engine = engineSimple
+	}
+	if req.Engine != engineSimple {
+		badRequest(c, "unsupported engine '"+req.Engine+"', supported: "+engineSimple)
+		return
+	}
+
+	content := []byte(req.Template)
+	if req.ResponseFile != "" {
+		if req.Template != "" {
+			badRequest(c, "specify either template or response_file, not both")
+			return
+		}
+		if !s.insideMocksRoot(req.ResponseFile) {
+			badRequest(c, errPathOutsideRoot.Error())
+			return
+		}
+		data, err := os.ReadFile(req.ResponseFile)
+		if err != nil {
+			respondFileError(c, err)
+			return
+		}
+		content = data
+	}
+
+	rendered := template.ReplaceVariables(content, req.Values)
+
+	c.JSON(http.StatusOK, gin.H{
+		"engine":       req.Engine,
+		"output":       string(rendered),
+		"placeholders": template.ExtractPlaceholders(content),
+		"unresolved":   template.ExtractPlaceholders(rendered),
+	})
+}
+
+// insideMocksRoot reports whether a config-style response file path lies in the mocks directory
+func (s *Server) insideMocksRoot(path string) bool {
+	absRoot, err := filepath.Abs(s.mocksRoot())
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const wsPingInterval = 30 * time.Second
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	// Admin auth already guards this route; browsers on other origins are
+	// allowed so external dashboards can subscribe too
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// handleWebSocket streams server events to the client as JSON messages
+func (s *Server) handleWebSocket(c *gin.Context) {
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	eventsCh, unsubscribe := s.eventBus.Subscribe()
+	defer unsubscribe()
+
+	// Drain client frames so close and pong messages are processed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-eventsCh:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/template"
+)
+
+const publishTimeout = 10 * time.Second
+
+// Message is one event ready to publish
+type Message struct {
+	Topic    string
+	Exchange string
+	Key      []byte
+	Value    []byte
+	Headers  map[string]string
+}
+
+// Publisher sends messages to one broker
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// Dialer connects to a configured broker
+type Dialer func(cfg config.BrokerConfig) (Publisher, error)
+
+// Emitter publishes the events of matched responses. Connections are opened
+// on first use and reopened when the broker's config changes.
+type Emitter struct {
+	configManager *config.ConfigManager
+	dial          Dialer
+	onError       func(error)
+
+	mu         sync.Mutex
+	publishers map[string]connection
+}
+
+type connection struct {
+	cfg       config.BrokerConfig
+	publisher Publisher
+}
+
+// NewEmitter creates an Emitter using the Kafka and AMQP clients. onError,
+// which may be nil, receives failed publishes.
+func NewEmitter(cfgManager *config.ConfigManager, onError func(error)) *Emitter {
+	return NewEmitterWithDialer(cfgManager, Dial, onError)
+}
+
+// NewEmitterWithDialer creates an Emitter with a custom Dialer, e.g. for tests
+func NewEmitterWithDialer(cfgManager *config.ConfigManager, dial Dialer, onError func(error)) *Emitter {
+	return &Emitter{
+		configManager: cfgManager,
+		dial:          dial,
+		onError:       onError,
+		publishers:    make(map[string]connection),
+	}
+}
+
+// Emit renders and publishes events in the background, in order
+func (e *Emitter) Emit(emits []config.EventEmit, values map[string]string) {
+	if e == nil || len(emits) == 0 {
+		return
+	}
+	cfg := e.configManager.GetConfig()
+	if cfg == nil {
+		return
+	}
+
+	messages := make([]Message, len(emits))
+	for i, emit := range emits {
+		messages[i] = render(emit, values)
+	}
+
+	go func() {
+		for i, emit := range emits {
+			if err := e.publish(cfg, emit.Broker, messages[i]); err != nil {
+				e.fail(fmt.Errorf("event to broker %s topic %s: %w", emit.Broker, emit.Topic, err))
+			}
+		}
+	}()
+}
+
+func render(emit config.EventEmit, values map[string]string) Message {
+	msg := Message{
+		Topic:    emit.Topic,
+		Exchange: emit.Exchange,
+		Value:    template.ReplaceVariables([]byte(emit.Message), values),
+		Headers:  make(map[string]string, len(emit.Headers)),
+	}
+	if emit.Key != "" {
+		msg.Key = template.ReplaceVariables([]byte(emit.Key), values)
+	}
+	for k, v := range emit.Headers {
+		msg.Headers[k] = string(template.ReplaceVariables([]byte(v), values))
+	}
+	return msg
+}
+
+func (e *Emitter) publish(cfg *config.Config, name string, msg Message) error {
+	brokerCfg, ok := cfg.Brokers[name]
+	if !ok {
+		return errors.New("unknown broker")
+	}
+	publisher, err := e.publisher(name, brokerCfg)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	if err := publisher.Publish(ctx, msg); err != nil {
+		e.drop(name, publisher)
+		return err
+	}
+	return nil
+}
+
+// drop closes a connection after a failed publish so the next event redials
+func (e *Emitter) drop(name string, publisher Publisher) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if conn, ok := e.publishers[name]; ok && conn.publisher == publisher {
+		publisher.Close()
+		delete(e.publishers, name)
+	}
+}
+
+// publisher returns the open connection to a broker, dialing it if needed
+func (e *Emitter) publisher(name string, cfg config.BrokerConfig) (Publisher, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if conn, ok := e.publishers[name]; ok {
+		if reflect.DeepEqual(conn.cfg, cfg) {
+			return conn.publisher, nil
+		}
+		conn.publisher.Close()
+		delete(e.publishers, name)
+	}
+
+	publisher, err := e.dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	e.publishers[name] = connection{cfg: cfg, publisher: publisher}
+	return publisher, nil
+}
+
+// Close closes every open broker connection
+func (e *Emitter) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for name, conn := range e.publishers {
+		conn.publisher.Close()
+		delete(e.publishers, name)
+	}
+}
+
+func (e *Emitter) fail(err error) {
+	if e.onError != nil {
+		e.onError(err)
+	}
+}
@@ -0,0 +1,108 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mock-api-server/config"
+)
+
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []Message
+	fail     bool
+	closed   bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail {
+		return errors.New("broker down")
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *fakePublisher) received() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+func TestEmitter(t *testing.T) {
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Brokers: map[string]config.BrokerConfig{
+		"events": {Type: config.BrokerKafka, Brokers: []string{"localhost:9092"}},
+	}})
+
+	var dials []*fakePublisher
+	var mu sync.Mutex
+	dial := func(cfg config.BrokerConfig) (Publisher, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		p := &fakePublisher{}
+		dials = append(dials, p)
+		return p, nil
+	}
+	errs := make(chan error, 10)
+	e := NewEmitterWithDialer(cm, dial, func(err error) { errs <- err })
+
+	e.Emit([]config.EventEmit{{
+		Broker:  "events",
+		Topic:   "orders",
+		Key:     "{{.user_id}}",
+		Message: `{"user":"{{.user_id}}"}`,
+		Headers: map[string]string{"trace": "{{.order_id}}"},
+	}}, map[string]string{"user_id": "u1", "order_id": "r1"})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(dials)
+		mu.Unlock()
+		if n == 1 && len(dials[0].received()) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected one message to be published")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	msg := dials[0].received()[0]
+	if msg.Topic != "orders" || string(msg.Key) != "u1" || string(msg.Value) != `{"user":"u1"}` || msg.Headers["trace"] != "r1" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	// A failed publish drops the connection so the next event redials
+	dials[0].mu.Lock()
+	dials[0].fail = true
+	dials[0].mu.Unlock()
+	e.Emit([]config.EventEmit{{Broker: "events", Topic: "orders", Message: "x"}}, nil)
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the failure to be reported")
+	}
+	if _, err := e.publisher("events", cm.GetConfig().Brokers["events"]); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dials) != 2 || !dials[0].closed {
+		t.Errorf("expected the failed connection to be closed and redialed, got %d dials", len(dials))
+	}
+}
@@ -0,0 +1,79 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"mock-api-server/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Dial connects to a Kafka or AMQP broker
+func Dial(cfg config.BrokerConfig) (Publisher, error) {
+	switch cfg.Type {
+	case config.BrokerKafka:
+		return &kafkaPublisher{writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		}}, nil
+	case config.BrokerAMQP:
+		conn, err := amqp.Dial(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := conn.Channel()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return &amqpPublisher{conn: conn, channel: ch}, nil
+	}
+	return nil, fmt.Errorf("unsupported broker type '%s'", cfg.Type)
+}
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msg Message) error {
+	km := kafka.Message{Topic: msg.Topic, Key: msg.Key, Value: msg.Value}
+	for k, v := range msg.Headers {
+		km.Headers = append(km.Headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return p.writer.WriteMessages(ctx, km)
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// amqpPublisher serializes publishes because AMQP channels are not safe for
+// concurrent use
+type amqpPublisher struct {
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func (p *amqpPublisher) Publish(ctx context.Context, msg Message) error {
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.channel.PublishWithContext(ctx, msg.Exchange, msg.Topic, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Headers:     headers,
+		Body:        msg.Value,
+	})
+}
+
+func (p *amqpPublisher) Close() error {
+	p.channel.Close()
+	return p.conn.Close()
+}
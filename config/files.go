@@ -0,0 +1,67 @@
+package config
+
+// MapFilePaths replaces every input file the config refers to (response
+// files, scripts, seeds, OpenAPI specs) with fn(path). Empty paths are left
+// alone. The config is changed in place, including its maps, so callers
+// sharing cfg should map a copy.
+func (cfg *Config) MapFilePaths(fn func(path string) string) {
+	mapPath := func(p *string) {
+		if *p != "" {
+			*p = fn(*p)
+		}
+	}
+
+	mapPath(&cfg.HealthCheck.ResponseFile)
+	for code, file := range cfg.Server.ErrorHandling.CustomErrorResponses {
+		mapPath(&file)
+		cfg.Server.ErrorHandling.CustomErrorResponses[code] = file
+	}
+	for _, p := range cfg.Server.ErrorHandling.PrefixErrorResponses {
+		for code, file := range p.CustomErrorResponses {
+			mapPath(&file)
+			p.CustomErrorResponses[code] = file
+		}
+	}
+	for i := range cfg.Server.SchemaFallback.Specs {
+		mapPath(&cfg.Server.SchemaFallback.Specs[i])
+	}
+
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		if ep.CRUD != nil {
+			mapPath(&ep.CRUD.SeedFile)
+		}
+		if ep.Contract != nil {
+			mapPath(&ep.Contract.Spec)
+		}
+		if ep.Challenge != nil && ep.Challenge.Unauthorized != nil {
+			ep.Challenge.Unauthorized.mapFilePaths(mapPath)
+		}
+		if ep.Quota != nil && ep.Quota.Exceeded != nil {
+			ep.Quota.Exceeded.mapFilePaths(mapPath)
+		}
+		ep.Default.mapFilePaths(mapPath)
+		for j := range ep.Rules {
+			ep.Rules[j].ResponseConfig.mapFilePaths(mapPath)
+		}
+		for method, override := range ep.MethodDefaults {
+			override.mapFilePaths(mapPath)
+			ep.MethodDefaults[method] = override
+		}
+	}
+}
+
+func (r *ResponseConfig) mapFilePaths(mapPath func(*string)) {
+	mapPath(&r.ResponseFile)
+	if r.RandomResponses != nil {
+		for i := range r.RandomResponses.Files {
+			mapPath(&r.RandomResponses.Files[i].File)
+		}
+	}
+	if r.Script != nil {
+		mapPath(&r.Script.File)
+	}
+	if r.FromOpenAPI != nil {
+		mapPath(&r.FromOpenAPI.Spec)
+	}
+}
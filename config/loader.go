@@ -1,19 +1,26 @@
 package config
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type rawConfig struct {
-	Server      ServerConfig `yaml:"server"`
-	HealthCheck HealthCheck  `yaml:"health_check"`
-	Endpoints   yaml.Node    `yaml:"endpoints"`
+	Server      ServerConfig              `yaml:"server"`
+	HealthCheck HealthCheck               `yaml:"health_check"`
+	Admin       AdminConfig               `yaml:"admin"`
+	OAuth       OAuthConfig               `yaml:"oauth"`
+	State       StateConfig               `yaml:"state"`
+	Scenarios   map[string]ScenarioConfig `yaml:"scenarios"`
+	Brokers     map[string]BrokerConfig   `yaml:"brokers"`
+	Recorder    RecorderConfig            `yaml:"recorder"`
+	Endpoints   yaml.Node                 `yaml:"endpoints"`
 }
 
 type endpointPathsConfig struct {
@@ -22,6 +29,7 @@ type endpointPathsConfig struct {
 
 type endpointFileConfig struct {
 	Endpoint  `yaml:",inline"`
+	BasePath  string     `yaml:"base_path"` // prefixed to the path of every endpoint in the file
 	Paths     []Endpoint `yaml:"paths"`
 	Endpoints []Endpoint `yaml:"endpoints"`
 }
@@ -33,21 +41,44 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var raw rawConfig
-	if err := yaml.Unmarshal(data, &raw); err != nil {
+	return ParseConfig(data, path)
+}
+
+// ParseConfig parses a YAML (or JSON) config document. path is used to resolve
+// relative endpoint config paths and does not need to exist.
+func ParseConfig(data []byte, path string) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	// Defaults for settings that are on unless configured otherwise
+	raw := rawConfig{Recorder: RecorderConfig{Enabled: true}}
+	raw.Server.ErrorHandling.MethodNotAllowed = true
+	if doc.Kind != 0 {
+		if err := doc.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
 
 	endpoints, endpointConfigPaths, err := parseEndpoints(raw.Endpoints, path)
 	if err != nil {
 		return nil, err
 	}
 
+	assignEndpointIDs(endpoints)
+
 	cfg := Config{
 		Server:              raw.Server,
 		HealthCheck:         raw.HealthCheck,
+		Admin:               raw.Admin,
+		OAuth:               raw.OAuth,
+		State:               raw.State,
+		Scenarios:           raw.Scenarios,
+		Brokers:             raw.Brokers,
+		Recorder:            raw.Recorder,
 		Endpoints:           endpoints,
 		EndpointConfigPaths: endpointConfigPaths,
+		src:                 &source{file: path, node: &doc},
 	}
 
 	// Set defaults
@@ -60,6 +91,12 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.Server.Logging.LogFormat == "" {
 		cfg.Server.Logging.LogFormat = "json"
 	}
+	if cfg.Admin.MocksDir == "" {
+		cfg.Admin.MocksDir = "./mocks"
+	}
+	if cfg.OAuth.PathPrefix == "" {
+		cfg.OAuth.PathPrefix = "/oauth"
+	}
 	if cfg.HealthCheck.Path == "" && cfg.HealthCheck.Enabled {
 		cfg.HealthCheck.Path = "/health"
 	}
@@ -90,6 +127,7 @@ func parseEndpoints(node yaml.Node, mainConfigPath string) ([]Endpoint, []string
 			if err := node.Decode(&endpoints); err != nil {
 				return nil, nil, fmt.Errorf("failed to parse inline endpoints: %w", err)
 			}
+			attachSources(endpoints, mainConfigPath, node.Content)
 			return endpoints, nil, nil
 		}
 		return nil, nil, fmt.Errorf("invalid endpoints format: sequence entries must be all strings or all mappings")
@@ -108,6 +146,7 @@ func parseEndpoints(node yaml.Node, mainConfigPath string) ([]Endpoint, []string
 		if !hasEndpointContent(endpoint) {
 			return nil, nil, fmt.Errorf("invalid endpoints mapping: expected config_paths or a valid endpoint")
 		}
+		endpoint.src = &source{file: mainConfigPath, node: &node}
 		return []Endpoint{endpoint}, nil, nil
 
 	default:
@@ -115,6 +154,21 @@ func parseEndpoints(node yaml.Node, mainConfigPath string) ([]Endpoint, []string
 	}
 }
 
+// EndpointID derives a stable endpoint identifier from its method and path
+func EndpointID(method, path string) string {
+	sum := sha1.Sum([]byte(strings.ToUpper(method) + " " + path))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// assignEndpointIDs fills in IDs for endpoints that do not declare one
+func assignEndpointIDs(endpoints []Endpoint) {
+	for i := range endpoints {
+		if endpoints[i].ID == "" {
+			endpoints[i].ID = EndpointID(endpoints[i].Method, endpoints[i].Path)
+		}
+	}
+}
+
 func allChildrenKind(node yaml.Node, kind yaml.Kind) bool {
 	for _, child := range node.Content {
 		if child.Kind != kind {
@@ -176,6 +230,7 @@ func loadEndpointsFromFile(path string) ([]Endpoint, error) {
 		if len(endpoints) == 0 {
 			return nil, fmt.Errorf("endpoint config file has empty endpoints sequence")
 		}
+		attachSources(endpoints, path, root.Content)
 		return endpoints, nil
 
 	case yaml.MappingNode:
@@ -186,18 +241,27 @@ func loadEndpointsFromFile(path string) ([]Endpoint, error) {
 
 		var endpoints []Endpoint
 		if hasEndpointContent(fileCfg.Endpoint) {
+			fileCfg.Endpoint.src = &source{file: path, node: root}
 			endpoints = append(endpoints, fileCfg.Endpoint)
 		}
 		if len(fileCfg.Paths) > 0 {
+			attachSources(fileCfg.Paths, path, mappingValue(root, "paths").Content)
 			endpoints = append(endpoints, fileCfg.Paths...)
 		}
 		if len(fileCfg.Endpoints) > 0 {
+			attachSources(fileCfg.Endpoints, path, mappingValue(root, "endpoints").Content)
 			endpoints = append(endpoints, fileCfg.Endpoints...)
 		}
 
 		if len(endpoints) == 0 {
 			return nil, fmt.Errorf("endpoint config must define 'path' or 'paths' or 'endpoints'")
 		}
+		if fileCfg.BasePath != "" {
+			if !strings.HasPrefix(fileCfg.BasePath, "/") {
+				return nil, fmt.Errorf("base_path must start with /: %q", fileCfg.BasePath)
+			}
+			applyBasePath(endpoints, fileCfg.BasePath)
+		}
 		return endpoints, nil
 
 	default:
@@ -205,10 +269,38 @@ func loadEndpointsFromFile(path string) ([]Endpoint, error) {
 	}
 }
 
+// applyBasePath prefixes the paths of a group of endpoints with base, and
+// records it so requests can be told apart per simulated service
+func applyBasePath(endpoints []Endpoint, base string) {
+	base = strings.TrimRight(base, "/")
+	for i := range endpoints {
+		switch path := endpoints[i].Path; {
+		case path == "" || path == "/":
+			endpoints[i].Path = base
+		case strings.HasPrefix(path, "/"):
+			endpoints[i].Path = base + path
+		default:
+			endpoints[i].Path = base + "/" + path
+		}
+		endpoints[i].BasePath = base
+	}
+}
+
+// attachSources records the sequence item each endpoint was decoded from
+func attachSources(endpoints []Endpoint, file string, items []*yaml.Node) {
+	for i := range endpoints {
+		if i < len(items) {
+			endpoints[i].src = &source{file: file, node: items[i]}
+		}
+	}
+}
+
 func hasEndpointContent(ep Endpoint) bool {
-	return ep.Path != "" ||
+	return ep.ID != "" ||
+		ep.Path != "" ||
 		ep.Method != "" ||
 		ep.Description != "" ||
+		len(ep.Tags) > 0 ||
 		len(ep.Selectors) > 0 ||
 		len(ep.Rules) > 0 ||
 		ep.Default.ResponseFile != "" ||
@@ -218,109 +310,3 @@ func hasEndpointContent(ep Endpoint) bool {
 		ep.Default.Template != nil ||
 		ep.Default.RandomResponses != nil
 }
-
-// ValidateConfig validates the configuration and returns warnings
-func ValidateConfig(cfg *Config) []string {
-	var warnings []string
-
-	// Validate endpoints
-	for i, ep := range cfg.Endpoints {
-		// Check path
-		if ep.Path == "" {
-			warnings = append(warnings, fmt.Sprintf("endpoint[%d]: path is empty", i))
-		}
-
-		// Check method
-		if ep.Method == "" {
-			warnings = append(warnings, fmt.Sprintf("endpoint[%d]: method is empty", i))
-		}
-
-		// Validate selectors
-		selectorNames := make(map[string]bool)
-		for j, sel := range ep.Selectors {
-			if sel.Name == "" {
-				warnings = append(warnings, fmt.Sprintf("endpoint[%d].selector[%d]: name is empty", i, j))
-			}
-			if selectorNames[sel.Name] {
-				warnings = append(warnings, fmt.Sprintf("endpoint[%d].selector[%d]: duplicate name '%s'", i, j, sel.Name))
-			}
-			selectorNames[sel.Name] = true
-
-			if !isValidSelectorType(sel.Type) {
-				warnings = append(warnings, fmt.Sprintf("endpoint[%d].selector[%d]: invalid type '%s'", i, j, sel.Type))
-			}
-		}
-
-		// Validate rules
-		for j, rule := range ep.Rules {
-			for k, cond := range rule.Conditions {
-				// Check if selector exists
-				if !selectorNames[cond.Selector] {
-					warnings = append(warnings, fmt.Sprintf("endpoint[%d].rule[%d].condition[%d]: unknown selector '%s'", i, j, k, cond.Selector))
-				}
-
-				// Validate match type
-				if !isValidMatchType(cond.MatchType) {
-					warnings = append(warnings, fmt.Sprintf("endpoint[%d].rule[%d].condition[%d]: invalid match_type '%s'", i, j, k, cond.MatchType))
-				}
-
-				// Validate regex patterns
-				if cond.MatchType == "regex" {
-					if _, err := regexp.Compile(cond.Value); err != nil {
-						warnings = append(warnings, fmt.Sprintf("endpoint[%d].rule[%d].condition[%d]: invalid regex '%s': %v", i, j, k, cond.Value, err))
-					}
-				}
-			}
-
-			// Check response file exists
-			if rule.ResponseFile != "" {
-				if _, err := os.Stat(rule.ResponseFile); os.IsNotExist(err) {
-					warnings = append(warnings, fmt.Sprintf("endpoint[%d].rule[%d]: response_file not found: %s", i, j, rule.ResponseFile))
-				}
-			}
-		}
-
-		// Check default response file
-		if ep.Default.ResponseFile != "" {
-			if _, err := os.Stat(ep.Default.ResponseFile); os.IsNotExist(err) {
-				warnings = append(warnings, fmt.Sprintf("endpoint[%d].default: response_file not found: %s", i, ep.Default.ResponseFile))
-			}
-		}
-
-		// Check random response files
-		if ep.Default.RandomResponses != nil && ep.Default.RandomResponses.Enabled {
-			for j, rr := range ep.Default.RandomResponses.Files {
-				if _, err := os.Stat(rr.File); os.IsNotExist(err) {
-					warnings = append(warnings, fmt.Sprintf("endpoint[%d].default.random_responses[%d]: file not found: %s", i, j, rr.File))
-				}
-			}
-		}
-	}
-
-	// Check custom error response files
-	for code, file := range cfg.Server.ErrorHandling.CustomErrorResponses {
-		if _, err := os.Stat(file); os.IsNotExist(err) {
-			warnings = append(warnings, fmt.Sprintf("error_handling.custom_error_responses[%d]: file not found: %s", code, file))
-		}
-	}
-
-	return warnings
-}
-
-func isValidSelectorType(t string) bool {
-	switch strings.ToLower(t) {
-	case "body", "header", "query", "path":
-		return true
-	default:
-		return false
-	}
-}
-
-func isValidMatchType(t string) bool {
-	switch strings.ToLower(t) {
-	case "exact", "prefix", "suffix", "regex", "range":
-		return true
-	default:
-		return false
-	}
-}
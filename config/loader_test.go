@@ -87,3 +87,151 @@ default:
 		}
 	}
 }
+
+func TestParseConfig_JSONDocument(t *testing.T) {
+	doc := `{
+  "server": {"port": 9090},
+  "endpoints": [
+    {"path": "/json", "method": "GET", "default": {"status_code": 204}}
+  ]
+}`
+
+	cfg, err := ParseConfig([]byte(doc), filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Fatalf("expected port 9090, got %d", cfg.Server.Port)
+	}
+	if len(cfg.Endpoints) != 1 || cfg.Endpoints[0].Path != "/json" {
+		t.Fatalf("unexpected endpoints: %+v", cfg.Endpoints)
+	}
+	if cfg.Endpoints[0].Default.StatusCode != 204 {
+		t.Fatalf("expected default status 204, got %d", cfg.Endpoints[0].Default.StatusCode)
+	}
+}
+
+func TestParseConfig_AssignsStableEndpointIDs(t *testing.T) {
+	doc := `endpoints:
+  - path: "/users/:id"
+    method: "get"
+  - id: "create-user"
+    path: "/users"
+    method: "POST"
+`
+	cfg, err := ParseConfig([]byte(doc), "config.yaml")
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+
+	if want := EndpointID("GET", "/users/:id"); cfg.Endpoints[0].ID != want {
+		t.Fatalf("expected derived id %q, got %q", want, cfg.Endpoints[0].ID)
+	}
+	if cfg.Endpoints[1].ID != "create-user" {
+		t.Fatalf("expected explicit id to be kept, got %q", cfg.Endpoints[1].ID)
+	}
+
+	again, _ := ParseConfig([]byte(doc), "config.yaml")
+	if again.Endpoints[0].ID != cfg.Endpoints[0].ID {
+		t.Fatalf("expected derived id to be stable across loads")
+	}
+}
+
+func TestParseConfig_RecorderEnabledByDefault(t *testing.T) {
+	cfg, err := ParseConfig([]byte("server:\n  port: 9000\n"), "config.yaml")
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+	if !cfg.Recorder.Enabled {
+		t.Fatalf("expected recorder to be enabled by default")
+	}
+
+	cfg, err = ParseConfig([]byte("recorder:\n  enabled: false\n"), "config.yaml")
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+	if cfg.Recorder.Enabled {
+		t.Fatalf("expected recorder.enabled: false to disable the recorder")
+	}
+}
+
+func TestParseConfig_MethodNotAllowedByDefault(t *testing.T) {
+	cfg, err := ParseConfig([]byte("server:\n  port: 9000\n"), "config.yaml")
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+	if !cfg.Server.ErrorHandling.MethodNotAllowed {
+		t.Fatalf("expected method_not_allowed to be on by default")
+	}
+
+	cfg, err = ParseConfig([]byte("server:\n  error_handling:\n    method_not_allowed: false\n"), "config.yaml")
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+	if cfg.Server.ErrorHandling.MethodNotAllowed {
+		t.Fatalf("expected method_not_allowed: false to keep 404 responses")
+	}
+}
+
+func TestLoadConfig_EndpointFileBasePath(t *testing.T) {
+	tempDir := t.TempDir()
+	mainConfig := `endpoints:
+  config_paths:
+    - "./payments.yaml"
+`
+	payments := `base_path: "/payments/"
+paths:
+  - path: "/"
+    method: "GET"
+  - path: "/refunds/:id"
+    method: "POST"
+`
+	mainConfigPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(mainConfigPath, []byte(mainConfig), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "payments.yaml"), []byte(payments), 0o644); err != nil {
+		t.Fatalf("write endpoint file failed: %v", err)
+	}
+
+	cfg, err := LoadConfig(mainConfigPath)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	expectedPaths := []string{"/payments", "/payments/refunds/:id"}
+	if len(cfg.Endpoints) != len(expectedPaths) {
+		t.Fatalf("expected %d endpoints, got %d", len(expectedPaths), len(cfg.Endpoints))
+	}
+	for i, want := range expectedPaths {
+		ep := cfg.Endpoints[i]
+		if ep.Path != want || ep.BasePath != "/payments" {
+			t.Errorf("endpoint[%d]: want path %q base_path /payments, got %q %q", i, want, ep.Path, ep.BasePath)
+		}
+		if ep.ID != EndpointID(ep.Method, want) {
+			t.Errorf("endpoint[%d]: expected the ID derived from the full path", i)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "payments.yaml"), []byte(`base_path: "payments"`+"\n"+payments[len(`base_path: "/payments/"`)+1:]), 0o644); err != nil {
+		t.Fatalf("write endpoint file failed: %v", err)
+	}
+	if _, err := LoadConfig(mainConfigPath); err == nil {
+		t.Error("expected a base_path without leading / to be rejected")
+	}
+}
+
+func TestParseConfig_RecorderExclusions(t *testing.T) {
+	doc := `recorder:
+  exclude:
+    - path: "/internal/**"
+    - regex: '^/v\d+/ping$'
+      methods: [GET]
+`
+	cfg, err := ParseConfig([]byte(doc), "config.yaml")
+	if err != nil {
+		t.Fatalf("ParseConfig returned error: %v", err)
+	}
+	if len(cfg.Recorder.Exclude) != 2 || cfg.Recorder.Exclude[1].Methods[0] != "GET" {
+		t.Errorf("unexpected exclusions: %+v", cfg.Recorder.Exclude)
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfigManager_RuntimeEndpoints(t *testing.T) {
+	cm := NewConfigManager("config.yaml")
+	cm.SetConfig(&Config{Endpoints: []Endpoint{{ID: "file-ep", Path: "/file", Method: "GET"}}})
+
+	added, err := cm.AddRuntimeEndpoint(Endpoint{Path: "/runtime", Method: "POST"})
+	if err != nil {
+		t.Fatalf("AddRuntimeEndpoint returned error: %v", err)
+	}
+	if added.ID != EndpointID("POST", "/runtime") {
+		t.Fatalf("expected derived id, got %q", added.ID)
+	}
+	if _, err := cm.AddRuntimeEndpoint(Endpoint{ID: "file-ep", Path: "/x", Method: "GET"}); !errors.Is(err, ErrEndpointExists) {
+		t.Fatalf("expected ErrEndpointExists, got %v", err)
+	}
+
+	cfg := cm.GetConfig()
+	if len(cfg.Endpoints) != 2 || cfg.Endpoints[0].ID != added.ID {
+		t.Fatalf("expected runtime endpoint first in merged config, got %+v", cfg.Endpoints)
+	}
+
+	// Runtime endpoints survive a config reload
+	cm.SetConfig(&Config{Endpoints: []Endpoint{{ID: "other", Path: "/other", Method: "GET"}}})
+	if !cm.IsRuntimeEndpoint(added.ID) || len(cm.GetConfig().Endpoints) != 2 {
+		t.Fatalf("expected runtime endpoint to be kept after SetConfig")
+	}
+
+	updated, err := cm.UpdateRuntimeEndpoint(added.ID, Endpoint{Path: "/runtime/v2", Method: "PUT"})
+	if err != nil || updated.ID != added.ID {
+		t.Fatalf("UpdateRuntimeEndpoint = (%+v, %v), want id kept", updated, err)
+	}
+	if _, err := cm.UpdateRuntimeEndpoint("other", Endpoint{}); !errors.Is(err, ErrNotRuntimeEndpoint) {
+		t.Fatalf("expected ErrNotRuntimeEndpoint for file endpoint, got %v", err)
+	}
+
+	if err := cm.DeleteRuntimeEndpoint(added.ID); err != nil {
+		t.Fatalf("DeleteRuntimeEndpoint returned error: %v", err)
+	}
+	if err := cm.DeleteRuntimeEndpoint(added.ID); !errors.Is(err, ErrEndpointNotFound) {
+		t.Fatalf("expected ErrEndpointNotFound, got %v", err)
+	}
+	if len(cm.GetConfig().Endpoints) != 1 {
+		t.Fatalf("expected only file endpoint to remain")
+	}
+}
+
+func TestConfigManager_ResetRuntime(t *testing.T) {
+	cm := NewConfigManager("config.yaml")
+	cm.SetConfig(&Config{Endpoints: []Endpoint{{ID: "file-ep", Path: "/file", Method: "GET"}}})
+	if _, err := cm.AddRuntimeEndpoint(Endpoint{Path: "/runtime", Method: "GET"}); err != nil {
+		t.Fatalf("AddRuntimeEndpoint returned error: %v", err)
+	}
+	cm.SetEndpointEnabled("file-ep", false)
+
+	cm.ResetRuntime()
+
+	if len(cm.GetConfig().Endpoints) != 1 {
+		t.Fatalf("expected runtime endpoints to be removed, got %+v", cm.GetConfig().Endpoints)
+	}
+	if !cm.IsEndpointEnabled("file-ep") {
+		t.Fatal("expected disabled endpoint to be re-enabled")
+	}
+}
+
+func TestEndpointDefaultFor(t *testing.T) {
+	ep := Endpoint{
+		Method:  MethodAny,
+		Default: ResponseConfig{ResponseFile: "item.json", StatusCode: 200, Headers: map[string]string{"X-A": "1"}},
+		MethodDefaults: map[string]ResponseConfig{
+			"POST":   {StatusCode: 201},
+			"delete": {StatusCode: 204, ResponseFile: "empty.json"},
+		},
+	}
+
+	if got := ep.DefaultFor("GET"); got.StatusCode != 200 || got.ResponseFile != "item.json" {
+		t.Errorf("expected the plain default for GET, got %+v", got)
+	}
+	if got := ep.DefaultFor("POST"); got.StatusCode != 201 || got.ResponseFile != "item.json" || got.Headers["X-A"] != "1" {
+		t.Errorf("expected POST to override only the status, got %+v", got)
+	}
+	if got := ep.DefaultFor("DELETE"); got.StatusCode != 204 || got.ResponseFile != "empty.json" {
+		t.Errorf("expected DELETE override matched case-insensitively, got %+v", got)
+	}
+	if ep.Default.StatusCode != 200 {
+		t.Error("expected DefaultFor to leave the endpoint's default untouched")
+	}
+
+	if !ep.AcceptsMethod("PATCH") {
+		t.Error("expected an ANY endpoint to accept PATCH")
+	}
+	if (&Endpoint{Method: "GET"}).AcceptsMethod("POST") {
+		t.Error("expected a GET endpoint to reject POST")
+	}
+}
+
+func TestErrorResponseFile(t *testing.T) {
+	eh := ErrorHandling{
+		CustomErrorResponses: map[int]string{404: "global_404.json", 500: "global_500.json"},
+		PrefixErrorResponses: []PrefixErrorResponses{
+			{Prefix: "/billing/", CustomErrorResponses: map[int]string{404: "billing_404.json"}},
+			{Prefix: "/billing/v2", CustomErrorResponses: map[int]string{404: "billing_v2_404.json"}},
+		},
+	}
+
+	for _, tt := range []struct {
+		status int
+		path   string
+		want   string
+	}{
+		{404, "/billing", "billing_404.json"},
+		{404, "/billing/invoices/1", "billing_404.json"},
+		{404, "/billing/v2/invoices", "billing_v2_404.json"},
+		{404, "/billingx", "global_404.json"},
+		{500, "/billing/invoices", "global_500.json"},
+		{429, "/billing", ""},
+	} {
+		if got, _ := eh.ErrorResponseFile(tt.status, tt.path); got != tt.want {
+			t.Errorf("ErrorResponseFile(%d, %s) = %q, want %q", tt.status, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestConfigManager_Generation(t *testing.T) {
+	cm := NewConfigManager("config.yaml")
+	cm.SetConfig(&Config{})
+	first := cm.Generation()
+
+	if _, err := cm.AddRuntimeEndpoint(Endpoint{Path: "/runtime", Method: "GET"}); err != nil {
+		t.Fatalf("AddRuntimeEndpoint returned error: %v", err)
+	}
+	if cm.Generation() <= first {
+		t.Fatalf("expected runtime endpoint change to bump generation past %d, got %d", first, cm.Generation())
+	}
+
+	second := cm.Generation()
+	cm.SetConfig(&Config{})
+	if cm.Generation() <= second {
+		t.Fatalf("expected reload to bump generation past %d, got %d", second, cm.Generation())
+	}
+}
+
+func TestConfigManager_Subscribe(t *testing.T) {
+	cm := NewConfigManager("config.yaml")
+	first := &Config{}
+	cm.SetConfig(first)
+
+	type change struct{ old, new *Config }
+	var changes []change
+	unsubscribe := cm.Subscribe(func(old, new *Config) {
+		changes = append(changes, change{old, new})
+	})
+
+	second := &Config{}
+	cm.SetConfig(second)
+	if len(changes) != 1 || changes[0].new != cm.GetConfig() || changes[0].old == changes[0].new {
+		t.Fatalf("expected one change to the new config, got %+v", changes)
+	}
+	previous := changes[0].new
+
+	if _, err := cm.AddRuntimeEndpoint(Endpoint{Path: "/runtime", Method: "GET"}); err != nil {
+		t.Fatalf("AddRuntimeEndpoint returned error: %v", err)
+	}
+	if len(changes) != 2 || changes[1].old != previous || len(changes[1].new.Endpoints) != 1 {
+		t.Fatalf("expected runtime endpoint change from the previous config, got %+v", changes)
+	}
+
+	// Failed changes leave the config alone and notify nobody
+	if _, err := cm.AddRuntimeEndpoint(Endpoint{Path: "/runtime", Method: "GET"}); !errors.Is(err, ErrEndpointExists) {
+		t.Fatalf("expected ErrEndpointExists, got %v", err)
+	}
+	unsubscribe()
+	cm.SetConfig(first)
+	if len(changes) != 2 {
+		t.Fatalf("expected no changes after a failed update or unsubscribe, got %d", len(changes))
+	}
+}
@@ -0,0 +1,877 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mock-api-server/pkg/openapi"
+	"mock-api-server/pkg/script"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue severities
+const (
+	SeverityError   = "error"   // the setting cannot work as configured
+	SeverityWarning = "warning" // the setting is ignored or has no effect
+)
+
+// Issue is one finding of config validation. File, Line and Column point at
+// the offending YAML node when the config was read from a document.
+type Issue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Location string `json:"location,omitempty"` // e.g. endpoint[2].rule[0].condition[1]
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// String formats the issue as "file:line:col: location: message", leaving
+// out the parts that are unknown
+func (i Issue) String() string {
+	msg := i.Message
+	if i.Location != "" {
+		msg = i.Location + ": " + msg
+	}
+	if i.Line == 0 {
+		return msg
+	}
+	if i.File == "" {
+		return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", i.File, i.Line, i.Column, msg)
+}
+
+// source is the YAML node a config element was decoded from
+type source struct {
+	file string
+	node *yaml.Node
+}
+
+// ValidateConfig validates the configuration and returns warnings
+func ValidateConfig(cfg *Config) []string {
+	issues := Validate(cfg)
+	if issues == nil {
+		return nil
+	}
+	warnings := make([]string, len(issues))
+	for i, issue := range issues {
+		warnings[i] = issue.String()
+	}
+	return warnings
+}
+
+// Validate checks the configuration and returns the issues found, located
+// in their source files where known
+func Validate(cfg *Config) []Issue {
+	v := &validator{}
+	v.validate(cfg)
+	for i := range v.issues {
+		locate(cfg, &v.issues[i])
+	}
+	return v.issues
+}
+
+type validator struct {
+	issues []Issue
+
+	maxFileBytes int64 // response file size limit, 0 means none
+	strictSize   bool  // oversized response files are errors rather than warnings
+	allowExec    bool  // responder commands may run
+}
+
+func (v *validator) errorf(code, location, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Code: code, Severity: SeverityError, Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(code, location, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Code: code, Severity: SeverityWarning, Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(cfg *Config) {
+	v.maxFileBytes = cfg.Server.MaxResponseFileBytes
+	v.strictSize = cfg.Server.StrictResponseFileSize
+	v.allowExec = cfg.Server.AllowExecResponders
+
+	// Validate endpoints
+	endpointIDs := make(map[string]int)
+	for i, ep := range cfg.Endpoints {
+		loc := fmt.Sprintf("endpoint[%d]", i)
+
+		// Check ID uniqueness
+		if ep.ID != "" {
+			if first, exists := endpointIDs[ep.ID]; exists {
+				v.errorf("duplicate_id", loc, "duplicate id '%s' (first used by endpoint[%d])", ep.ID, first)
+			} else {
+				endpointIDs[ep.ID] = i
+			}
+		}
+
+		// Check path
+		if ep.Path == "" {
+			v.errorf("missing_path", loc, "path is empty")
+		}
+
+		// Check method; crud and sink endpoints answer every method
+		switch ep.Mode {
+		case "":
+			if ep.Method == "" {
+				v.errorf("missing_method", loc, "method is empty")
+			}
+		case EndpointModeCRUD:
+			if ep.CRUD != nil && ep.CRUD.SeedFile != "" {
+				if _, err := os.Stat(ep.CRUD.SeedFile); os.IsNotExist(err) {
+					v.errorf("file_not_found", loc, "crud seed_file not found: %s", ep.CRUD.SeedFile)
+				}
+			}
+			if len(ep.Rules) > 0 || ep.Scenario != "" {
+				v.warnf("ignored_setting", loc, "rules and scenario are ignored in crud mode")
+			}
+		case EndpointModeSink:
+			if len(ep.Rules) > 0 || ep.Scenario != "" {
+				v.warnf("ignored_setting", loc, "rules and scenario are ignored in sink mode")
+			}
+			if code := ep.Default.StatusCode; code != 0 && (code < 200 || code > 299) {
+				v.warnf("invalid_status", loc, "sink status_code %d is not 2xx", code)
+			}
+		case EndpointModeWebSocket:
+			if !strings.EqualFold(ep.Method, "GET") {
+				v.errorf("invalid_value", loc, "websocket endpoints need method GET")
+			}
+			if len(ep.Rules) > 0 || ep.Scenario != "" {
+				v.warnf("ignored_setting", loc, "rules and scenario are ignored in websocket mode")
+			}
+			if ep.Idempotency != nil {
+				v.warnf("ignored_setting", loc+".idempotency", "ignored in websocket mode")
+			}
+			v.validateWebSocket(loc+".websocket", ep.WebSocket)
+		default:
+			v.errorf("invalid_mode", loc, "invalid mode '%s'", ep.Mode)
+		}
+
+		v.validateContract(loc, ep)
+		v.validateMediaTypes(loc+".consumes", ep.Consumes)
+		v.validateMediaTypes(loc+".produces", ep.Produces)
+		v.validateChallenge(loc+".challenge", ep.Challenge)
+		if ep.Idempotency != nil && ep.Idempotency.TTLSec < 0 {
+			v.errorf("invalid_value", loc+".idempotency", "ttl_sec must not be negative")
+		}
+		if q := ep.Quota; q != nil {
+			if q.Limit <= 0 || q.WindowSec < 0 {
+				v.errorf("invalid_value", loc+".quota", "limit must be positive and window_sec not negative")
+			}
+			if q.Exceeded != nil && q.Exceeded.ResponseFile != "" {
+				v.checkResponseFile(loc+".quota.exceeded", "response_file", q.Exceeded.ResponseFile)
+			}
+		}
+
+		// Validate selectors
+		selectorNames := make(map[string]bool)
+		for j, sel := range ep.Selectors {
+			selLoc := fmt.Sprintf("%s.selector[%d]", loc, j)
+			if sel.Name == "" {
+				v.errorf("missing_name", selLoc, "name is empty")
+			}
+			if selectorNames[sel.Name] {
+				v.errorf("duplicate_selector", selLoc, "duplicate name '%s'", sel.Name)
+			}
+			selectorNames[sel.Name] = true
+
+			if !isValidSelectorType(sel.Type) {
+				v.errorf("invalid_selector_type", selLoc, "invalid type '%s'", sel.Type)
+			}
+		}
+
+		// Validate scenario settings
+		if ep.PartitionSelector != "" && !selectorNames[ep.PartitionSelector] {
+			v.errorf("unknown_selector", loc, "unknown partition_selector '%s'", ep.PartitionSelector)
+		}
+		for j, sel := range ep.Selectors {
+			if !strings.EqualFold(sel.Type, "state") {
+				continue
+			}
+			selLoc := fmt.Sprintf("%s.selector[%d]", loc, j)
+			if ep.Scenario == "" {
+				v.warnf("ignored_setting", selLoc, "state selector has no effect without scenario")
+			}
+			if sel.Name == ep.PartitionSelector {
+				v.errorf("invalid_partition_selector", selLoc, "state selector cannot be the partition_selector")
+			}
+		}
+		if ep.Scenario == "" {
+			if ep.PartitionSelector != "" || ep.Default.NewStep != "" || len(ep.Default.SetVariables) > 0 {
+				v.warnf("ignored_setting", loc, "partition_selector, new_step and set_variables have no effect without scenario")
+			}
+			for j, rule := range ep.Rules {
+				if rule.RequiredStep != "" || rule.NewStep != "" || len(rule.SetVariables) > 0 {
+					v.warnf("ignored_setting", fmt.Sprintf("%s.rule[%d]", loc, j), "required_step, new_step and set_variables have no effect without scenario")
+				}
+			}
+		}
+		for name, sel := range ep.Default.SetVariables {
+			if !selectorNames[sel] {
+				v.errorf("unknown_selector", loc+".default", "set_variables.%s uses unknown selector '%s'", name, sel)
+			}
+		}
+		for j, rule := range ep.Rules {
+			for name, sel := range rule.SetVariables {
+				if !selectorNames[sel] {
+					v.errorf("unknown_selector", fmt.Sprintf("%s.rule[%d]", loc, j), "set_variables.%s uses unknown selector '%s'", name, sel)
+				}
+			}
+		}
+
+		// Validate rules
+		for j, rule := range ep.Rules {
+			ruleLoc := fmt.Sprintf("%s.rule[%d]", loc, j)
+			for k, cond := range rule.Conditions {
+				condLoc := fmt.Sprintf("%s.condition[%d]", ruleLoc, k)
+
+				// Check if selector exists; scenario variables are referenced as var.<name>
+				isVariable := ep.Scenario != "" && strings.HasPrefix(cond.Selector, "var.")
+				if !selectorNames[cond.Selector] && !isVariable {
+					v.errorf("unknown_selector", condLoc, "unknown selector '%s'", cond.Selector)
+				}
+
+				// Validate match type
+				if !isValidMatchType(cond.MatchType) {
+					v.errorf("invalid_match_type", condLoc, "invalid match_type '%s'", cond.MatchType)
+				}
+
+				// Validate regex patterns
+				if cond.MatchType == "regex" {
+					if _, err := regexp.Compile(cond.Value); err != nil {
+						v.errorf("invalid_regex", condLoc, "invalid regex '%s': %v", cond.Value, err)
+					}
+				}
+				if cond.MatchType == "json_equals" {
+					v.checkExpectedJSON(condLoc, cond.Value)
+				}
+			}
+
+			// Check response file exists
+			if rule.ResponseFile != "" {
+				v.checkResponseFile(ruleLoc, "response_file", rule.ResponseFile)
+			}
+			v.validateResponse(ruleLoc, rule.ResponseConfig, cfg.Brokers)
+		}
+		v.validateResponse(loc+".default", ep.Default, cfg.Brokers)
+
+		for method, override := range ep.MethodDefaults {
+			mdLoc := fmt.Sprintf("%s.method_defaults[%s]", loc, method)
+			if !strings.EqualFold(ep.Method, MethodAny) && ep.Mode != EndpointModeSink {
+				v.warnf("ignored_setting", mdLoc, "only used with method ANY")
+			}
+			if override.ResponseFile != "" {
+				v.checkResponseFile(mdLoc, "response_file", override.ResponseFile)
+			}
+			v.validateResponder(mdLoc, override)
+		}
+
+		// Check default response file
+		if ep.Default.ResponseFile != "" {
+			v.checkResponseFile(loc+".default", "response_file", ep.Default.ResponseFile)
+		}
+
+		// Check random response files
+		if ep.Default.RandomResponses != nil && ep.Default.RandomResponses.Enabled {
+			for j, rr := range ep.Default.RandomResponses.Files {
+				v.checkResponseFile(fmt.Sprintf("%s.default.random_responses[%d]", loc, j), "file", rr.File)
+			}
+		}
+	}
+	v.validateRoutes(cfg.Endpoints)
+
+	// Check custom error response files
+	for code, file := range cfg.Server.ErrorHandling.CustomErrorResponses {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			v.errorf("file_not_found", fmt.Sprintf("error_handling.custom_error_responses[%d]", code), "file not found: %s", file)
+		}
+	}
+	for i, p := range cfg.Server.ErrorHandling.PrefixErrorResponses {
+		loc := fmt.Sprintf("error_handling.prefix_error_responses[%d]", i)
+		if !strings.HasPrefix(p.Prefix, "/") {
+			v.errorf("invalid_value", loc+".prefix", "prefix must start with /: %q", p.Prefix)
+		}
+		for code, file := range p.CustomErrorResponses {
+			if _, err := os.Stat(file); os.IsNotExist(err) {
+				v.errorf("file_not_found", fmt.Sprintf("%s.custom_error_responses[%d]", loc, code), "file not found: %s", file)
+			}
+		}
+	}
+
+	// Check schema fallback specs
+	for i, spec := range cfg.Server.SchemaFallback.Specs {
+		loc := fmt.Sprintf("schema_fallback.specs[%d]", i)
+		data, err := os.ReadFile(spec)
+		if err != nil {
+			v.errorf("file_not_found", loc, "file not found: %s", spec)
+		} else if _, err := openapi.Parse(data); err != nil {
+			v.errorf("invalid_spec", loc, "%v", err)
+		}
+	}
+
+	switch cfg.Server.Logging.AccessLogFormat {
+	case "", "combined":
+	default:
+		v.errorf("invalid_value", "logging.access_log_format", "unknown access_log_format '%s'", cfg.Server.Logging.AccessLogFormat)
+	}
+
+	// Check logging overrides
+	for i, o := range cfg.Server.Logging.Overrides {
+		loc := fmt.Sprintf("logging.overrides[%d]", i)
+		if o.Sample < 0 || o.Sample > 1 {
+			v.errorf("invalid_value", loc, "sample %v is not between 0 and 1", o.Sample)
+		}
+		switch strings.ToLower(o.Level) {
+		case "", "none", "off", "debug", "info", "warn", "error":
+		default:
+			v.errorf("invalid_value", loc, "unknown level '%s'", o.Level)
+		}
+	}
+
+	// Check server timeouts
+	if t := cfg.Server.Timeouts; t.ReadMs < 0 || t.ReadHeaderMs < 0 || t.WriteMs < 0 || t.IdleMs < 0 {
+		v.errorf("invalid_value", "server.timeouts", "timeouts must not be negative")
+	}
+
+	// Check IP filter entries
+	for _, entry := range append(append([]string{}, cfg.Server.IPFilter.Allow...), cfg.Server.IPFilter.Deny...) {
+		if !isValidIPOrCIDR(entry) {
+			v.errorf("invalid_value", "server.ip_filter", "invalid IP or CIDR '%s'", entry)
+		}
+	}
+
+	for _, entry := range cfg.Server.TrustedProxies {
+		if !isValidIPOrCIDR(entry) {
+			v.errorf("invalid_value", "server.trusted_proxies", "invalid IP or CIDR '%s'", entry)
+		}
+	}
+
+	// Check chaos settings
+	if chaos := cfg.Server.Chaos; chaos.Enabled {
+		if chaos.ErrorRate < 0 || chaos.ErrorRate > 100 {
+			v.errorf("invalid_value", "server.chaos", "error_rate %v is not between 0 and 100", chaos.ErrorRate)
+		}
+		if chaos.LatencyRate < 0 || chaos.LatencyRate > 100 {
+			v.errorf("invalid_value", "server.chaos", "latency_rate %v is not between 0 and 100", chaos.LatencyRate)
+		}
+		for status := range chaos.ErrorStatuses {
+			if status < 100 || status > 599 {
+				v.errorf("invalid_status", "server.chaos", "error_statuses has invalid status code %d", status)
+			}
+		}
+	}
+
+	// Check state backend
+	switch cfg.State.Backend {
+	case "", "memory":
+	case "redis":
+		if cfg.State.PersistFile != "" {
+			v.warnf("ignored_setting", "state.persist_file", "ignored with the redis backend")
+		}
+	default:
+		v.errorf("invalid_value", "state.backend", "invalid backend '%s'", cfg.State.Backend)
+	}
+
+	// Check brokers
+	for name, b := range cfg.Brokers {
+		loc := "brokers." + name
+		switch b.Type {
+		case BrokerKafka:
+			if len(b.Brokers) == 0 {
+				v.errorf("missing_value", loc, "kafka needs at least one broker address")
+			}
+		case BrokerAMQP:
+			if u, err := url.Parse(b.URL); err != nil || (u.Scheme != "amqp" && u.Scheme != "amqps") {
+				v.errorf("invalid_url", loc, "amqp needs an amqp:// or amqps:// url")
+			}
+		default:
+			v.errorf("invalid_value", loc, "invalid type '%s'", b.Type)
+		}
+	}
+
+	// Check scenario settings
+	for name, sc := range cfg.Scenarios {
+		loc := "scenarios." + name
+		if sc.TTLSec < 0 {
+			v.errorf("invalid_value", loc, "ttl_sec must not be negative")
+		}
+		if pk := sc.PartitionKey; pk != nil && (!isValidSelectorType(pk.Type) || strings.EqualFold(pk.Type, "state") || strings.EqualFold(pk.Type, "counter") || pk.Key == "") {
+			v.errorf("invalid_value", loc, "partition_key needs a body, header, query or path type and a key")
+		}
+		for j, wh := range sc.Webhooks {
+			if u, err := url.Parse(wh.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				v.errorf("invalid_url", fmt.Sprintf("%s.webhooks[%d]", loc, j), "invalid url '%s'", wh.URL)
+			}
+		}
+	}
+
+	// Check recorder exclusions
+	for i, e := range cfg.Recorder.Exclude {
+		if _, err := e.Pattern(); err != nil {
+			v.errorf("invalid_value", fmt.Sprintf("recorder.exclude[%d]", i), "%v", err)
+		}
+	}
+}
+
+func isValidIPOrCIDR(entry string) bool {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		_, _, err := net.ParseCIDR(entry)
+		return err == nil
+	}
+	return net.ParseIP(entry) != nil
+}
+
+func isValidSelectorType(t string) bool {
+	switch strings.ToLower(t) {
+	case "body", "header", "query", "path", "state", "counter":
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidMatchType(t string) bool {
+	switch strings.ToLower(t) {
+	case "exact", "prefix", "suffix", "regex", "range", "json_equals":
+		return true
+	default:
+		return false
+	}
+}
+
+// checkResponseFile checks that a response file exists and is within
+// max_response_file_bytes
+func (v *validator) checkResponseFile(loc, label, path string) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		v.errorf("file_not_found", loc, "%s not found: %s", label, path)
+		return
+	}
+	if err != nil || v.maxFileBytes <= 0 || info.Size() <= v.maxFileBytes {
+		return
+	}
+	if v.strictSize {
+		v.errorf("file_too_large", loc, "%s %s is %d bytes, over max_response_file_bytes %d", label, path, info.Size(), v.maxFileBytes)
+	} else {
+		v.warnf("file_too_large", loc, "%s %s is %d bytes, over max_response_file_bytes %d; it is streamed without templates or mutations", label, path, info.Size(), v.maxFileBytes)
+	}
+}
+
+// checkExpectedJSON checks that the expected document of a json_equals
+// condition exists and is JSON
+func (v *validator) checkExpectedJSON(loc, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.errorf("file_not_found", loc, "json_equals file not found: %s", path)
+		return
+	}
+	if !json.Valid(data) {
+		v.errorf("invalid_json", loc, "json_equals file %s is not valid JSON", path)
+	}
+}
+
+// validateChallenge checks the scheme and settings of an auth challenge
+func (v *validator) validateChallenge(loc string, ch *AuthChallenge) {
+	if ch == nil {
+		return
+	}
+	switch strings.ToLower(ch.Scheme) {
+	case "", ChallengeBasic, ChallengeDigest:
+		if len(ch.Tokens) > 0 {
+			v.warnf("ignored_setting", loc, "tokens are only used with scheme bearer")
+		}
+	case ChallengeBearer:
+		if len(ch.Users) > 0 {
+			v.warnf("ignored_setting", loc, "users are not used with scheme bearer")
+		}
+	default:
+		v.errorf("invalid_value", loc, "invalid scheme '%s'", ch.Scheme)
+	}
+	if ch.FailAttempts < 0 {
+		v.errorf("invalid_value", loc, "fail_attempts must not be negative")
+	}
+	if ch.Unauthorized != nil && ch.Unauthorized.ResponseFile != "" {
+		v.checkResponseFile(loc+".unauthorized", "response_file", ch.Unauthorized.ResponseFile)
+	}
+}
+
+// validateRoutes warns about endpoints that can never be served because an
+// earlier endpoint has the same method and path shape. Parameter names do
+// not matter to routing, so /users/:id and /users/:uid are the same route.
+func (v *validator) validateRoutes(endpoints []Endpoint) {
+	first := make(map[string]int)
+	for i, ep := range endpoints {
+		method := strings.ToUpper(ep.Method)
+		if ep.Mode == EndpointModeCRUD || ep.Mode == EndpointModeSink || method == MethodAny {
+			method = MethodAny
+		}
+		keys := []string{method + " " + routeShape(ep.Path)}
+		if ep.Mode == EndpointModeCRUD {
+			keys = append(keys, method+" "+routeShape(strings.TrimRight(ep.Path, "/")+"/:id"))
+		}
+
+		shadowedBy, reachable := -1, false
+		for _, key := range keys {
+			if j, exists := first[key]; exists {
+				shadowedBy = j
+			} else {
+				first[key] = i
+				reachable = true
+			}
+		}
+		if !reachable {
+			v.warnf("shadowed_endpoint", fmt.Sprintf("endpoint[%d]", i), "never served: endpoint[%d] has the same method and path", shadowedBy)
+		}
+	}
+}
+
+// routeShape replaces the parameter names of a path pattern, which routing
+// ignores
+func routeShape(pattern string) string {
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") {
+			segments[i] = ":"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// validateWebSocket checks the message rules and frames of a websocket
+// endpoint
+func (v *validator) validateWebSocket(loc string, ws *WSConfig) {
+	if ws == nil {
+		return
+	}
+	v.validateFrames(loc+".on_connect", ws.OnConnect)
+	v.validateFrames(loc+".default", ws.Default)
+	for i, msg := range ws.Messages {
+		mloc := fmt.Sprintf("%s.messages[%d]", loc, i)
+		if msg.MatchType != "" && !isValidMatchType(msg.MatchType) {
+			v.errorf("invalid_match_type", mloc, "invalid match_type '%s'", msg.MatchType)
+		}
+		if msg.MatchType == "regex" {
+			if _, err := regexp.Compile(msg.Value); err != nil {
+				v.errorf("invalid_regex", mloc, "invalid regex '%s': %v", msg.Value, err)
+			}
+		}
+		if msg.MatchType == "json_equals" {
+			v.checkExpectedJSON(mloc, msg.Value)
+		}
+		v.validateFrames(mloc+".reply", msg.Reply)
+	}
+	for i, push := range ws.Push {
+		ploc := fmt.Sprintf("%s.push[%d]", loc, i)
+		if push.IntervalMs <= 0 {
+			v.errorf("invalid_value", ploc, "interval_ms must be positive")
+		}
+		if push.Count < 0 {
+			v.errorf("invalid_value", ploc, "count must not be negative")
+		}
+		v.validateFrame(ploc, push.WSFrame)
+	}
+}
+
+// validateFrames checks the files and scripts of websocket frames
+func (v *validator) validateFrames(loc string, frames []WSFrame) {
+	for i, f := range frames {
+		v.validateFrame(fmt.Sprintf("%s[%d]", loc, i), f)
+	}
+}
+
+func (v *validator) validateFrame(loc string, f WSFrame) {
+	if f.File != "" {
+		if _, err := os.Stat(f.File); err != nil {
+			v.errorf("file_not_found", loc, "frame file not found: %s", f.File)
+		}
+	}
+	v.validateScript(loc, f.Script)
+	if f.DelayMs < 0 {
+		v.errorf("invalid_value", loc, "delay_ms must not be negative")
+	}
+}
+
+// validateMediaTypes checks the entries of consumes or produces
+func (v *validator) validateMediaTypes(loc string, types []string) {
+	for i, t := range types {
+		if _, _, err := mime.ParseMediaType(t); err != nil {
+			v.errorf("invalid_value", fmt.Sprintf("%s[%d]", loc, i), "invalid media type '%s'", t)
+		}
+	}
+}
+
+// validateResponse checks the parts of a rule or default response that
+// produce or post-process the body
+func (v *validator) validateResponse(loc string, resp ResponseConfig, brokers map[string]BrokerConfig) {
+	v.validateScript(loc, resp.Script)
+	v.validateResponder(loc, resp)
+	v.validateEvents(loc, resp.Events, brokers)
+	v.validateMutations(loc, resp.Mutate)
+	v.validateOpenAPIExample(loc, resp)
+}
+
+// validateScript checks that a response script exists and compiles
+func (v *validator) validateScript(loc string, sc *ScriptConfig) {
+	if sc == nil {
+		return
+	}
+	source := sc.Source
+	if sc.File != "" {
+		data, err := os.ReadFile(sc.File)
+		if err != nil {
+			v.errorf("file_not_found", loc, "script file not found: %s", sc.File)
+			return
+		}
+		source = string(data)
+	} else if source == "" {
+		v.errorf("missing_value", loc, "script needs file or source")
+		return
+	}
+	if err := script.Compile(source, loc); err != nil {
+		v.errorf("invalid_script", loc, "invalid script: %v", err)
+	}
+}
+
+// validateResponder checks that a responder has exactly one target
+func (v *validator) validateResponder(loc string, resp ResponseConfig) {
+	r := resp.Responder
+	if r == nil {
+		return
+	}
+	switch {
+	case len(r.Command) == 0 && r.URL == "":
+		v.errorf("missing_value", loc, "responder needs command or url")
+	case len(r.Command) > 0 && !v.allowExec:
+		v.errorf("exec_disabled", loc, "responder commands need server.allow_exec_responders")
+	case len(r.Command) > 0 && r.URL != "":
+		v.warnf("ignored_setting", loc, "responder has both command and url, command is used")
+	case r.URL != "":
+		if u, err := url.Parse(r.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			v.errorf("invalid_url", loc, "invalid responder url '%s'", r.URL)
+		}
+	}
+	if resp.Script != nil {
+		v.warnf("ignored_setting", loc, "script and responder are both set, script is used")
+	}
+}
+
+// validateEvents checks that every event names a configured broker and topic
+func (v *validator) validateEvents(loc string, emits []EventEmit, brokers map[string]BrokerConfig) {
+	for k, e := range emits {
+		eventLoc := fmt.Sprintf("%s.events[%d]", loc, k)
+		if _, ok := brokers[e.Broker]; !ok {
+			v.errorf("unknown_broker", eventLoc, "unknown broker '%s'", e.Broker)
+		}
+		if e.Topic == "" && e.Exchange == "" {
+			v.errorf("missing_value", eventLoc, "topic is empty")
+		}
+	}
+}
+
+// validateMutations checks mutation paths, types and ranges
+func (v *validator) validateMutations(loc string, mutations []Mutation) {
+	for k, m := range mutations {
+		mutLoc := fmt.Sprintf("%s.mutate[%d]", loc, k)
+		if m.JSONPath == "" || m.JSONPath == "$" {
+			v.errorf("missing_value", mutLoc, "json_path must name a field")
+		}
+		switch m.Type {
+		case MutateRandomInt, MutateRandomFloat:
+			if m.Max < m.Min {
+				v.errorf("invalid_value", mutLoc, "max %v is below min %v", m.Max, m.Min)
+			}
+		case MutateOneOf:
+			if len(m.Values) == 0 {
+				v.errorf("missing_value", mutLoc, "one_of needs values")
+			}
+		case MutateRandomBool, MutateRandomString, MutateUUID:
+		default:
+			v.errorf("invalid_value", mutLoc, "unknown type '%s'", m.Type)
+		}
+	}
+}
+
+// validateContract checks that a contract's spec parses and documents the
+// linked operation
+func (v *validator) validateContract(loc string, ep Endpoint) {
+	ct := ep.Contract
+	if ct == nil {
+		return
+	}
+	loc += ".contract"
+	if ct.OnMismatch != "" && ct.OnMismatch != ContractLog && ct.OnMismatch != ContractFail {
+		v.errorf("invalid_value", loc, "invalid contract on_mismatch '%s'", ct.OnMismatch)
+	}
+	doc, ok := v.loadSpec(loc, ct.Spec)
+	if !ok {
+		return
+	}
+	if ct.OperationID != "" {
+		if _, ok := doc.FindOperation(ct.OperationID); !ok {
+			v.errorf("unknown_operation", loc, "operation '%s' not found in %s", ct.OperationID, ct.Spec)
+		}
+	} else if _, ok := doc.FindOperationByPath(ep.Method, ep.Path); !ok {
+		v.errorf("unknown_operation", loc, "%s %s not found in %s", ep.Method, ep.Path, ct.Spec)
+	}
+}
+
+// validateOpenAPIExample checks that response_from_openapi names an
+// operation and example documented in its spec
+func (v *validator) validateOpenAPIExample(loc string, resp ResponseConfig) {
+	ex := resp.FromOpenAPI
+	if ex == nil {
+		return
+	}
+	if resp.ResponseFile != "" {
+		v.warnf("ignored_setting", loc, "response_file and response_from_openapi are both set, response_file is used")
+	}
+	loc += ".response_from_openapi"
+	doc, ok := v.loadSpec(loc, ex.Spec)
+	if !ok {
+		return
+	}
+	op, ok := doc.FindOperation(ex.OperationID)
+	if !ok {
+		v.errorf("unknown_operation", loc, "operation '%s' not found in %s", ex.OperationID, ex.Spec)
+		return
+	}
+	if ex.Example != "" {
+		if _, ok := op.ResponseWithExample(ex.Example); !ok {
+			v.errorf("unknown_example", loc, "example '%s' not found in operation '%s'", ex.Example, ex.OperationID)
+		}
+	}
+}
+
+func (v *validator) loadSpec(loc, path string) (*openapi.Document, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.errorf("file_not_found", loc, "openapi spec not found: %s", path)
+		return nil, false
+	}
+	doc, err := openapi.Parse(data)
+	if err != nil {
+		v.errorf("invalid_spec", loc, "invalid openapi spec: %v", err)
+		return nil, false
+	}
+	return doc, true
+}
+
+var endpointLocation = regexp.MustCompile(`^endpoint\[(\d+)\]\.?`)
+
+// locate fills in the file and position of an issue from the YAML node its
+// location refers to, or the closest enclosing node that exists
+func locate(cfg *Config, issue *Issue) {
+	src := cfg.src
+	path := issue.Location
+	if m := endpointLocation.FindStringSubmatch(path); m != nil {
+		i, _ := strconv.Atoi(m[1])
+		if i >= len(cfg.Endpoints) {
+			return
+		}
+		src = cfg.Endpoints[i].src
+		path = path[len(m[0]):]
+	}
+	if src == nil || src.node == nil {
+		return
+	}
+
+	node := src.node
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if path != "" && src == cfg.src && mappingValue(node, strings.SplitN(path, ".", 2)[0]) == nil {
+		// server settings are reported without the "server." prefix
+		if server := mappingValue(node, "server"); server != nil {
+			node = server
+		}
+	}
+	pos := node
+	if path != "" {
+		pos = lookupNode(node, path)
+	}
+	issue.File = src.file
+	issue.Line = pos.Line
+	issue.Column = pos.Column
+}
+
+var locationSegment = regexp.MustCompile(`^([^\[]+)(?:\[([^\]]+)\])?$`)
+
+// segmentKeys maps the singular location names used in issues to YAML keys
+var segmentKeys = map[string][]string{
+	"selector":         {"selectors"},
+	"rule":             {"rules"},
+	"condition":        {"conditions"},
+	"random_responses": {"random_responses", "files"},
+}
+
+// lookupNode follows a location such as "rule[1].condition[0]" below node
+// and returns the deepest node found
+func lookupNode(node *yaml.Node, location string) *yaml.Node {
+	pos := node
+	for _, segment := range strings.Split(location, ".") {
+		m := locationSegment.FindStringSubmatch(segment)
+		if m == nil {
+			return pos
+		}
+		keys, ok := segmentKeys[m[1]]
+		if !ok {
+			keys = []string{m[1]}
+		}
+		for _, key := range keys {
+			keyNode, value := mappingEntry(node, key)
+			if value == nil {
+				return pos
+			}
+			node, pos = value, keyNode
+		}
+		if m[2] == "" {
+			continue
+		}
+		switch node.Kind {
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(m[2])
+			if err != nil || i >= len(node.Content) {
+				return pos
+			}
+			node, pos = node.Content[i], node.Content[i]
+		case yaml.MappingNode:
+			keyNode, value := mappingEntry(node, m[2])
+			if value == nil {
+				return pos
+			}
+			node, pos = value, keyNode
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
+
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidate_ReportsSourcePositions(t *testing.T) {
+	tempDir := t.TempDir()
+	mainConfig := `server:
+  error_handling:
+    custom_error_responses:
+      404: "./missing-404.json"
+state:
+  backend: redis
+  persist_file: state.json
+endpoints:
+  config_paths:
+    - "./orders.yaml"
+`
+	orders := `paths:
+  - path: "/orders"
+    method: "GET"
+  - path: "/orders/{id}"
+    method: "POST"
+    selectors:
+      - name: "id"
+        type: "path"
+        key: "id"
+    rules:
+      - conditions:
+          - selector: "id"
+            match_type: "exact"
+            value: "1"
+          - selector: "nope"
+            match_type: "exact"
+            value: "2"
+`
+	mainPath := filepath.Join(tempDir, "config.yaml")
+	ordersPath := filepath.Join(tempDir, "orders.yaml")
+	if err := os.WriteFile(mainPath, []byte(mainConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ordersPath, []byte(orders), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(mainPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	byCode := make(map[string]Issue)
+	for _, issue := range Validate(cfg) {
+		byCode[issue.Code] = issue
+	}
+
+	tests := []struct {
+		code     string
+		severity string
+		location string
+		file     string
+		line     int
+		column   int
+	}{
+		{"unknown_selector", SeverityError, "endpoint[1].rule[0].condition[1]", ordersPath, 15, 13},
+		{"file_not_found", SeverityError, "error_handling.custom_error_responses[404]", mainPath, 4, 7},
+		{"ignored_setting", SeverityWarning, "state.persist_file", mainPath, 7, 3},
+	}
+	for _, tt := range tests {
+		issue, ok := byCode[tt.code]
+		if !ok {
+			t.Errorf("no %s issue in %v", tt.code, byCode)
+			continue
+		}
+		if issue.Severity != tt.severity || issue.Location != tt.location {
+			t.Errorf("%s: severity %q location %q, want %q %q", tt.code, issue.Severity, issue.Location, tt.severity, tt.location)
+		}
+		if issue.File != tt.file || issue.Line != tt.line || issue.Column != tt.column {
+			t.Errorf("%s: position %s:%d:%d, want %s:%d:%d", tt.code, issue.File, issue.Line, issue.Column, tt.file, tt.line, tt.column)
+		}
+	}
+}
+
+func TestValidate_WithoutSource(t *testing.T) {
+	cfg := &Config{Endpoints: []Endpoint{{Path: "/x", Mode: "bogus"}}}
+
+	issues := Validate(cfg)
+	if len(issues) != 1 {
+		t.Fatalf("expected one issue, got %v", issues)
+	}
+	issue := issues[0]
+	if issue.Code != "invalid_mode" || issue.Line != 0 || issue.File != "" {
+		t.Errorf("unexpected issue %+v", issue)
+	}
+	if got, want := issue.String(), "endpoint[0]: invalid mode 'bogus'"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestValidate_NegativeTimeouts(t *testing.T) {
+	cfg := &Config{Server: ServerConfig{Timeouts: Timeouts{ReadHeaderMs: 500, IdleMs: -1}}}
+
+	issues := Validate(cfg)
+	if len(issues) != 1 || issues[0].Code != "invalid_value" || issues[0].Location != "server.timeouts" {
+		t.Fatalf("expected one invalid_value issue for server.timeouts, got %v", issues)
+	}
+
+	cfg.Server.Timeouts.IdleMs = 0
+	if issues := Validate(cfg); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	issue := Issue{Location: "endpoint[0]", File: "a.yaml", Line: 3, Column: 5, Message: "path is empty"}
+	if got, want := issue.String(), "a.yaml:3:5: endpoint[0]: path is empty"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	issue.File = ""
+	if got, want := issue.String(), "3:5: endpoint[0]: path is empty"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestValidate_LargeResponseFiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "big.json")
+	if err := os.WriteFile(file, []byte(`{"items":[1,2,3]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Server:    ServerConfig{MaxResponseFileBytes: 8},
+		Endpoints: []Endpoint{{Path: "/x", Method: "GET", Default: ResponseConfig{ResponseFile: file}}},
+	}
+
+	for _, strict := range []bool{false, true} {
+		cfg.Server.StrictResponseFileSize = strict
+		issues := Validate(cfg)
+		want := SeverityWarning
+		if strict {
+			want = SeverityError
+		}
+		if len(issues) != 1 || issues[0].Code != "file_too_large" || issues[0].Severity != want {
+			t.Errorf("strict=%v: expected one %s file_too_large issue, got %v", strict, want, issues)
+		}
+	}
+}
+
+func TestValidate_WebSocket(t *testing.T) {
+	cfg := &Config{Endpoints: []Endpoint{{
+		Path: "/ws", Method: "POST", Mode: EndpointModeWebSocket,
+		Idempotency: &Idempotency{},
+		WebSocket: &WSConfig{
+			Messages: []WSMessage{{MatchType: "regex", Value: "(", Reply: []WSFrame{{File: "missing.json"}}}},
+			Push:     []WSPush{{IntervalMs: 0, WSFrame: WSFrame{Body: "tick"}}},
+		},
+	}}}
+
+	got := make(map[string]string)
+	for _, issue := range Validate(cfg) {
+		got[issue.Location] = issue.Code
+	}
+	want := map[string]string{
+		"endpoint[0]":                                "invalid_value",
+		"endpoint[0].idempotency":                    "ignored_setting",
+		"endpoint[0].websocket.messages[0]":          "invalid_regex",
+		"endpoint[0].websocket.messages[0].reply[0]": "file_not_found",
+		"endpoint[0].websocket.push[0]":              "invalid_value",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("issues = %v, want %v", got, want)
+	}
+	for loc, code := range want {
+		if got[loc] != code {
+			t.Errorf("%s: code = %q, want %q", loc, got[loc], code)
+		}
+	}
+}
+
+func TestValidate_ExecResponders(t *testing.T) {
+	cfg := &Config{Endpoints: []Endpoint{{
+		Path: "/quote", Method: "ANY",
+		Default:        ResponseConfig{Responder: &ResponderConfig{Command: []string{"./quote.sh"}}},
+		MethodDefaults: map[string]ResponseConfig{"POST": {Responder: &ResponderConfig{Command: []string{"./create.sh"}}}},
+	}}}
+
+	var locs []string
+	for _, issue := range Validate(cfg) {
+		if issue.Code == "exec_disabled" {
+			locs = append(locs, issue.Location)
+		}
+	}
+	if len(locs) != 2 {
+		t.Fatalf("exec_disabled issues at %v, want default and method_defaults[POST]", locs)
+	}
+
+	cfg.Server.AllowExecResponders = true
+	if issues := Validate(cfg); len(issues) != 0 {
+		t.Errorf("expected no issues with allow_exec_responders, got %v", issues)
+	}
+}
+
+func TestValidate_RecorderExclusions(t *testing.T) {
+	cfg := &Config{Recorder: RecorderConfig{Exclude: []RecorderExclusion{
+		{Path: "/internal/**", Methods: []string{"GET"}},
+		{Regex: "("},
+		{Path: "/a", Regex: "^/a$"},
+		{Methods: []string{"POST"}},
+	}}}
+
+	var locs []string
+	for _, issue := range Validate(cfg) {
+		locs = append(locs, issue.Location)
+	}
+	want := []string{"recorder.exclude[1]", "recorder.exclude[2]", "recorder.exclude[3]"}
+	if !reflect.DeepEqual(locs, want) {
+		t.Errorf("issue locations = %v, want %v", locs, want)
+	}
+}
+
+func TestValidate_ShadowedEndpoints(t *testing.T) {
+	cfg := &Config{Endpoints: []Endpoint{
+		{Path: "/users/:id", Method: "GET"},
+		{Path: "/users/:uid/", Method: "get"},
+		{Path: "/users/:id", Method: "PUT"},
+		{Path: "/users/:name", Method: MethodAny},
+		{Path: "/users", Mode: EndpointModeCRUD},
+		{Path: "/users", Mode: EndpointModeSink},
+		{Path: "/users/me", Method: "GET"},
+	}}
+
+	var got []string
+	for _, issue := range Validate(cfg) {
+		if issue.Code == "shadowed_endpoint" {
+			got = append(got, issue.Location+": "+issue.Message)
+		}
+	}
+	want := []string{
+		"endpoint[1]: never served: endpoint[0] has the same method and path",
+		"endpoint[5]: never served: endpoint[4] has the same method and path",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shadowed endpoints = %v, want %v", got, want)
+	}
+}
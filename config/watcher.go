@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -16,6 +17,7 @@ type Watcher struct {
 	mu         sync.RWMutex
 	stopCh     chan struct{}
 	logger     *log.Logger
+	onReload   func(cfg *Config)
 }
 
 // NewWatcher creates a new config watcher
@@ -28,6 +30,11 @@ func NewWatcher(configPath string, manager *ConfigManager, logger *log.Logger) *
 	}
 }
 
+// OnReload registers a callback invoked after each successful reload
+func (w *Watcher) OnReload(fn func(cfg *Config)) {
+	w.onReload = fn
+}
+
 // Start starts watching the config file for changes
 func (w *Watcher) Start(intervalSec int) {
 	go w.watchWithFsnotify()
@@ -38,7 +45,14 @@ func (w *Watcher) Stop() {
 	close(w.stopCh)
 }
 
-// watchWithFsnotify uses fsnotify to watch for file changes
+// defaultReloadDebounce is the quiet time after the last change before a
+// reload when server.reload_debounce_ms is not set
+const defaultReloadDebounce = 500 * time.Millisecond
+
+// watchWithFsnotify uses fsnotify to watch for file changes. The directories
+// holding the config files are watched rather than the files themselves, so
+// editors that save by renaming and git checkouts that replace files are
+// picked up too.
 func (w *Watcher) watchWithFsnotify() {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -58,9 +72,11 @@ func (w *Watcher) watchWithFsnotify() {
 
 	w.logger.Printf("[INFO] Started watching config file: %s", w.configPath)
 
-	// Debounce timer to avoid rapid reloads
-	var debounceTimer *time.Timer
-	debounceDuration := 500 * time.Millisecond
+	// One debounce timer covers all watched files, so a burst of saves
+	// across several files results in a single reload
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer debounce.Stop()
 
 	for {
 		select {
@@ -72,16 +88,17 @@ func (w *Watcher) watchWithFsnotify() {
 			if !ok {
 				return
 			}
+			if _, watched := watchedPaths[filepath.Clean(event.Name)]; !watched {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				debounce.Reset(w.debounceDuration())
+			}
 
-			// Only reload on write or create events
-			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
-				// Debounce: cancel previous timer and set a new one
-				if debounceTimer != nil {
-					debounceTimer.Stop()
-				}
-				debounceTimer = time.AfterFunc(debounceDuration, func() {
-					w.reloadConfig(watcher, watchedPaths)
-				})
+		case <-debounce.C:
+			if !w.reloadConfig(watcher, watchedPaths) {
+				// Files were still changing, try again once they settle
+				debounce.Reset(w.debounceDuration())
 			}
 
 		case err, ok := <-watcher.Errors:
@@ -93,6 +110,13 @@ func (w *Watcher) watchWithFsnotify() {
 	}
 }
 
+func (w *Watcher) debounceDuration() time.Duration {
+	if cfg := w.manager.GetConfig(); cfg != nil && cfg.Server.ReloadDebounceMs > 0 {
+		return time.Duration(cfg.Server.ReloadDebounceMs) * time.Millisecond
+	}
+	return defaultReloadDebounce
+}
+
 // watchWithPolling polls for file changes at regular intervals
 func (w *Watcher) watchWithPolling(intervalSec int) {
 	ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
@@ -111,15 +135,27 @@ func (w *Watcher) watchWithPolling(intervalSec int) {
 	}
 }
 
-// reloadConfig reloads the configuration from file
-func (w *Watcher) reloadConfig(watcher *fsnotify.Watcher, watchedPaths map[string]struct{}) {
+// reloadConfig reloads the configuration from file. The new config is only
+// applied when the main file and every endpoint file parse and none of them
+// changed while they were read; it returns false in the latter case so the
+// caller can retry once the files settle.
+func (w *Watcher) reloadConfig(watcher *fsnotify.Watcher, watchedPaths map[string]struct{}) bool {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	files := []string{w.configPath}
+	if current := w.manager.GetBaseConfig(); current != nil {
+		files = append(files, current.EndpointConfigPaths...)
+	}
+	before := statFiles(files)
 	newCfg, err := LoadConfig(w.configPath)
 	if err != nil {
 		w.logger.Printf("[ERROR] Failed to reload config: %v (keeping old config)", err)
-		return
+		return true
+	}
+	if path, changed := changedFile(before, statFiles(files)); changed {
+		w.logger.Printf("[WARN] Config file %s changed during reload, retrying (keeping old config)", path)
+		return false
 	}
 
 	// Validate new config
@@ -132,6 +168,42 @@ func (w *Watcher) reloadConfig(watcher *fsnotify.Watcher, watchedPaths map[strin
 	w.manager.SetConfig(newCfg)
 	w.watchEndpointConfigFiles(watcher, watchedPaths, newCfg)
 	w.logger.Printf("[INFO] Configuration reloaded successfully at %s", time.Now().Format(time.RFC3339))
+
+	if w.onReload != nil {
+		w.onReload(newCfg)
+	}
+	return true
+}
+
+// fileStamp identifies one version of a file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// statFiles returns the current stamp of each path; missing files get the
+// zero stamp
+func statFiles(paths []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		} else {
+			stamps[path] = fileStamp{}
+		}
+	}
+	return stamps
+}
+
+// changedFile returns a file whose stamp differs between before and after,
+// meaning it was written while the config was being read
+func changedFile(before, after map[string]fileStamp) (string, bool) {
+	for path, stamp := range before {
+		if after[path] != stamp {
+			return path, true
+		}
+	}
+	return "", false
 }
 
 func (w *Watcher) watchEndpointConfigFiles(watcher *fsnotify.Watcher, watchedPaths map[string]struct{}, cfg *Config) {
@@ -146,12 +218,14 @@ func (w *Watcher) watchEndpointConfigFiles(watcher *fsnotify.Watcher, watchedPat
 	}
 }
 
+// addWatchPath watches the directory of path and records path as one of
+// the files whose events trigger a reload
 func (w *Watcher) addWatchPath(watcher *fsnotify.Watcher, watchedPaths map[string]struct{}, path string) error {
 	cleanPath := filepath.Clean(path)
 	if _, exists := watchedPaths[cleanPath]; exists {
 		return nil
 	}
-	if err := watcher.Add(cleanPath); err != nil {
+	if err := watcher.Add(filepath.Dir(cleanPath)); err != nil {
 		return err
 	}
 	watchedPaths[cleanPath] = struct{}{}
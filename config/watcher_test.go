@@ -0,0 +1,59 @@
+package config
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ReloadsOnceAfterRenamedSaves(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config.yaml")
+	writeFile := func(name, content string) {
+		// Save through a rename, as editors and git checkout do
+		tmp := filepath.Join(dir, "."+name+".tmp")
+		if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("config.yaml", "server:\n  reload_debounce_ms: 100\nendpoints:\n  config_paths:\n    - ./a.yaml\n    - ./b.yaml\n")
+	writeFile("a.yaml", "path: /a\nmethod: GET\n")
+	writeFile("b.yaml", "path: /b\nmethod: GET\n")
+
+	cfg, err := LoadConfig(mainPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	cm := NewConfigManager(mainPath)
+	cm.SetConfig(cfg)
+
+	reloads := make(chan *Config, 10)
+	w := NewWatcher(mainPath, cm, log.New(io.Discard, "", 0))
+	w.OnReload(func(cfg *Config) { reloads <- cfg })
+	w.Start(0)
+	defer w.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	writeFile("a.yaml", "path: /a2\nmethod: GET\n")
+	writeFile("b.yaml", "path: /b2\nmethod: GET\n")
+
+	select {
+	case got := <-reloads:
+		if len(got.Endpoints) != 2 || got.Endpoints[0].Path != "/a2" || got.Endpoints[1].Path != "/b2" {
+			t.Fatalf("expected both files reloaded together, got %+v", got.Endpoints)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected a reload")
+	}
+	select {
+	case got := <-reloads:
+		t.Fatalf("expected a single debounced reload, got another with %d endpoints", len(got.Endpoints))
+	case <-time.After(300 * time.Millisecond):
+	}
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/pkg/lru"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkChallenge enforces the endpoint's authentication challenge and
+// reports whether the request may proceed. Rejected requests get 401 with a
+// WWW-Authenticate header for the configured scheme.
+func (h *MockHandler) checkChallenge(c *gin.Context, cfg *config.Config, ep *config.Endpoint) bool {
+	ch := ep.Challenge
+	if ch == nil {
+		return true
+	}
+
+	auth := c.GetHeader("Authorization")
+	message := "Missing credentials"
+	if auth != "" {
+		message = "Invalid credentials"
+		if validChallengeCredentials(ch, c.Request.Method, auth) {
+			if ch.FailAttempts <= 0 || h.challengeAttempt(ep, auth) > int64(ch.FailAttempts) {
+				return true
+			}
+			message = "Credentials rejected, retry"
+		}
+	}
+
+	c.Header("WWW-Authenticate", challengeHeader(ch))
+	middleware.AbortAuth(c, cfg, ch.Unauthorized, http.StatusUnauthorized, "UNAUTHORIZED", message)
+	return false
+}
+
+// challengeAttempt counts the requests of one Authorization value to an
+// endpoint. Only a digest of the value is kept.
+func (h *MockHandler) challengeAttempt(ep *config.Endpoint, auth string) int64 {
+	sum := sha256.Sum256([]byte(auth))
+	return h.challenges.increment(ep.ID+":"+hex.EncodeToString(sum[:8]), time.Now())
+}
+
+// ResetChallenges forgets the attempts counted for fail_attempts
+func (h *MockHandler) ResetChallenges() {
+	h.challenges.reset()
+}
+
+// challengeAttemptTTL is how long an Authorization value is remembered after
+// its last request; maxChallengeAttempts caps how many are remembered
+const (
+	challengeAttemptTTL  = time.Hour
+	maxChallengeAttempts = 10000
+)
+
+// challengeAttempts counts requests per endpoint and Authorization value,
+// apart from the named counters so they neither show in /admin/counters nor
+// grow with every distinct credential a client sends
+type challengeAttempts struct {
+	mu      sync.Mutex
+	entries *lru.Cache[int64]
+}
+
+func newChallengeAttempts() *challengeAttempts {
+	return &challengeAttempts{entries: lru.New[int64](maxChallengeAttempts)}
+}
+
+func (a *challengeAttempts) increment(key string, now time.Time) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	count, _ := a.entries.Get(key, now)
+	count++
+	a.entries.Put(key, count, now.Add(challengeAttemptTTL))
+	return count
+}
+
+func (a *challengeAttempts) reset() {
+	a.mu.Lock()
+	a.entries.Reset()
+	a.mu.Unlock()
+}
+
+// challengeHeader builds the WWW-Authenticate value for the challenge
+func challengeHeader(ch *config.AuthChallenge) string {
+	realm := ch.Realm
+	if realm == "" {
+		realm = "mock"
+	}
+	switch strings.ToLower(ch.Scheme) {
+	case config.ChallengeBearer:
+		return fmt.Sprintf(`Bearer realm=%q`, realm)
+	case config.ChallengeDigest:
+		return fmt.Sprintf(`Digest realm=%q, qop="auth", algorithm=MD5, nonce=%q, opaque=%q`, realm, randomHex(16), randomHex(8))
+	default:
+		return fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, realm)
+	}
+}
+
+// validChallengeCredentials reports whether auth carries acceptable
+// credentials of the challenge's scheme
+func validChallengeCredentials(ch *config.AuthChallenge, method, auth string) bool {
+	scheme, value, _ := strings.Cut(auth, " ")
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(ch.Scheme) {
+	case config.ChallengeBearer:
+		if !strings.EqualFold(scheme, "bearer") || value == "" {
+			return false
+		}
+		return len(ch.Tokens) == 0 || containsString(ch.Tokens, value)
+	case config.ChallengeDigest:
+		return strings.EqualFold(scheme, "digest") && validDigest(ch, method, value)
+	default:
+		if !strings.EqualFold(scheme, "basic") {
+			return false
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return false
+		}
+		user, password, ok := strings.Cut(string(decoded), ":")
+		if !ok || user == "" {
+			return false
+		}
+		if len(ch.Users) == 0 {
+			return true
+		}
+		expected, known := ch.Users[user]
+		return known && expected == password
+	}
+}
+
+// validDigest checks an RFC 2617 digest response (MD5, with or without
+// qop=auth) against the configured users. Nonces are not tracked, so any
+// nonce the client echoes is accepted.
+func validDigest(ch *config.AuthChallenge, method, value string) bool {
+	params := parseAuthParams(value)
+	user := params["username"]
+	if user == "" || params["response"] == "" {
+		return false
+	}
+	if len(ch.Users) == 0 {
+		return true
+	}
+	password, known := ch.Users[user]
+	if !known {
+		return false
+	}
+
+	ha1 := md5Hex(user + ":" + params["realm"] + ":" + password)
+	ha2 := md5Hex(method + ":" + params["uri"])
+	expected := md5Hex(ha1 + ":" + params["nonce"] + ":" + ha2)
+	if params["qop"] != "" {
+		expected = md5Hex(strings.Join([]string{ha1, params["nonce"], params["nc"], params["cnonce"], params["qop"], ha2}, ":"))
+	}
+	return expected == params["response"]
+}
+
+// parseAuthParams splits comma separated key=value or key="value" pairs
+func parseAuthParams(value string) map[string]string {
+	params := make(map[string]string)
+	for len(value) > 0 {
+		key, rest, ok := strings.Cut(value, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+		var v string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			v, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			v, rest, _ = strings.Cut(rest, ",")
+			rest = "," + rest
+		}
+		params[key] = strings.TrimSpace(v)
+		_, value, _ = strings.Cut(rest, ",")
+	}
+	return params
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleRequestAuthChallenge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{
+			ID: "basic", Path: "/basic", Method: "GET",
+			Challenge: &config.AuthChallenge{Realm: "api", Users: map[string]string{"alice": "secret"}, FailAttempts: 1},
+		},
+		{
+			ID: "bearer", Path: "/bearer", Method: "GET",
+			Challenge: &config.AuthChallenge{Scheme: "bearer", Tokens: []string{"t1"}},
+		},
+	}})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	call := func(path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	basic := func(user, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	}
+
+	w := call("/basic", "")
+	if w.Code != http.StatusUnauthorized || !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), `Basic realm="api"`) {
+		t.Fatalf("expected a Basic challenge, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := call("/basic", basic("alice", "wrong")); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected wrong password to be rejected, got %d", w.Code)
+	}
+	// fail_attempts: 1 rejects the first valid attempt, the retry succeeds
+	if w := call("/basic", basic("alice", "secret")); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the first valid attempt to be rejected, got %d", w.Code)
+	}
+	if w := call("/basic", basic("alice", "secret")); w.Code != http.StatusOK {
+		t.Errorf("expected the retry to succeed, got %d", w.Code)
+	}
+
+	if w := call("/bearer", "Bearer nope"); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Bearer realm="mock"` {
+		t.Errorf("expected a Bearer challenge, got %d %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if w := call("/bearer", "Bearer t1"); w.Code != http.StatusOK {
+		t.Errorf("expected accepted token, got %d", w.Code)
+	}
+}
+
+func TestValidDigest(t *testing.T) {
+	ch := &config.AuthChallenge{Scheme: "digest", Users: map[string]string{"Mufasa": "Circle Of Life"}}
+	// Example from RFC 2617 section 3.5
+	header := `username="Mufasa", realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", ` +
+		`uri="/dir/index.html", qop=auth, nc=00000001, cnonce="0a4f113b", ` +
+		`response="6629fae49393a05397450978507c4ef1", opaque="5ccc069c403ebaf9f0171e9517f40e41"`
+
+	if !validDigest(ch, http.MethodGet, header) {
+		t.Error("expected the RFC example to verify")
+	}
+	if validDigest(ch, http.MethodPost, header) {
+		t.Error("expected a different method to fail")
+	}
+	if got := challengeHeader(ch); !strings.HasPrefix(got, `Digest realm="mock", qop="auth"`) {
+		t.Errorf("unexpected challenge %q", got)
+	}
+}
+
+func TestChallengeAttempts(t *testing.T) {
+	a := newChallengeAttempts()
+	now := time.Now()
+
+	if n := a.increment("ep:a", now); n != 1 {
+		t.Errorf("first attempt = %d, want 1", n)
+	}
+	if n := a.increment("ep:a", now.Add(time.Minute)); n != 2 {
+		t.Errorf("second attempt = %d, want 2", n)
+	}
+	if n := a.increment("ep:a", now.Add(time.Minute+challengeAttemptTTL+time.Second)); n != 1 {
+		t.Errorf("attempt after the ttl = %d, want 1", n)
+	}
+
+	for i := 0; i < maxChallengeAttempts+10; i++ {
+		a.increment(strconv.Itoa(i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if a.entries.Len() > maxChallengeAttempts {
+		t.Errorf("kept %d entries, want at most %d", a.entries.Len(), maxChallengeAttempts)
+	}
+
+	a.reset()
+	if a.entries.Len() != 0 {
+		t.Errorf("kept %d entries after reset", a.entries.Len())
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"mock-api-server/config"
+)
+
+// compiledEndpoint is the request-independent part of matching an endpoint,
+// converted once per config instead of on every request
+type compiledEndpoint struct {
+	selectors []Selector
+	rules     []Rule
+	ruleNames []string // rule_<i>, for logging
+	needsBody bool     // whether matching or responding reads the request body
+	wsRules   []wsRule // message rules of a websocket endpoint
+}
+
+// compiledConfig holds the compiled endpoints of one config. Endpoints are
+// keyed by their address in cfg.Endpoints, which is stable for a config.
+type compiledConfig struct {
+	cfg       *config.Config
+	endpoints map[*config.Endpoint]*compiledEndpoint
+}
+
+// endpointCache compiles the endpoints of the current config, recompiling
+// when the config is replaced
+type endpointCache struct {
+	current atomic.Pointer[compiledConfig]
+}
+
+// config returns the compiled form of cfg, compiling it when it replaced
+// the previous config
+func (ec *endpointCache) config(cfg *config.Config) *compiledConfig {
+	cc := ec.current.Load()
+	if cc == nil || cc.cfg != cfg {
+		cc = compileConfig(cfg)
+		ec.current.Store(cc)
+	}
+	return cc
+}
+
+// store compiles cfg and makes it the current config
+func (ec *endpointCache) store(cfg *config.Config) {
+	ec.current.Store(compileConfig(cfg))
+}
+
+// get returns the compiled form of ep, an endpoint of cfg
+func (ec *endpointCache) get(cfg *config.Config, ep *config.Endpoint) *compiledEndpoint {
+	if compiled, ok := ec.config(cfg).endpoints[ep]; ok {
+		return compiled
+	}
+	// Not an endpoint of cfg, e.g. one built by a test
+	return compileEndpoint(cfg, ep)
+}
+
+func compileConfig(cfg *config.Config) *compiledConfig {
+	cc := &compiledConfig{
+		cfg:       cfg,
+		endpoints: make(map[*config.Endpoint]*compiledEndpoint, len(cfg.Endpoints)),
+	}
+	for i := range cfg.Endpoints {
+		cc.endpoints[&cfg.Endpoints[i]] = compileEndpoint(cfg, &cfg.Endpoints[i])
+	}
+	return cc
+}
+
+func compileEndpoint(cfg *config.Config, ep *config.Endpoint) *compiledEndpoint {
+	compiled := &compiledEndpoint{
+		selectors: toSelectors(ep),
+		rules:     toRules(ep),
+		ruleNames: make([]string, len(ep.Rules)),
+		needsBody: needsBody(cfg, ep),
+	}
+	for i := range compiled.ruleNames {
+		compiled.ruleNames[i] = fmt.Sprintf("rule_%d", i)
+	}
+	for i := range compiled.rules {
+		for j := range compiled.rules[i].Conditions {
+			compileCondition(&compiled.rules[i].Conditions[j])
+		}
+	}
+	if ep.Mode == config.EndpointModeWebSocket && ep.WebSocket != nil {
+		compiled.wsRules = toWSRules(ep.WebSocket.Messages)
+	}
+	return compiled
+}
+
+// compileCondition prepares the pattern or expected document of cond
+func compileCondition(cond *Condition) {
+	switch strings.ToLower(cond.MatchType) {
+	case "regex":
+		// Invalid patterns never match, as in matchCondition
+		cond.regex, _ = regexp.Compile(conditionPattern(*cond))
+	case "json_equals":
+		// Unreadable files are retried, and never match, per request
+		cond.expected, _ = loadExpectedJSON(cond.Value)
+	}
+}
+
+// needsBody reports whether serving ep reads the request body: for body
+// selectors, a body partition key, or scripts and responders, which are
+// handed the body. Other requests are not buffered.
+func needsBody(cfg *config.Config, ep *config.Endpoint) bool {
+	for _, sel := range ep.Selectors {
+		if strings.EqualFold(sel.Type, "body") {
+			return true
+		}
+	}
+	if ep.Scenario != "" && ep.PartitionSelector == "" {
+		if pk := cfg.Scenarios[ep.Scenario].PartitionKey; pk != nil && strings.EqualFold(pk.Type, "body") {
+			return true
+		}
+	}
+	handsBody := func(r config.ResponseConfig) bool {
+		return r.Script != nil || r.Responder != nil
+	}
+	if handsBody(ep.Default) {
+		return true
+	}
+	for _, r := range ep.Rules {
+		if handsBody(r.ResponseConfig) {
+			return true
+		}
+	}
+	for _, r := range ep.MethodDefaults {
+		if handsBody(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleName returns the logged name of a matched rule
+func (ce *compiledEndpoint) ruleName(rule *Rule) string {
+	if i := getRuleIndex(ce.rules, rule); i >= 0 {
+		return ce.ruleNames[i]
+	}
+	return "default"
+}
+
+// maxPooledBody keeps unusually large request bodies from pinning memory
+const maxPooledBody = 1 << 20
+
+var bodyPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// readBody reads r into a pooled buffer. The returned bytes are only valid
+// until release is called.
+func readBody(r io.Reader) ([]byte, func(), error) {
+	buf := bodyPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	release := func() {
+		if buf.Cap() <= maxPooledBody {
+			bodyPool.Put(buf)
+		}
+	}
+	if r == nil {
+		return buf.Bytes(), release, nil
+	}
+	_, err := buf.ReadFrom(r)
+	return buf.Bytes(), release, err
+}
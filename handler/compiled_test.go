@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEndpointCacheRecompilesOnConfigChange(t *testing.T) {
+	newConfig := func(pattern string) *config.Config {
+		return &config.Config{Endpoints: []config.Endpoint{{
+			Path:      "/users",
+			Method:    "GET",
+			Selectors: []config.Selector{{Name: "name", Type: "query", Key: "name"}},
+			Rules: []config.Rule{{
+				Conditions: []config.Condition{{Selector: "name", MatchType: "regex", Value: pattern}},
+			}},
+		}}}
+	}
+
+	var cache endpointCache
+	first := newConfig("^a")
+	compiled := cache.get(first, &first.Endpoints[0])
+	if cache.get(first, &first.Endpoints[0]) != compiled {
+		t.Fatalf("expected the compiled endpoint to be reused for the same config")
+	}
+	if cond := compiled.rules[0].Conditions[0]; cond.regex == nil || !matchCondition("alice", cond) {
+		t.Fatalf("expected a precompiled regex matching alice")
+	}
+
+	second := newConfig("^b")
+	recompiled := cache.get(second, &second.Endpoints[0])
+	if recompiled == compiled || matchCondition("alice", recompiled.rules[0].Conditions[0]) {
+		t.Fatalf("expected the endpoint to be recompiled for a new config")
+	}
+	if name := recompiled.ruleName(&recompiled.rules[0]); name != "rule_0" {
+		t.Errorf("ruleName = %q", name)
+	}
+}
+
+func TestHandleRequestMatchesBodySelector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{
+		ID:        "orders",
+		Path:      "/orders",
+		Method:    "POST",
+		Selectors: []config.Selector{{Name: "type", Type: "body", Key: "type"}},
+		Rules: []config.Rule{{
+			Conditions:     []config.Condition{{Selector: "type", MatchType: "regex", Value: "^vip"}},
+			ResponseConfig: config.ResponseConfig{StatusCode: http.StatusAccepted},
+		}},
+		Default: config.ResponseConfig{StatusCode: http.StatusCreated},
+	}}})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	for _, tt := range []struct {
+		body string
+		want int
+	}{
+		{`{"type":"vip_gold"}`, http.StatusAccepted},
+		{`{"type":"standard"}`, http.StatusCreated},
+		{``, http.StatusCreated},
+		{`{"type":"vip"}`, http.StatusAccepted},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body)))
+		if w.Code != tt.want {
+			t.Errorf("body %q: status %d, want %d", tt.body, w.Code, tt.want)
+		}
+	}
+}
+
+// trackingReader records whether the request body was read
+type trackingReader struct {
+	r    *strings.Reader
+	read bool
+}
+
+func (t *trackingReader) Read(p []byte) (int, error) {
+	t.read = true
+	return t.r.Read(p)
+}
+
+func TestHandleRequestSkipsUnneededBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "upload", Path: "/upload", Method: "POST", Default: config.ResponseConfig{StatusCode: http.StatusCreated}},
+		{
+			ID:        "search",
+			Path:      "/search",
+			Method:    "POST",
+			Selectors: []config.Selector{{Name: "q", Type: "body", Key: "q"}},
+			Default:   config.ResponseConfig{StatusCode: http.StatusOK},
+		},
+	}})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	for _, tt := range []struct {
+		path     string
+		wantRead bool
+	}{
+		{"/upload", false},
+		{"/search", true},
+	} {
+		body := &trackingReader{r: strings.NewReader(`{"q":"x"}`)}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, body))
+		if body.read != tt.wantRead {
+			t.Errorf("%s: body read = %v, want %v", tt.path, body.read, tt.wantRead)
+		}
+	}
+}
+
+func TestMockHandlerCompilesConfigOnChange(t *testing.T) {
+	cm := config.NewConfigManager("config.yaml")
+	h := NewMockHandler(cm, nil, nil)
+
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{Path: "/users", Method: "GET"}}})
+	if cc := h.compiled.current.Load(); cc == nil || cc.cfg != cm.GetConfig() {
+		t.Fatalf("expected the new config to be compiled when it was set")
+	}
+}
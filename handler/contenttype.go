@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkContentTypes enforces the endpoint's consumes and produces lists. It
+// answers 415 when a request body's Content-Type is not consumed and 406
+// when the Accept header allows none of the produced types, and reports
+// whether the request may proceed.
+func checkContentTypes(c *gin.Context, cfg *config.Config, ep *config.Endpoint) bool {
+	if len(ep.Consumes) > 0 && hasBody(c.Request) {
+		contentType := c.GetHeader("Content-Type")
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !matchesAnyMediaType(mediaType, ep.Consumes) {
+			c.Set("matched_rule", "consumes")
+			middleware.AbortWithError(c, cfg, http.StatusUnsupportedMediaType, gin.H{
+				"code":         "UNSUPPORTED_MEDIA_TYPE",
+				"message":      "The request Content-Type is not supported by this resource",
+				"content_type": contentType,
+				"supported":    ep.Consumes,
+			})
+			return false
+		}
+	}
+
+	if len(ep.Produces) > 0 && !acceptsAny(c.GetHeader("Accept"), ep.Produces) {
+		c.Set("matched_rule", "produces")
+		middleware.AbortWithError(c, cfg, http.StatusNotAcceptable, gin.H{
+			"code":      "NOT_ACCEPTABLE",
+			"message":   "None of the response types of this resource is acceptable",
+			"accept":    c.GetHeader("Accept"),
+			"available": ep.Produces,
+		})
+		return false
+	}
+	return true
+}
+
+// hasBody reports whether the request carries a body
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || (req.ContentLength < 0 && req.Body != nil && req.Body != http.NoBody)
+}
+
+// acceptsAny reports whether an Accept header allows one of types. A missing
+// header accepts everything.
+func acceptsAny(accept string, types []string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight <= 0 {
+				continue
+			}
+		}
+		for _, t := range types {
+			if mediaTypeMatches(mediaRange, t) || mediaTypeMatches(t, mediaRange) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesAnyMediaType reports whether mediaType matches one of patterns
+func matchesAnyMediaType(mediaType string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if mediaTypeMatches(pattern, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaTypeMatches reports whether mediaType falls under pattern, which may
+// be */* or type/*. Parameters of both are ignored.
+func mediaTypeMatches(pattern, mediaType string) bool {
+	if p, _, err := mime.ParseMediaType(pattern); err == nil {
+		pattern = p
+	}
+	if m, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = m
+	}
+	switch {
+	case pattern == "*/*":
+		return true
+	case strings.HasSuffix(pattern, "/*"):
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	default:
+		return pattern == mediaType
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleRequestEnforcesContentTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{
+		ID:       "orders",
+		Path:     "/orders",
+		Method:   "POST",
+		Consumes: []string{"application/json", "text/*"},
+		Produces: []string{"application/json"},
+		Default:  config.ResponseConfig{StatusCode: http.StatusCreated},
+	}}})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		accept      string
+		want        int
+	}{
+		{"json body", `{}`, "application/json; charset=utf-8", "", http.StatusCreated},
+		{"wildcard subtype", `hi`, "text/plain", "", http.StatusCreated},
+		{"unsupported type", `a=1`, "application/x-www-form-urlencoded", "", http.StatusUnsupportedMediaType},
+		{"missing type", `{}`, "", "", http.StatusUnsupportedMediaType},
+		{"no body", ``, "", "", http.StatusCreated},
+		{"acceptable", `{}`, "application/json", "text/html, application/*;q=0.5", http.StatusCreated},
+		{"not acceptable", `{}`, "application/json", "text/html, application/json;q=0", http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
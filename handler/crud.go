@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// crudItemParam is the path parameter holding the item ID of crud endpoints
+const crudItemParam = "crud_id"
+
+// matchCRUDPath matches requestPath against the collection path pattern or
+// its item path (pattern + "/:id")
+func matchCRUDPath(pattern, requestPath string) (map[string]string, bool) {
+	if params, ok := matchPath(pattern, requestPath); ok {
+		return params, true
+	}
+	return matchPath(strings.TrimRight(pattern, "/")+"/:"+crudItemParam, requestPath)
+}
+
+// crudResource returns the resource name and ID field of a crud endpoint
+func crudResource(ep *config.Endpoint) (resource, idField string) {
+	if ep.CRUD != nil {
+		resource, idField = ep.CRUD.Resource, ep.CRUD.IDField
+	}
+	if resource == "" {
+		resource = path.Base(strings.TrimRight(ep.Path, "/"))
+	}
+	if idField == "" {
+		idField = "id"
+	}
+	return resource, idField
+}
+
+// ResetResources empties every crud collection and forgets pagination
+// cursors; seeded resources are re-seeded on their next request
+func (h *MockHandler) ResetResources() {
+	h.resources.ResetAll()
+	h.cursors.ResetAll()
+}
+
+// handleCRUD serves a crud endpoint from the in-memory collection:
+// GET lists or fetches, POST creates, PUT replaces, PATCH merges and
+// DELETE removes
+func (h *MockHandler) handleCRUD(c *gin.Context, cfg *config.Config, ep *config.Endpoint, pathParams map[string]string) {
+	resource, idField := crudResource(ep)
+	c.Set("matched_rule", "crud")
+
+	if err := h.seedResource(ep, resource, idField); err != nil {
+		h.handleError(c, cfg, err)
+		return
+	}
+
+	id, isItem := pathParams[crudItemParam]
+	method := c.Request.Method
+
+	switch {
+	case !isItem && method == http.MethodGet:
+		h.listCRUD(c, cfg, ep, resource, idField)
+
+	case !isItem && method == http.MethodPost:
+		item, ok := h.readCRUDBody(c, cfg)
+		if !ok {
+			return
+		}
+		created, err := h.resources.Create(resource, idField, item)
+		if err != nil {
+			crudError(c, cfg, http.StatusConflict, "CONFLICT", err.Error())
+			return
+		}
+		c.Header("Location", fmt.Sprintf("%s/%v", strings.TrimRight(c.Request.URL.Path, "/"), created[idField]))
+		c.JSON(http.StatusCreated, created)
+
+	case isItem && method == http.MethodGet:
+		if item, ok := h.resources.Get(resource, idField, id); ok {
+			c.JSON(http.StatusOK, item)
+			return
+		}
+		crudNotFound(c, cfg, resource, id)
+
+	case isItem && (method == http.MethodPut || method == http.MethodPatch):
+		item, ok := h.readCRUDBody(c, cfg)
+		if !ok {
+			return
+		}
+		var updated state.Item
+		if method == http.MethodPut {
+			updated, ok = h.resources.Replace(resource, idField, id, item)
+		} else {
+			updated, ok = h.resources.Merge(resource, idField, id, item)
+		}
+		if !ok {
+			crudNotFound(c, cfg, resource, id)
+			return
+		}
+		c.JSON(http.StatusOK, updated)
+
+	case isItem && method == http.MethodDelete:
+		if !h.resources.Delete(resource, idField, id) {
+			crudNotFound(c, cfg, resource, id)
+			return
+		}
+		c.Status(http.StatusNoContent)
+
+	default:
+		crudError(c, cfg, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED",
+			fmt.Sprintf("%s is not supported on %s", method, c.Request.URL.Path))
+	}
+}
+
+// listCRUD returns the collection, one page at a time when the endpoint has
+// a page_size or the request a limit. Pages look like
+// {"items": [...], "next_cursor": "..."}; pass next_cursor as ?cursor= to
+// get the following page. It is null on the last page.
+func (h *MockHandler) listCRUD(c *gin.Context, cfg *config.Config, ep *config.Endpoint, resource, idField string) {
+	items := h.resources.List(resource)
+
+	limit := 0
+	if ep.CRUD != nil {
+		limit = ep.CRUD.PageSize
+	}
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			crudError(c, cfg, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	token := c.Query("cursor")
+	if limit == 0 && token == "" {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
+	start := 0
+	if token != "" {
+		cursor, ok := h.cursors.Lookup(token)
+		if !ok || cursor.Resource != resource {
+			crudError(c, cfg, http.StatusBadRequest, "INVALID_CURSOR", "cursor is unknown or expired")
+			return
+		}
+		start = cursor.Start(items, idField)
+		// Without an explicit limit, pages keep the size of the first one
+		if c.Query("limit") == "" {
+			limit = cursor.Limit
+		}
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	page := items[start:end]
+
+	var next interface{}
+	if end < len(items) {
+		next = h.cursors.Issue(state.Cursor{
+			Resource: resource,
+			AfterID:  fmt.Sprint(page[len(page)-1][idField]),
+			NextID:   fmt.Sprint(items[end][idField]),
+			Offset:   end,
+			Limit:    limit,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": page, "next_cursor": next})
+}
+
+// seedResource loads the endpoint's seed file the first time the resource is used
+func (h *MockHandler) seedResource(ep *config.Endpoint, resource, idField string) error {
+	if ep.CRUD == nil || ep.CRUD.SeedFile == "" || h.resources.Exists(resource) {
+		return nil
+	}
+	data, err := os.ReadFile(ep.CRUD.SeedFile)
+	if err != nil {
+		return fmt.Errorf("failed to read crud seed file: %w", err)
+	}
+	var items []state.Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("crud seed file %s must be a JSON array of objects: %w", ep.CRUD.SeedFile, err)
+	}
+	h.resources.Seed(resource, idField, items)
+	return nil
+}
+
+// readCRUDBody decodes the request body as a JSON object
+func (h *MockHandler) readCRUDBody(c *gin.Context, cfg *config.Config) (state.Item, bool) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			middleware.AbortBodyTooLarge(c, cfg)
+			return nil, false
+		}
+		crudError(c, cfg, http.StatusBadRequest, "INVALID_BODY", "failed to read request body")
+		return nil, false
+	}
+	var item state.Item
+	if err := json.Unmarshal(data, &item); err != nil || item == nil {
+		crudError(c, cfg, http.StatusBadRequest, "INVALID_BODY", "request body must be a JSON object")
+		return nil, false
+	}
+	return item, true
+}
+
+func crudNotFound(c *gin.Context, cfg *config.Config, resource, id string) {
+	crudError(c, cfg, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("%s '%s' not found", resource, id))
+}
+
+func crudError(c *gin.Context, cfg *config.Config, status int, code, message string) {
+	middleware.AbortWithError(c, cfg, status, gin.H{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EndpointSummary identifies an endpoint in debug output
+type EndpointSummary struct {
+	ID          string   `json:"id"`
+	Index       int      `json:"index"`
+	Path        string   `json:"path"`
+	Method      string   `json:"method"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// MatchExplanation describes how a request would be handled, without sending a response
+type MatchExplanation struct {
+	Matched      bool              `json:"matched"`
+	Endpoint     *EndpointSummary  `json:"endpoint,omitempty"`
+	PathParams   map[string]string `json:"path_params,omitempty"`
+	Values       map[string]string `json:"values,omitempty"`
+	Scenario     string            `json:"scenario,omitempty"`
+	Partition    string            `json:"partition,omitempty"`
+	Step         string            `json:"step,omitempty"`
+	Rules        []RuleEvaluation  `json:"rules,omitempty"`
+	MatchedRule  string            `json:"matched_rule,omitempty"`
+	ResponseFile string            `json:"response_file,omitempty"`
+	StatusCode   int               `json:"status_code,omitempty"`
+}
+
+// Explain runs endpoint lookup, selector extraction and rule matching for req
+// exactly as handleRequest would, and reports every intermediate result
+func (h *MockHandler) Explain(req *http.Request) *MatchExplanation {
+	result := &MatchExplanation{}
+
+	cfg := h.configManager.GetConfig()
+	if cfg == nil {
+		return result
+	}
+
+	endpoint, pathParams := h.matchEndpoint(cfg, req)
+	if endpoint == nil {
+		return result
+	}
+
+	index := 0
+	for i := range cfg.Endpoints {
+		if &cfg.Endpoints[i] == endpoint {
+			index = i
+			break
+		}
+	}
+
+	result.Matched = true
+	result.Endpoint = &EndpointSummary{
+		ID:          endpoint.ID,
+		Index:       index,
+		Path:        endpoint.Path,
+		Method:      endpoint.Method,
+		Description: endpoint.Description,
+		Tags:        endpoint.Tags,
+	}
+	result.PathParams = pathParams
+
+	if endpoint.Mode == config.EndpointModeCRUD || endpoint.Mode == config.EndpointModeSink || endpoint.Mode == config.EndpointModeWebSocket {
+		result.MatchedRule = endpoint.Mode
+		return result
+	}
+
+	// Buffer the body so the caller's request stays readable
+	var bodyBytes []byte
+	if req.Body != nil {
+		bodyBytes, _ = io.ReadAll(req.Body)
+	}
+	req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+
+	c := &gin.Context{Request: req}
+	for k, v := range pathParams {
+		c.Params = append(c.Params, gin.Param{Key: k, Value: v})
+	}
+
+	compiled := h.compiled.get(cfg, endpoint)
+	result.Values = extractValues(c, compiled.selectors, pathParams, bodyBytes)
+	h.addServerValues(c, cfg, endpoint, result.Values)
+	// Predict the call counter without incrementing it
+	if endpoint.Counter != "" {
+		result.Values[CounterPrefix+endpoint.Counter] = strconv.FormatInt(h.scenarioStore.GetCounter(endpoint.Counter)+1, 10)
+	}
+	ExtractCounterValues(compiled.selectors, result.Values, func(name string) int64 {
+		if name == endpoint.Counter {
+			return h.scenarioStore.GetCounter(name) + 1
+		}
+		return h.scenarioStore.GetCounter(name)
+	})
+	if endpoint.Scenario != "" {
+		result.Scenario = endpoint.Scenario
+		result.Partition = partitionOf(c, cfg, endpoint, result.Values, pathParams, bodyBytes)
+		result.Step = h.scenarioStore.GetStep(endpoint.Scenario, result.Partition)
+		h.mergeScenarioValues(endpoint, compiled.selectors, result.Partition, result.Step, result.Values)
+	}
+
+	rules := compiled.rules
+	result.Rules = EvaluateRules(result.Values, rules)
+
+	var matchedRule *Rule
+	if endpoint.Scenario != "" {
+		matchedRule = MatchRulesForStep(result.Values, rules, result.Step)
+	} else {
+		matchedRule = MatchRules(result.Values, rules)
+	}
+
+	if matchedRule != nil {
+		idx := getRuleIndex(rules, matchedRule)
+		result.MatchedRule = result.Rules[idx].Name
+		result.ResponseFile = matchedRule.ResponseFile
+		result.StatusCode = matchedRule.StatusCode
+	} else {
+		def := endpoint.DefaultFor(req.Method)
+		result.MatchedRule = "default"
+		result.ResponseFile = def.ResponseFile
+		result.StatusCode = def.StatusCode
+	}
+	if result.StatusCode == 0 {
+		result.StatusCode = http.StatusOK
+	}
+
+	return result
+}
@@ -2,14 +2,21 @@ package handler
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
+	"mock-api-server/broker"
 	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/pkg/events"
+	"mock-api-server/pkg/ratelimit"
+	"mock-api-server/state"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,148 +25,240 @@ import (
 type MockHandler struct {
 	configManager   *config.ConfigManager
 	responseBuilder *ResponseBuilder
+	limiter         *ratelimit.Limiter // per-endpoint rate limits
+	quotas          *ratelimit.WindowLimiter
+	concurrency     *ratelimit.ConcurrencyLimiter
+	scenarioStore   state.Store
+	resources       *state.ResourceStore // collections of crud endpoints
+	cursors         *state.CursorStore   // pagination cursors of crud lists
+	sinks           *state.SinkStore     // requests received by sink endpoints
+	idempotency     *state.IdempotencyStore
+	challenges      *challengeAttempts // fail_attempts of auth challenges
+	eventBus        *events.Bus
+	hookClient      *http.Client // calls responder hooks
+	emitter         *broker.Emitter
+	compiled        endpointCache // selectors and rules of the current config
+	router          atomic.Pointer[routeTable]
 }
 
-// NewMockHandler creates a new MockHandler
-func NewMockHandler(cfgManager *config.ConfigManager) *MockHandler {
-	return &MockHandler{
+// NewMockHandler creates a new MockHandler. Scenario transitions are kept in
+// scenarioStore and published on eventBus, which may be nil.
+func NewMockHandler(cfgManager *config.ConfigManager, scenarioStore state.Store, eventBus *events.Bus) *MockHandler {
+	if scenarioStore == nil {
+		scenarioStore = state.NewScenarioStore()
+	}
+	h := &MockHandler{
 		configManager:   cfgManager,
 		responseBuilder: NewResponseBuilder(),
+		limiter:         ratelimit.NewLimiter(),
+		quotas:          ratelimit.NewWindowLimiter(),
+		concurrency:     ratelimit.NewConcurrencyLimiter(),
+		scenarioStore:   scenarioStore,
+		resources:       state.NewResourceStore(),
+		cursors:         state.NewCursorStore(),
+		sinks:           state.NewSinkStore(),
+		idempotency:     state.NewIdempotencyStore(),
+		challenges:      newChallengeAttempts(),
+		eventBus:        eventBus,
+		hookClient:      &http.Client{},
 	}
+	// Compile a new config as soon as it is active rather than on the
+	// first request that sees it
+	cfgManager.Subscribe(func(_, cfg *config.Config) {
+		if cfg != nil {
+			h.compiled.store(cfg)
+			h.routes(cfg)
+		}
+	})
+	return h
+}
+
+// SetEmitter enables the events of matched responses; without an emitter
+// they are ignored
+func (h *MockHandler) SetEmitter(emitter *broker.Emitter) {
+	h.emitter = emitter
 }
 
-// RegisterRoutes registers all endpoint routes from config
+// RegisterRoutes serves the mock endpoints behind the routes of r. Each
+// config gets a gin engine with a route per endpoint (see routeTable), so
+// reloads and admin changes take effect and the mock routes never conflict
+// with the others.
 func (h *MockHandler) RegisterRoutes(r *gin.Engine) {
+	r.NoRoute(h.handleRequest)
+}
+
+// handleRequest routes a mock request through the engine of the current config
+func (h *MockHandler) handleRequest(c *gin.Context) {
 	cfg := h.configManager.GetConfig()
 	if cfg == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "configuration not loaded"})
 		return
 	}
-
-	// Register each endpoint path individually to avoid wildcard conflicts
-	for _, ep := range cfg.Endpoints {
-		path := ep.Path
-		method := strings.ToUpper(ep.Method)
-
-		switch method {
-		case "GET":
-			r.GET(path, h.handleRequest)
-		case "POST":
-			r.POST(path, h.handleRequest)
-		case "PUT":
-			r.PUT(path, h.handleRequest)
-		case "DELETE":
-			r.DELETE(path, h.handleRequest)
-		case "PATCH":
-			r.PATCH(path, h.handleRequest)
-		case "OPTIONS":
-			r.OPTIONS(path, h.handleRequest)
-		case "HEAD":
-			r.HEAD(path, h.handleRequest)
-		default:
-			r.Handle(method, path, h.handleRequest)
-		}
+	if c.Keys == nil {
+		c.Keys = make(map[any]any)
 	}
+	h.routes(cfg).serve(c.Writer, c.Request, &dispatch{keys: c.Keys})
+}
 
-	// Set NoRoute handler for 404
-	r.NoRoute(func(c *gin.Context) {
-		h.handleNotFound(c, h.configManager.GetConfig())
-	})
+// handleUnmatched answers a request no endpoint serves: from an OpenAPI spec
+// when schema_fallback documents it, otherwise with 404
+func (h *MockHandler) handleUnmatched(c *gin.Context, cfg *config.Config) {
+	if h.serveFromSchema(c, cfg) {
+		return
+	}
+	h.handleNotFound(c, cfg)
 }
 
-// handleRequest handles incoming requests and matches against config endpoints
-func (h *MockHandler) handleRequest(c *gin.Context) {
-	cfg := h.configManager.GetConfig()
-	if cfg == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "configuration not loaded"})
+// serveEndpoint answers a request routed to endpoint
+func (h *MockHandler) serveEndpoint(c *gin.Context, cfg *config.Config, endpoint *config.Endpoint, pathParams map[string]string) {
+	c.Set("endpoint_id", endpoint.ID)
+	if len(endpoint.Tags) > 0 {
+		c.Set("endpoint_tags", endpoint.Tags)
+	}
+	if endpoint.BasePath != "" {
+		c.Set("endpoint_base_path", endpoint.BasePath)
+	}
+
+	if !middleware.CheckRateLimit(c, cfg, h.limiter, endpoint.RateLimit, "endpoint:"+endpoint.ID) ||
+		!middleware.CheckQuota(c, cfg, h.quotas, endpoint.Quota, "endpoint:"+endpoint.ID) {
 		return
 	}
 
-	path := c.Request.URL.Path
-	method := c.Request.Method
+	release, ok := middleware.AcquireConcurrency(c, cfg, h.concurrency, endpoint.ID, endpoint.MaxConcurrentRequests, cfg.Server.ConcurrencyQueueMs)
+	if !ok {
+		return
+	}
+	defer release()
 
-	// Find matching endpoint
-	endpoint, pathParams := h.findEndpoint(cfg.Endpoints, path, method)
-	if endpoint == nil {
-		h.handleNotFound(c, cfg)
+	if !checkContentTypes(c, cfg, endpoint) || !h.checkChallenge(c, cfg, endpoint) {
+		return
+	}
+	done, proceed := h.idempotent(c, cfg, endpoint)
+	if !proceed {
 		return
 	}
+	defer done()
 
 	// Store path params in context
 	for k, v := range pathParams {
 		c.Params = append(c.Params, gin.Param{Key: k, Value: v})
 	}
 
-	// Read body for potential reuse
-	bodyBytes, err := io.ReadAll(c.Request.Body)
-	if err != nil {
-		bodyBytes = []byte{}
+	switch endpoint.Mode {
+	case config.EndpointModeCRUD:
+		h.handleCRUD(c, cfg, endpoint, pathParams)
+		return
+	case config.EndpointModeSink:
+		h.handleSink(c, cfg, endpoint, pathParams)
+		return
+	case config.EndpointModeWebSocket:
+		h.handleWebSocket(c, cfg, endpoint, pathParams)
+		return
 	}
-	// Restore body for selectors
-	c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	// Convert config selectors to handler selectors
-	selectors := make([]Selector, len(endpoint.Selectors))
-	for i, s := range endpoint.Selectors {
-		selectors[i] = Selector{
-			Name: s.Name,
-			Type: s.Type,
-			Key:  s.Key,
+	compiled := h.compiled.get(cfg, endpoint)
+
+	// Buffer the body only when something reads it, so large uploads to
+	// other endpoints are never held in memory
+	var bodyBytes []byte
+	var err error
+	if compiled.needsBody {
+		var releaseBody func()
+		bodyBytes, releaseBody, err = readBody(c.Request.Body)
+		defer releaseBody()
+		if err != nil {
+			if middleware.IsBodyTooLarge(err) {
+				middleware.AbortBodyTooLarge(c, cfg)
+				return
+			}
+			bodyBytes = bodyBytes[:0]
 		}
+		// Restore body for later readers
+		c.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 	}
 
 	// Extract values from request
-	values := ExtractValues(c, selectors, pathParams)
+	values := extractValues(c, compiled.selectors, pathParams, bodyBytes)
+	h.addServerValues(c, cfg, endpoint, values)
+
+	// Count the call before matching so the nth request sees n
+	h.countCall(endpoint, compiled.selectors, values)
+
+	// Scenario endpoints see the partition's variables as var.<name>
+	var partition, step string
+	if endpoint.Scenario != "" {
+		partition = partitionOf(c, cfg, endpoint, values, pathParams, bodyBytes)
+		step = h.scenarioStore.GetStep(endpoint.Scenario, partition)
+		h.scenarioStore.Touch(endpoint.Scenario, partition)
+		c.Set("scenario_step", step)
+		h.mergeScenarioValues(endpoint, compiled.selectors, partition, step, values)
+	}
 
-	// Convert config rules to handler rules
-	rules := make([]Rule, len(endpoint.Rules))
-	for i, r := range endpoint.Rules {
-		conditions := make([]Condition, len(r.Conditions))
-		for j, cond := range r.Conditions {
-			conditions[j] = Condition{
-				Selector:  cond.Selector,
-				MatchType: cond.MatchType,
-				Value:     cond.Value,
-			}
-		}
-		rules[i] = Rule{
-			Conditions:   conditions,
-			ResponseFile: r.ResponseFile,
-			StatusCode:   r.StatusCode,
-			DelayMs:      r.DelayMs,
-			Headers:      r.Headers,
-		}
+	rules := compiled.rules
+
+	if cfg.Server.Logging.LogMatchDetails {
+		c.Set("selector_values", values)
+		c.Set("rule_evaluations", EvaluateRules(values, rules))
 	}
 
-	// Match rules
-	matchedRule := MatchRules(values, rules)
+	// Match rules, restricted to the current step for scenario endpoints
+	var matchedRule *Rule
+	if endpoint.Scenario != "" {
+		matchedRule = MatchRulesForStep(values, rules, step)
+	} else {
+		matchedRule = MatchRules(values, rules)
+	}
 
 	// Build response config
 	var respCfg ResponseBuildConfig
 	var matchedRuleName string
+	var newStep string
+	var setVariables map[string]string
+	var increments []string
+	var responseScript *config.ScriptConfig
+	var responder *config.ResponderConfig
+	var emits []config.EventEmit
 
 	if matchedRule != nil {
-		matchedRuleName = fmt.Sprintf("rule_%d", getRuleIndex(rules, matchedRule))
+		matchedRuleName = compiled.ruleName(matchedRule)
+		newStep = matchedRule.NewStep
+		setVariables = matchedRule.SetVariables
+		increments = matchedRule.Increment
+		responseScript = matchedRule.Script
+		responder = matchedRule.Responder
+		emits = matchedRule.Events
 		respCfg = ResponseBuildConfig{
 			ResponseFile:    matchedRule.ResponseFile,
 			StatusCode:      matchedRule.StatusCode,
 			DelayMs:         matchedRule.DelayMs,
 			Headers:         matchedRule.Headers,
 			TemplateEnabled: false, // Rules don't have template config currently
+			Mutate:          matchedRule.Mutate,
+			FromOpenAPI:     matchedRule.FromOpenAPI,
 		}
 	} else {
+		def := endpoint.DefaultFor(c.Request.Method)
 		matchedRuleName = "default"
+		newStep = def.NewStep
+		setVariables = def.SetVariables
+		increments = def.Increment
+		responseScript = def.Script
+		responder = def.Responder
+		emits = def.Events
 		respCfg = ResponseBuildConfig{
-			ResponseFile:    endpoint.Default.ResponseFile,
-			StatusCode:      endpoint.Default.StatusCode,
-			DelayMs:         endpoint.Default.DelayMs,
-			Headers:         endpoint.Default.Headers,
-			TemplateEnabled: endpoint.Default.Template != nil && endpoint.Default.Template.Enabled,
+			ResponseFile:    def.ResponseFile,
+			StatusCode:      def.StatusCode,
+			DelayMs:         def.DelayMs,
+			Headers:         def.Headers,
+			TemplateEnabled: def.Template != nil && def.Template.Enabled,
+			Mutate:          def.Mutate,
+			FromOpenAPI:     def.FromOpenAPI,
 		}
 
 		// Handle random responses
-		if endpoint.Default.RandomResponses != nil && endpoint.Default.RandomResponses.Enabled {
-			randomConfigs := make([]RandomResponseConfig, len(endpoint.Default.RandomResponses.Files))
-			for i, rr := range endpoint.Default.RandomResponses.Files {
+		if def.RandomResponses != nil && def.RandomResponses.Enabled {
+			randomConfigs := make([]RandomResponseConfig, len(def.RandomResponses.Files))
+			for i, rr := range def.RandomResponses.Files {
 				randomConfigs[i] = RandomResponseConfig{
 					File:       rr.File,
 					Weight:     rr.Weight,
@@ -171,46 +270,234 @@ func (h *MockHandler) handleRequest(c *gin.Context) {
 		}
 	}
 
+	respCfg.MaxFileBytes = cfg.Server.MaxResponseFileBytes
+	respCfg.StrictFileSize = cfg.Server.StrictResponseFileSize
+
 	// Store matched rule name in context for logging
 	c.Set("matched_rule", matchedRuleName)
 	c.Set("response_file", respCfg.ResponseFile)
 
+	for _, name := range increments {
+		name = CounterName(name, values)
+		values[CounterPrefix+name] = strconv.FormatInt(h.scenarioStore.Increment(name, 1), 10)
+	}
+
+	// Captured variables are visible to this response's template as well
+	var captured map[string]string
+	if endpoint.Scenario != "" && len(setVariables) > 0 {
+		captured = captureVariables(setVariables, values)
+		for name, value := range captured {
+			values[VariablePrefix+name] = value
+		}
+	}
+
 	// Build response
-	result, err := h.responseBuilder.Build(respCfg, values)
+	var result *ResponseResult
+	switch {
+	case responseScript != nil:
+		result, err = runScript(c, responseScript, respCfg, values, pathParams, bodyBytes)
+	case responder != nil:
+		result, err = h.runResponder(c, responder, respCfg, values, pathParams, bodyBytes)
+	default:
+		result, err = h.responseBuilder.Build(respCfg, values)
+	}
 	if err != nil {
 		h.handleError(c, cfg, err)
 		return
 	}
 
+	if ct := endpoint.Contract; ct != nil {
+		if violations := h.checkContract(ct, endpoint, result); len(violations) > 0 {
+			c.Set("contract_violations", violations)
+			if ct.OnMismatch == config.ContractFail {
+				respondContractViolation(c, violations)
+				return
+			}
+		}
+	}
+
+	if endpoint.Scenario != "" {
+		if len(captured) > 0 {
+			h.scenarioStore.SetVariables(endpoint.Scenario, partition, captured)
+		}
+		if newStep != "" {
+			h.transition(c, endpoint.Scenario, partition, step, newStep)
+		}
+	}
+
 	// Apply delay
 	ApplyDelay(result.DelayMs)
 
-	// Set headers
-	for k, v := range result.Headers {
-		c.Header(k, v)
+	// Send response
+	result.write(c)
+
+	h.emitter.Emit(emits, values)
+}
+
+// partitionOf returns the scenario partition of a request: the value of the
+// endpoint's partition_selector, or else of the scenario's partition_key
+func partitionOf(c *gin.Context, cfg *config.Config, endpoint *config.Endpoint, values, pathParams map[string]string, body []byte) string {
+	if endpoint.PartitionSelector != "" {
+		return values[endpoint.PartitionSelector]
 	}
+	pk := cfg.Scenarios[endpoint.Scenario].PartitionKey
+	if pk == nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	key := ExtractValues(c, []Selector{{Name: "partition", Type: pk.Type, Key: pk.Key}}, pathParams)
+	return key["partition"]
+}
 
-	// Send response
-	c.Data(result.StatusCode, result.Headers["Content-Type"], result.Body)
+// addServerValues adds server and endpoint metadata to values, so fixtures
+// can build links to the mock itself: {{.server.port}}, {{.server.base_url}},
+// {{.endpoint.id}}, {{.endpoint.path}} and {{.config_generation}}
+func (h *MockHandler) addServerValues(c *gin.Context, cfg *config.Config, endpoint *config.Endpoint, values map[string]string) {
+	values["server.port"] = strconv.Itoa(cfg.Server.Port)
+	values["server.base_url"] = baseURL(c, cfg)
+	values["endpoint.id"] = endpoint.ID
+	values["endpoint.path"] = endpoint.Path
+	values["config_generation"] = strconv.FormatUint(h.configManager.Generation(), 10)
+}
+
+// baseURL returns server.base_url, or the scheme and host the request was
+// sent to, honoring X-Forwarded-Proto and X-Forwarded-Host from proxies
+func baseURL(c *gin.Context, cfg *config.Config) string {
+	if cfg.Server.BaseURL != "" {
+		return strings.TrimSuffix(cfg.Server.BaseURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host
 }
 
-// findEndpoint finds a matching endpoint for the given path and method
-func (h *MockHandler) findEndpoint(endpoints []config.Endpoint, requestPath, method string) (*config.Endpoint, map[string]string) {
-	for i := range endpoints {
-		ep := &endpoints[i]
+// CounterPrefix marks counter values among selector values, so templates
+// refer to a counter as counter.<name>
+const CounterPrefix = "counter."
 
-		// Check method
-		if !strings.EqualFold(ep.Method, method) {
-			continue
+// countCall increments the endpoint's call counter and resolves counter
+// selectors into values
+func (h *MockHandler) countCall(endpoint *config.Endpoint, selectors []Selector, values map[string]string) {
+	if endpoint.Counter != "" {
+		values[CounterPrefix+endpoint.Counter] = strconv.FormatInt(h.scenarioStore.Increment(endpoint.Counter, 1), 10)
+	}
+	ExtractCounterValues(selectors, values, func(name string) int64 {
+		if v, ok := values[CounterPrefix+name]; ok {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			return n
+		}
+		return h.scenarioStore.GetCounter(name)
+	})
+}
+
+// VariablePrefix marks scenario variables among selector values, so rules
+// and templates refer to a variable as var.<name>
+const VariablePrefix = "var."
+
+// mergeScenarioValues adds the variables of a scenario partition to values
+// and resolves the endpoint's state selectors
+func (h *MockHandler) mergeScenarioValues(endpoint *config.Endpoint, selectors []Selector, partition, step string, values map[string]string) {
+	vars := h.scenarioStore.GetVariables(endpoint.Scenario, partition)
+	for name, value := range vars {
+		values[VariablePrefix+name] = value
+	}
+	ExtractStateValues(selectors, values, step, vars)
+}
+
+// captureVariables resolves set_variables (variable -> selector) against the
+// extracted values. Selectors without a value are skipped.
+func captureVariables(setVariables, values map[string]string) map[string]string {
+	captured := make(map[string]string, len(setVariables))
+	for name, selector := range setVariables {
+		if value, ok := values[selector]; ok && value != "" {
+			captured[name] = value
 		}
+	}
+	return captured
+}
+
+// transition moves a scenario partition to newStep and publishes the change
+func (h *MockHandler) transition(c *gin.Context, scenario, partition, previous, newStep string) {
+	h.scenarioStore.SetStep(scenario, partition, newStep)
+	h.scenarioStore.RecordTransition(scenario, state.Transition{
+		Partition:    partition,
+		PreviousStep: previous,
+		Step:         newStep,
+		Source:       "request",
+		RequestID:    c.GetString("request_id"),
+	})
+	c.Set("scenario_step", newStep)
+
+	h.eventBus.Publish(events.TypeScenarioTransition, map[string]interface{}{
+		"scenario":      scenario,
+		"partition":     partition,
+		"previous_step": previous,
+		"step":          newStep,
+		"source":        "request",
+		"request_id":    c.GetString("request_id"),
+	})
+}
 
-		// Check path (with parameter support)
-		pathParams, matched := matchPath(ep.Path, requestPath)
-		if matched {
-			return ep, pathParams
+// toSelectors converts config selectors to handler selectors
+func toSelectors(endpoint *config.Endpoint) []Selector {
+	selectors := make([]Selector, len(endpoint.Selectors))
+	for i, s := range endpoint.Selectors {
+		selectors[i] = Selector{
+			Name: s.Name,
+			Type: s.Type,
+			Key:  s.Key,
 		}
 	}
-	return nil, nil
+	return selectors
+}
+
+// toRules converts config rules to handler rules
+func toRules(endpoint *config.Endpoint) []Rule {
+	rules := make([]Rule, len(endpoint.Rules))
+	for i, r := range endpoint.Rules {
+		conditions := make([]Condition, len(r.Conditions))
+		for j, cond := range r.Conditions {
+			conditions[j] = Condition{
+				Selector:   cond.Selector,
+				MatchType:  cond.MatchType,
+				Value:      cond.Value,
+				IgnoreCase: cond.IgnoreCase,
+				TrimSpace:  cond.TrimSpace,
+			}
+		}
+		rules[i] = Rule{
+			Conditions:   conditions,
+			ResponseFile: r.ResponseFile,
+			StatusCode:   r.StatusCode,
+			DelayMs:      r.DelayMs,
+			Headers:      r.Headers,
+			RequiredStep: r.RequiredStep,
+			NewStep:      r.NewStep,
+			SetVariables: r.SetVariables,
+			Increment:    r.Increment,
+			Script:       r.Script,
+			Responder:    r.Responder,
+			Events:       r.Events,
+			Mutate:       r.Mutate,
+			FromOpenAPI:  r.FromOpenAPI,
+		}
+	}
+	return rules
+}
+
+// matchEndpoint finds the enabled endpoint of cfg serving req through the
+// config's route table
+func (h *MockHandler) matchEndpoint(cfg *config.Config, req *http.Request) (*config.Endpoint, map[string]string) {
+	return h.routes(cfg).lookup(req)
 }
 
 // matchPath matches a request path against an endpoint path pattern
@@ -243,47 +530,74 @@ func matchPath(pattern, requestPath string) (map[string]string, bool) {
 
 // handleNotFound handles 404 responses
 func (h *MockHandler) handleNotFound(c *gin.Context, cfg *config.Config) {
-	// Check for custom 404 response
-	if file, ok := cfg.Server.ErrorHandling.CustomErrorResponses[404]; ok {
-		content, err := os.ReadFile(file)
-		if err == nil {
-			c.Data(http.StatusNotFound, "application/json", content)
-			return
+	middleware.AbortWithError(c, cfg, http.StatusNotFound, gin.H{
+		"code":    "NOT_FOUND",
+		"message": "The requested resource was not found",
+		"method":  c.Request.Method,
+		"path":    c.Request.URL.Path,
+	})
+}
+
+// anyMethods are offered in CORS preflights for ANY, crud and sink endpoints
+var anyMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// PreflightMethods returns the methods a CORS preflight for req is allowed:
+// those of the enabled endpoints matching its path. ok is false when no
+// endpoint matches, or when an OPTIONS endpoint does and answers instead.
+func (h *MockHandler) PreflightMethods(req *http.Request) (methods []string, ok bool) {
+	cfg := h.configManager.GetConfig()
+	if cfg == nil {
+		return nil, false
+	}
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		if !h.configManager.IsEndpointEnabled(ep.ID) {
+			continue
+		}
+		if _, matched := matchPath(ep.Path, req.URL.Path); !matched {
+			continue
+		}
+		epMethods := []string{strings.ToUpper(ep.Method)}
+		switch {
+		case strings.EqualFold(ep.Method, http.MethodOptions):
+			return nil, false
+		case isGenericEndpoint(ep):
+			epMethods = anyMethods
+		}
+		for _, method := range epMethods {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
 		}
 	}
+	sort.Strings(methods)
+	return methods, len(methods) > 0
+}
 
-	c.JSON(http.StatusNotFound, gin.H{
-		"error": gin.H{
-			"code":    "NOT_FOUND",
-			"message": "The requested resource was not found",
-			"path":    c.Request.URL.Path,
-		},
+// handleMethodNotAllowed answers a request for a known path with a method
+// none of its endpoints accepts
+func (h *MockHandler) handleMethodNotAllowed(c *gin.Context, cfg *config.Config, allowed []string) {
+	c.Header("Allow", strings.Join(allowed, ", "))
+	middleware.AbortWithError(c, cfg, http.StatusMethodNotAllowed, gin.H{
+		"code":    "METHOD_NOT_ALLOWED",
+		"message": "The requested method is not allowed for this resource",
+		"path":    c.Request.URL.Path,
+		"allowed": allowed,
 	})
 }
 
 // handleError handles internal errors
 func (h *MockHandler) handleError(c *gin.Context, cfg *config.Config, err error) {
-	// Check for custom 500 response
-	if file, ok := cfg.Server.ErrorHandling.CustomErrorResponses[500]; ok {
-		content, readErr := os.ReadFile(file)
-		if readErr == nil {
-			c.Data(http.StatusInternalServerError, "application/json", content)
-			return
-		}
-	}
-
-	response := gin.H{
-		"error": gin.H{
-			"code":    "INTERNAL_ERROR",
-			"message": "An internal error occurred",
-		},
+	errBody := gin.H{
+		"code":    "INTERNAL_ERROR",
+		"message": "An internal error occurred",
 	}
 
 	if cfg.Server.ErrorHandling.ShowDetails {
-		response["error"].(gin.H)["details"] = err.Error()
+		errBody["details"] = err.Error()
 	}
 
-	c.JSON(http.StatusInternalServerError, response)
+	middleware.AbortWithError(c, cfg, http.StatusInternalServerError, errBody)
 }
 
 // getRuleIndex returns the index of a rule in the rules slice
@@ -296,27 +610,6 @@ func getRuleIndex(rules []Rule, target *Rule) int {
 	return -1
 }
 
-// HealthHandler returns the health check handler
-func HealthHandler(cfgManager *config.ConfigManager) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		cfg := cfgManager.GetConfig()
-		endpointsCount := 0
-		if cfg != nil {
-			endpointsCount = len(cfg.Endpoints)
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"status":    "healthy",
-			"timestamp": cfgManager.GetLoadedAt().Format("2006-01-02T15:04:05Z07:00"),
-			"config": gin.H{
-				"loaded_at":       cfgManager.GetLoadedAt().Format("2006-01-02T15:04:05Z07:00"),
-				"endpoints_count": endpointsCount,
-				"hot_reload":      cfg != nil && cfg.Server.HotReload,
-			},
-		})
-	}
-}
-
 // extractPathParams extracts path parameters from a pattern and value
 func extractPathParams(pattern, value string) map[string]string {
 	params := make(map[string]string)
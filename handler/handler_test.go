@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPartitionOf(t *testing.T) {
+	cfg := &config.Config{Scenarios: map[string]config.ScenarioConfig{
+		"checkout": {PartitionKey: &config.PartitionKey{Type: "body", Key: "customer_id"}},
+	}}
+	body := []byte(`{"customer_id":"c42"}`)
+
+	newContext := func() *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/checkout", strings.NewReader(""))
+		return c
+	}
+
+	ep := &config.Endpoint{Scenario: "checkout"}
+	if got := partitionOf(newContext(), cfg, ep, map[string]string{}, nil, body); got != "c42" {
+		t.Errorf("expected partition from scenario partition_key, got %q", got)
+	}
+
+	ep.PartitionSelector = "session"
+	if got := partitionOf(newContext(), cfg, ep, map[string]string{"session": "s1"}, nil, body); got != "s1" {
+		t.Errorf("expected endpoint partition_selector to win, got %q", got)
+	}
+
+	other := &config.Endpoint{Scenario: "login"}
+	if got := partitionOf(newContext(), cfg, other, map[string]string{}, nil, body); got != "" {
+		t.Errorf("expected no partition without partition_key, got %q", got)
+	}
+}
+
+func TestHandleRequestPerIDCounters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{
+			ID: "create-order", Path: "/order", Method: "POST",
+			Selectors: []config.Selector{{Name: "id", Type: "body", Key: "id"}},
+			Default:   config.ResponseConfig{StatusCode: 201, Increment: []string{"order:{{.id}}"}},
+		},
+		{
+			ID: "get-order", Path: "/order/:id", Method: "GET",
+			Selectors: []config.Selector{
+				{Name: "id", Type: "path", Key: "id"},
+				{Name: "created", Type: "counter", Key: "order:{{.id}}"},
+			},
+			Rules: []config.Rule{{
+				Conditions:     []config.Condition{{Selector: "created", MatchType: "exact", Value: "0"}},
+				ResponseConfig: config.ResponseConfig{StatusCode: 404},
+			}},
+			Default: config.ResponseConfig{StatusCode: 200},
+		},
+	}})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	call := func(method, path, body string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w.Code
+	}
+
+	if code := call(http.MethodGet, "/order/42", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 before the order is created, got %d", code)
+	}
+	if code := call(http.MethodPost, "/order", `{"id":"42"}`); code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", code)
+	}
+	if code := call(http.MethodGet, "/order/42", ""); code != http.StatusOK {
+		t.Errorf("expected 200 once the order is created, got %d", code)
+	}
+	if code := call(http.MethodGet, "/order/7", ""); code != http.StatusNotFound {
+		t.Errorf("expected other ids to stay 404, got %d", code)
+	}
+}
+
+func TestHandleRequestServerTemplateValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	file := filepath.Join(t.TempDir(), "order.json")
+	body := `{"self":"{{.server.base_url}}/orders/{{.id}}","route":"{{.endpoint.path}}","port":{{.server.port}},"generation":{{.config_generation}}}`
+	if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+		t.Fatalf("failed to write response file: %v", err)
+	}
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{
+		Server: config.ServerConfig{Port: 8080},
+		Endpoints: []config.Endpoint{{
+			ID: "get-order", Path: "/orders/:id", Method: "GET",
+			Selectors: []config.Selector{{Name: "id", Type: "path", Key: "id"}},
+			Default: config.ResponseConfig{
+				StatusCode: 200, ResponseFile: file,
+				Template: &config.TemplateConfig{Enabled: true},
+			},
+		}},
+	})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	call := func(header map[string]string) string {
+		req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+		req.Host = "mock.internal:8080"
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	want := `{"self":"http://mock.internal:8080/orders/7","route":"/orders/:id","port":8080,"generation":1}`
+	if got := call(nil); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+	want = `{"self":"https://api.example.com/orders/7","route":"/orders/:id","port":8080,"generation":1}`
+	if got := call(map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com"}); got != want {
+		t.Errorf("behind a proxy: body = %s, want %s", got, want)
+	}
+}
+
+func TestExplainSeesServerValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{
+		Endpoints: []config.Endpoint{{
+			ID: "get-order", Path: "/orders/:id", Method: "GET",
+			Rules: []config.Rule{{
+				Conditions:     []config.Condition{{Selector: "server.base_url", MatchType: "prefix", Value: "https://"}},
+				ResponseConfig: config.ResponseConfig{StatusCode: http.StatusAccepted},
+			}},
+			Default: config.ResponseConfig{StatusCode: http.StatusOK},
+		}},
+	})
+	h := NewMockHandler(cm, nil, nil)
+	router := gin.New()
+	h.RegisterRoutes(router)
+
+	for _, proto := range []string{"http", "https"} {
+		req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+		req.Header.Set("X-Forwarded-Proto", proto)
+		explained := h.Explain(req.Clone(req.Context()))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if explained.StatusCode != w.Code {
+			t.Errorf("%s: explained status %d, served %d", proto, explained.StatusCode, w.Code)
+		}
+		if want := proto + "://example.com"; explained.Values["server.base_url"] != want {
+			t.Errorf("%s: server.base_url = %q, want %q", proto, explained.Values["server.base_url"], want)
+		}
+	}
+}
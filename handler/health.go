@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/template"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthOverride forces the health endpoints into a failure state, so the
+// mock can simulate its own health check failing
+type HealthOverride struct {
+	mu         sync.RWMutex
+	active     bool
+	statusCode int
+	message    string
+}
+
+// NewHealthOverride creates an inactive HealthOverride
+func NewHealthOverride() *HealthOverride {
+	return &HealthOverride{}
+}
+
+// ForceUnhealthy makes /health and /health/ready respond with statusCode
+func (o *HealthOverride) ForceUnhealthy(statusCode int, message string) {
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.active = true
+	o.statusCode = statusCode
+	o.message = message
+}
+
+// Clear restores normal health reporting
+func (o *HealthOverride) Clear() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.active = false
+	o.statusCode = 0
+	o.message = ""
+}
+
+// Get returns the forced status code and message, and whether an override is active
+func (o *HealthOverride) Get() (statusCode int, message string, active bool) {
+	if o == nil {
+		return 0, "", false
+	}
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.statusCode, o.message, o.active
+}
+
+// HealthHandler returns the health check handler
+func HealthHandler(cfgManager *config.ConfigManager, override *HealthOverride) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := cfgManager.GetConfig()
+		endpointsCount := 0
+		if cfg != nil {
+			endpointsCount = len(cfg.Endpoints)
+		}
+
+		status, statusCode := "healthy", http.StatusOK
+		forcedCode, message, forced := override.Get()
+		if forced {
+			status, statusCode = "unhealthy", forcedCode
+		}
+
+		loadedAt := cfgManager.GetLoadedAt().Format(time.RFC3339)
+		generation := cfgManager.Generation()
+
+		// A custom response file replaces the built-in body
+		if cfg != nil && cfg.HealthCheck.ResponseFile != "" {
+			content, err := os.ReadFile(cfg.HealthCheck.ResponseFile)
+			if err == nil {
+				values := map[string]string{
+					"status":            status,
+					"message":           message,
+					"loaded_at":         loadedAt,
+					"endpoints_count":   strconv.Itoa(endpointsCount),
+					"config_generation": strconv.FormatUint(generation, 10),
+				}
+				for k, v := range cfg.HealthCheck.Fields {
+					values[k] = v
+				}
+				c.Data(statusCode, "application/json", template.ReplaceVariables(content, values))
+				return
+			}
+		}
+
+		response := gin.H{
+			"status":    status,
+			"timestamp": loadedAt,
+			"config": gin.H{
+				"loaded_at":       loadedAt,
+				"endpoints_count": endpointsCount,
+				"hot_reload":      cfg != nil && cfg.Server.HotReload,
+			},
+			// Bumped on every config change, so clients can wait for theirs to apply
+			"config_generation": generation,
+		}
+		if forced && message != "" {
+			response["message"] = message
+		}
+		if cfg != nil {
+			for k, v := range cfg.HealthCheck.Fields {
+				if _, exists := response[k]; !exists {
+					response[k] = v
+				}
+			}
+		}
+
+		c.JSON(statusCode, response)
+	}
+}
+
+// LiveHandler reports that the process is up and serving requests
+func LiveHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "alive"})
+	}
+}
+
+// ReadyHandler reports whether the server can serve mock traffic. It returns
+// 503 until a config is loaded and, with ready_strict, while validation warns.
+func ReadyHandler(cfgManager *config.ConfigManager, override *HealthOverride) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := cfgManager.GetConfig()
+		if cfg == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "not_ready",
+				"checks": gin.H{"config_loaded": false},
+			})
+			return
+		}
+
+		warnings := config.ValidateConfig(cfg)
+		ready := !cfg.HealthCheck.ReadyStrict || len(warnings) == 0
+
+		status, code := "ready", http.StatusOK
+		if !ready {
+			status, code = "not_ready", http.StatusServiceUnavailable
+		}
+
+		checks := gin.H{
+			"config_loaded":       true,
+			"validation_warnings": len(warnings),
+			"ready_strict":        cfg.HealthCheck.ReadyStrict,
+		}
+		if forcedCode, message, forced := override.Get(); forced {
+			status, code = "not_ready", forcedCode
+			checks["forced_unhealthy"] = true
+			if message != "" {
+				checks["message"] = message
+			}
+		}
+
+		c.JSON(code, gin.H{
+			"status": status,
+			"checks": checks,
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthHandler_CustomResponseAndOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	file := filepath.Join(t.TempDir(), "health.json")
+	if err := os.WriteFile(file, []byte(`{"state":"{{.status}}","version":"{{.version}}"}`), 0644); err != nil {
+		t.Fatalf("failed to write response file: %v", err)
+	}
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{HealthCheck: config.HealthCheck{
+		Enabled:      true,
+		ResponseFile: file,
+		Fields:       map[string]string{"version": "1.2.3"},
+	}})
+
+	override := NewHealthOverride()
+	router := gin.New()
+	router.GET("/health", HealthHandler(cm, override))
+
+	tests := []struct {
+		name       string
+		force      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"healthy", false, http.StatusOK, `{"state":"healthy","version":"1.2.3"}`},
+		{"forced unhealthy", true, http.StatusServiceUnavailable, `{"state":"unhealthy","version":"1.2.3"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.force {
+				override.ForceUnhealthy(0, "maintenance")
+			} else {
+				override.Clear()
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultIdempotencyTTL is how long responses are replayed when the
+// endpoint sets no ttl_sec
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// ResetIdempotency forgets every stored idempotent response, so retried
+// keys are handled anew
+func (h *MockHandler) ResetIdempotency() {
+	h.idempotency.ResetAll()
+}
+
+// idempotent handles the endpoint's idempotency key. A retry of a completed
+// request gets the stored response again and proceed is false. Otherwise the
+// response is captured, and done must be called once it is written to store
+// it; 5xx responses are not stored so the retry is handled anew.
+func (h *MockHandler) idempotent(c *gin.Context, cfg *config.Config, ep *config.Endpoint) (done func(), proceed bool) {
+	idem := ep.Idempotency
+	// A websocket connection has no replayable response and would hold the
+	// key for as long as it stays open
+	if idem == nil || ep.Mode == config.EndpointModeWebSocket {
+		return func() {}, true
+	}
+	header := idem.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	key := c.GetHeader(header)
+	if key == "" {
+		if idem.Required {
+			c.Set("matched_rule", "idempotency")
+			middleware.AbortWithError(c, cfg, http.StatusBadRequest, gin.H{
+				"code":    "IDEMPOTENCY_KEY_REQUIRED",
+				"message": "The " + header + " header is required",
+			})
+			return nil, false
+		}
+		return func() {}, true
+	}
+
+	ttl := time.Duration(idem.TTLSec) * time.Second
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	hash, err := requestHash(c)
+	if middleware.IsBodyTooLarge(err) {
+		middleware.AbortBodyTooLarge(c, cfg)
+		return nil, false
+	}
+	storeKey := ep.ID + "|" + key
+	status, stored := h.idempotency.Begin(storeKey, hash, ttl)
+	switch status {
+	case state.IdempotencyReplay:
+		c.Set("matched_rule", "idempotent_replay")
+		for name, values := range stored.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Status(stored.Status)
+		c.Writer.Write(stored.Body)
+		c.Abort()
+		return nil, false
+	case state.IdempotencyInFlight:
+		c.Set("matched_rule", "idempotency")
+		middleware.AbortWithError(c, cfg, http.StatusConflict, gin.H{
+			"code":    "IDEMPOTENCY_KEY_IN_USE",
+			"message": "A request with this " + header + " is still being processed",
+		})
+		return nil, false
+	case state.IdempotencyMismatch:
+		c.Set("matched_rule", "idempotency")
+		middleware.AbortWithError(c, cfg, http.StatusUnprocessableEntity, gin.H{
+			"code":    "IDEMPOTENCY_KEY_REUSED",
+			"message": "This " + header + " was already used for a different request",
+		})
+		return nil, false
+	}
+
+	capture := &captureWriter{ResponseWriter: c.Writer}
+	c.Writer = capture
+	return func() {
+		if capture.Status() >= 500 {
+			h.idempotency.Abandon(storeKey)
+			return
+		}
+		header := capture.Header().Clone()
+		for _, name := range perRequestHeaders {
+			header.Del(name)
+		}
+		h.idempotency.Complete(storeKey, &state.IdempotentResponse{
+			Status: capture.Status(),
+			Header: header,
+			Body:   capture.body.Bytes(),
+		})
+	}, true
+}
+
+// perRequestHeaders belong to the request being answered, so a replay keeps
+// the retry's own values instead of the stored ones
+var perRequestHeaders = []string{middleware.RequestIDHeader, "traceparent", "tracestate"}
+
+// requestHash identifies a request by method, path, query and body, so an
+// idempotency key reused for a different request is detected. The body is
+// read within the server's body limit and restored for later readers.
+func requestHash(c *gin.Context) (string, error) {
+	body, release, err := readBody(c.Request.Body)
+	defer release()
+	if err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			return "", err
+		}
+		body = body[:0]
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(bytes.Clone(body)))
+
+	sum := sha256.New()
+	io.WriteString(sum, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// captureWriter keeps a copy of the response body
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
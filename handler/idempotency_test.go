@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleRequestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{
+		ID: "payments", Path: "/payments", Method: "ANY", Mode: config.EndpointModeCRUD,
+		Idempotency: &config.Idempotency{Required: true},
+	}}})
+	router := gin.New()
+	h := NewMockHandler(cm, nil, nil)
+	h.RegisterRoutes(router)
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("", `{"amount":10}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a missing key to get 400, got %d", w.Code)
+	}
+
+	first := post("k1", `{"amount":10}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", first.Code, first.Body.String())
+	}
+	retry := post("k1", `{"amount":10}`)
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("expected the retry to replay %d %s, got %d %s", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected the replay to be marked with Idempotent-Replayed")
+	}
+	if other := post("k2", `{"amount":10}`); other.Body.String() == first.Body.String() {
+		t.Error("expected a new key to create a new resource")
+	}
+	if w := post("k1", `{"amount":20}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected a reused key with another body to get 422, got %d", w.Code)
+	}
+
+	h.ResetIdempotency()
+	if w := post("k1", `{"amount":20}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected reset to forget stored responses, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyReplayKeepsRequestHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{
+		ID: "orders", Path: "/orders", Method: "ANY", Mode: config.EndpointModeCRUD,
+		Idempotency: &config.Idempotency{},
+	}}})
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	post := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "k1")
+		req.Header.Set(middleware.RequestIDHeader, requestID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("first"); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	retry := post("retry")
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("expected the retry to be replayed")
+	}
+	if got := retry.Header().Get(middleware.RequestIDHeader); got != "retry" {
+		t.Errorf("expected the replay to echo the retry's request ID, got %q", got)
+	}
+}
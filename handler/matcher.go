@@ -1,16 +1,27 @@
 package handler
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"mock-api-server/config"
 )
 
 // Condition represents a matching condition
 type Condition struct {
-	Selector  string
-	MatchType string
-	Value     string
+	Selector   string
+	MatchType  string
+	Value      string
+	IgnoreCase bool
+	TrimSpace  bool
+
+	regex    *regexp.Regexp // compiled Value of a regex condition, see compileEndpoint
+	expected *any           // parsed file of a json_equals condition, see compileEndpoint
 }
 
 // Rule represents a matching rule with conditions and response
@@ -20,6 +31,15 @@ type Rule struct {
 	StatusCode   int
 	DelayMs      int
 	Headers      map[string]string
+	RequiredStep string // empty matches in any scenario step
+	NewStep      string
+	SetVariables map[string]string
+	Increment    []string
+	Script       *config.ScriptConfig
+	Responder    *config.ResponderConfig
+	Events       []config.EventEmit
+	Mutate       []config.Mutation
+	FromOpenAPI  *config.OpenAPIExample
 }
 
 // MatchRules finds the first matching rule based on extracted values
@@ -34,6 +54,21 @@ func MatchRules(values map[string]string, rules []Rule) *Rule {
 	return nil
 }
 
+// MatchRulesForStep finds the first matching rule that is allowed in the
+// current scenario step
+func MatchRulesForStep(values map[string]string, rules []Rule, step string) *Rule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.RequiredStep != "" && rule.RequiredStep != step {
+			continue
+		}
+		if matchAllConditions(values, rule.Conditions) {
+			return rule
+		}
+	}
+	return nil
+}
+
 // matchAllConditions checks if all conditions in a rule match (AND logic)
 func matchAllConditions(values map[string]string, conditions []Condition) bool {
 	for _, cond := range conditions {
@@ -51,18 +86,31 @@ func matchAllConditions(values map[string]string, conditions []Condition) bool {
 
 // matchCondition checks if a single condition matches
 func matchCondition(targetValue string, cond Condition) bool {
+	if cond.TrimSpace {
+		targetValue = strings.TrimSpace(targetValue)
+	}
+	document := targetValue // json_equals compares the value as sent
+	value := cond.Value
+	if cond.IgnoreCase {
+		targetValue = strings.ToLower(targetValue)
+		value = strings.ToLower(value)
+	}
+
 	switch strings.ToLower(cond.MatchType) {
 	case "exact":
-		return targetValue == cond.Value
+		return targetValue == value
 
 	case "prefix":
-		return strings.HasPrefix(targetValue, cond.Value)
+		return strings.HasPrefix(targetValue, value)
 
 	case "suffix":
-		return strings.HasSuffix(targetValue, cond.Value)
+		return strings.HasSuffix(targetValue, value)
 
 	case "regex":
-		matched, err := regexp.MatchString(cond.Value, targetValue)
+		if cond.regex != nil {
+			return cond.regex.MatchString(targetValue)
+		}
+		matched, err := regexp.MatchString(conditionPattern(cond), targetValue)
 		if err != nil {
 			return false
 		}
@@ -71,12 +119,31 @@ func matchCondition(targetValue string, cond Condition) bool {
 	case "range":
 		return matchRange(targetValue, cond.Value)
 
+	case "json_equals":
+		expected := cond.expected
+		if expected == nil {
+			var err error
+			if expected, err = loadExpectedJSON(cond.Value); err != nil {
+				return false
+			}
+		}
+		return matchJSONEquals(document, *expected)
+
 	default:
 		// Default to exact match
-		return targetValue == cond.Value
+		return targetValue == value
 	}
 }
 
+// conditionPattern is the regular expression of a regex condition. With
+// ignore_case it also matches the lowercased request value.
+func conditionPattern(cond Condition) string {
+	if cond.IgnoreCase {
+		return "(?i)" + cond.Value
+	}
+	return cond.Value
+}
+
 // matchRange checks if a numeric value is within a range
 // Range format: "[min, max]" or "(min, max)" for exclusive bounds
 // Examples: "[1, 100]" means 1 <= value <= 100
@@ -132,3 +199,74 @@ func matchRange(targetValue string, rangeStr string) bool {
 
 	return minOK && maxOK
 }
+
+// ConditionResult describes the outcome of evaluating a single condition
+type ConditionResult struct {
+	Selector  string `json:"selector"`
+	MatchType string `json:"match_type"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+	Matched   bool   `json:"matched"`
+}
+
+// RuleEvaluation describes the outcome of evaluating a single rule
+type RuleEvaluation struct {
+	Name         string            `json:"name"`
+	Matched      bool              `json:"matched"` // conditions only, RequiredStep is not checked
+	RequiredStep string            `json:"required_step,omitempty"`
+	Conditions   []ConditionResult `json:"conditions"`
+}
+
+// EvaluateRules evaluates every condition of every rule without short-circuiting,
+// so callers can see why each rule did or did not match
+func EvaluateRules(values map[string]string, rules []Rule) []RuleEvaluation {
+	evaluations := make([]RuleEvaluation, len(rules))
+	for i, rule := range rules {
+		eval := RuleEvaluation{
+			Name:         fmt.Sprintf("rule_%d", i),
+			Matched:      true,
+			RequiredStep: rule.RequiredStep,
+			Conditions:   make([]ConditionResult, len(rule.Conditions)),
+		}
+		for j, cond := range rule.Conditions {
+			actual := values[cond.Selector]
+			matched := matchCondition(actual, cond)
+			eval.Conditions[j] = ConditionResult{
+				Selector:  cond.Selector,
+				MatchType: cond.MatchType,
+				Expected:  cond.Value,
+				Actual:    actual,
+				Matched:   matched,
+			}
+			if !matched {
+				eval.Matched = false
+			}
+		}
+		evaluations[i] = eval
+	}
+	return evaluations
+}
+
+// loadExpectedJSON parses the expected document of a json_equals condition
+func loadExpectedJSON(path string) (*any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var expected any
+	if err := json.Unmarshal(data, &expected); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &expected, nil
+}
+
+// matchJSONEquals reports whether targetValue is a JSON document equal to
+// expected. Objects compare regardless of key order and whitespace, numbers
+// by value; array order matters.
+func matchJSONEquals(targetValue string, expected any) bool {
+	var actual any
+	if err := json.Unmarshal([]byte(targetValue), &actual); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(actual, expected)
+}
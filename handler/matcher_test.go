@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -121,6 +123,65 @@ func TestMatchConditionRange(t *testing.T) {
 	}
 }
 
+func TestMatchConditionCanonicalization(t *testing.T) {
+	tests := []struct {
+		name        string
+		targetValue string
+		cond        Condition
+		expected    bool
+	}{
+		{"case differs", "Bearer", Condition{MatchType: "exact", Value: "bearer"}, false},
+		{"ignore case exact", "Bearer", Condition{MatchType: "exact", Value: "bearer", IgnoreCase: true}, true},
+		{"ignore case prefix", "APPLICATION/json", Condition{MatchType: "prefix", Value: "application/", IgnoreCase: true}, true},
+		{"ignore case suffix", "data.JSON", Condition{MatchType: "suffix", Value: ".json", IgnoreCase: true}, true},
+		{"ignore case regex", "GZIP, br", Condition{MatchType: "regex", Value: "^gzip", IgnoreCase: true}, true},
+		{"whitespace differs", " vip ", Condition{MatchType: "exact", Value: "vip"}, false},
+		{"trim space", " vip\t", Condition{MatchType: "exact", Value: "vip", TrimSpace: true}, true},
+		{"trim space range", " 42 ", Condition{MatchType: "range", Value: "[1, 100]", TrimSpace: true}, true},
+		{"both", "  VIP ", Condition{MatchType: "exact", Value: "vip", IgnoreCase: true, TrimSpace: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matchCondition(tt.targetValue, tt.cond)
+			if result != tt.expected {
+				t.Errorf("matchCondition(%q, %+v) = %v, want %v", tt.targetValue, tt.cond, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchConditionJSONEquals(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "expected.json")
+	if err := os.WriteFile(file, []byte(`{"user": {"id": 1, "roles": ["a", "b"]}, "active": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cond := Condition{MatchType: "json_equals", Value: file}
+
+	tests := []struct {
+		name        string
+		targetValue string
+		expected    bool
+	}{
+		{"same document", `{"user":{"id":1,"roles":["a","b"]},"active":true}`, true},
+		{"keys reordered", `{"active": true, "user": {"roles": ["a", "b"], "id": 1.0}}`, true},
+		{"array reordered", `{"user":{"id":1,"roles":["b","a"]},"active":true}`, false},
+		{"extra field", `{"user":{"id":1,"roles":["a","b"]},"active":true,"x":1}`, false},
+		{"not json", `user=1`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := matchCondition(tt.targetValue, cond); result != tt.expected {
+				t.Errorf("matchCondition(%q) = %v, want %v", tt.targetValue, result, tt.expected)
+			}
+		})
+	}
+
+	if matchCondition(`{}`, Condition{MatchType: "json_equals", Value: filepath.Join(t.TempDir(), "missing.json")}) {
+		t.Error("expected a missing file never to match")
+	}
+}
+
 func TestMatchRules(t *testing.T) {
 	rules := []Rule{
 		{
@@ -196,3 +257,124 @@ func TestMatchAllConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateRules(t *testing.T) {
+	rules := []Rule{
+		{Conditions: []Condition{{Selector: "order_id", MatchType: "exact", Value: "1001"}}},
+		{Conditions: []Condition{
+			{Selector: "order_id", MatchType: "prefix", Value: "VIP_"},
+			{Selector: "user_type", MatchType: "exact", Value: "premium"},
+		}},
+	}
+
+	evals := EvaluateRules(map[string]string{"order_id": "VIP_1", "user_type": "regular"}, rules)
+	if len(evals) != 2 {
+		t.Fatalf("expected 2 evaluations, got %d", len(evals))
+	}
+	if evals[0].Matched || evals[1].Matched {
+		t.Errorf("expected no rule to match, got %+v", evals)
+	}
+	if evals[1].Name != "rule_1" {
+		t.Errorf("expected name rule_1, got %s", evals[1].Name)
+	}
+	if !evals[1].Conditions[0].Matched || evals[1].Conditions[1].Matched {
+		t.Errorf("unexpected condition results: %+v", evals[1].Conditions)
+	}
+	if evals[1].Conditions[1].Actual != "regular" {
+		t.Errorf("expected actual value regular, got %q", evals[1].Conditions[1].Actual)
+	}
+}
+
+func TestMatchRulesForStep(t *testing.T) {
+	rules := []Rule{
+		{RequiredStep: "paid", ResponseFile: "shipped.json"},
+		{Conditions: []Condition{{Selector: "action", MatchType: "exact", Value: "pay"}}, RequiredStep: "idle", ResponseFile: "paid.json"},
+		{ResponseFile: "any.json"},
+	}
+
+	tests := []struct {
+		name     string
+		step     string
+		values   map[string]string
+		expected string
+	}{
+		{"step restricted rule", "paid", map[string]string{}, "shipped.json"},
+		{"conditions and step", "idle", map[string]string{"action": "pay"}, "paid.json"},
+		{"falls through to unrestricted rule", "idle", map[string]string{"action": "view"}, "any.json"},
+		{"unknown step", "cancelled", map[string]string{"action": "pay"}, "any.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MatchRulesForStep(tt.values, rules, tt.step)
+			if result == nil || result.ResponseFile != tt.expected {
+				t.Errorf("MatchRulesForStep() = %+v, want ResponseFile=%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCaptureVariables(t *testing.T) {
+	values := map[string]string{"order_id": "A1", "empty": ""}
+	captured := captureVariables(map[string]string{
+		"last_order": "order_id",
+		"blank":      "empty",
+		"missing":    "unknown",
+	}, values)
+
+	if len(captured) != 1 || captured["last_order"] != "A1" {
+		t.Errorf("captureVariables() = %v, want only last_order=A1", captured)
+	}
+}
+
+func TestExtractStateValues(t *testing.T) {
+	selectors := []Selector{
+		{Name: "current", Type: "state", Key: "step"},
+		{Name: "items", Type: "state", Key: "cart_count"},
+		{Name: "missing", Type: "state", Key: "unknown"},
+		{Name: "user", Type: "header", Key: "X-User"},
+	}
+	values := map[string]string{"user": "u1"}
+
+	ExtractStateValues(selectors, values, "cart", map[string]string{"cart_count": "4"})
+
+	if values["current"] != "cart" || values["items"] != "4" || values["missing"] != "" || values["user"] != "u1" {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if !matchAllConditions(values, []Condition{{Selector: "items", MatchType: "range", Value: "(3, 100]"}}) {
+		t.Error("expected state value to be usable in a range condition")
+	}
+}
+
+func TestExtractCounterValues(t *testing.T) {
+	selectors := []Selector{
+		{Name: "calls", Type: "counter", Key: "get_order"},
+		{Name: "user", Type: "header", Key: "X-User"},
+	}
+	values := map[string]string{"user": "u1"}
+	counters := map[string]int64{"get_order": 3}
+
+	ExtractCounterValues(selectors, values, func(name string) int64 { return counters[name] })
+
+	if values["calls"] != "3" || values["user"] != "u1" {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if !matchAllConditions(values, []Condition{{Selector: "calls", MatchType: "exact", Value: "3"}}) {
+		t.Error("expected nth-call condition to match")
+	}
+}
+
+func TestCounterName(t *testing.T) {
+	values := map[string]string{"id": "42", "user": "u1"}
+	tests := map[string]string{
+		"orders":             "orders",
+		"order:{{.id}}":      "order:42",
+		"{{.user}}/{{.id}}":  "u1/42",
+		"order:{{.missing}}": "order:",
+	}
+	for name, want := range tests {
+		if got := CounterName(name, values); got != want {
+			t.Errorf("CounterName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const mutateAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// applyMutations replaces the fields named by each mutation with random
+// values. Fields missing from the body are left alone, and bodies that are
+// not JSON are returned unchanged.
+func applyMutations(body []byte, mutations []config.Mutation) []byte {
+	if !gjson.ValidBytes(body) {
+		return body
+	}
+	for _, m := range mutations {
+		for _, path := range mutationPaths(body, m.JSONPath) {
+			if updated, err := sjson.SetBytes(body, path, mutationValue(m)); err == nil {
+				body = updated
+			}
+		}
+	}
+	return body
+}
+
+// mutationPaths turns a JSONPath such as "$.items[*].price" into the gjson
+// paths of the existing fields it names, e.g. "items.0.price"
+func mutationPaths(body []byte, jsonPath string) []string {
+	p := strings.TrimPrefix(jsonPath, "$")
+	p = strings.NewReplacer("[", ".", "]", "").Replace(p)
+	p = strings.TrimPrefix(p, ".")
+	if p == "" {
+		return nil
+	}
+
+	paths := []string{""}
+	for _, seg := range strings.Split(p, ".") {
+		var next []string
+		for _, prefix := range paths {
+			if seg != "*" {
+				next = append(next, joinPath(prefix, seg))
+				continue
+			}
+			n := int(gjson.GetBytes(body, joinPath(prefix, "#")).Int())
+			for i := 0; i < n; i++ {
+				next = append(next, joinPath(prefix, strconv.Itoa(i)))
+			}
+		}
+		paths = next
+	}
+
+	existing := paths[:0]
+	for _, path := range paths {
+		if gjson.GetBytes(body, path).Exists() {
+			existing = append(existing, path)
+		}
+	}
+	return existing
+}
+
+func joinPath(prefix, seg string) string {
+	if prefix == "" {
+		return seg
+	}
+	return prefix + "." + seg
+}
+
+func mutationValue(m config.Mutation) interface{} {
+	switch m.Type {
+	case config.MutateRandomInt:
+		lo, hi := int64(math.Ceil(m.Min)), int64(math.Floor(m.Max))
+		if hi <= lo {
+			return lo
+		}
+		return lo + rand.Int63n(hi-lo+1)
+	case config.MutateRandomFloat:
+		precision := 2
+		if m.Precision != nil {
+			precision = *m.Precision
+		}
+		scale := math.Pow(10, float64(precision))
+		return math.Round((m.Min+rand.Float64()*(m.Max-m.Min))*scale) / scale
+	case config.MutateRandomBool:
+		return rand.Intn(2) == 1
+	case config.MutateRandomString:
+		length := m.Length
+		if length <= 0 {
+			length = 8
+		}
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = mutateAlphabet[rand.Intn(len(mutateAlphabet))]
+		}
+		return string(b)
+	case config.MutateUUID:
+		return uuid.NewString()
+	case config.MutateOneOf:
+		if len(m.Values) > 0 {
+			return m.Values[rand.Intn(len(m.Values))]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"math"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyMutations(t *testing.T) {
+	body := []byte(`{"balance": 10.5, "name": "x", "items": [{"price": 1}, {"price": 2}], "flag": true}`)
+	precision := 1
+
+	out := applyMutations(body, []config.Mutation{
+		{JSONPath: "$.balance", Type: config.MutateRandomFloat, Min: 100, Max: 200, Precision: &precision},
+		{JSONPath: "$.items[*].price", Type: config.MutateRandomInt, Min: 5, Max: 9},
+		{JSONPath: "$.name", Type: config.MutateOneOf, Values: []interface{}{"a", "b"}},
+		{JSONPath: "$.missing", Type: config.MutateUUID},
+	})
+
+	if b := gjson.GetBytes(out, "balance").Float(); b < 100 || b > 200 || math.Abs(b*10-math.Round(b*10)) > 1e-9 {
+		t.Errorf("expected balance in [100,200] with one decimal, got %v", b)
+	}
+	for _, p := range gjson.GetBytes(out, "items.#.price").Array() {
+		if p.Int() < 5 || p.Int() > 9 {
+			t.Errorf("expected price in [5,9], got %v", p)
+		}
+	}
+	if n := gjson.GetBytes(out, "name").String(); n != "a" && n != "b" {
+		t.Errorf("expected name from values, got %q", n)
+	}
+	if gjson.GetBytes(out, "missing").Exists() {
+		t.Error("expected missing field to stay absent")
+	}
+	if !gjson.GetBytes(out, "flag").Bool() {
+		t.Error("expected untouched field to be kept")
+	}
+
+	plain := []byte("not json")
+	if got := applyMutations(plain, []config.Mutation{{JSONPath: "$.a", Type: config.MutateUUID}}); string(got) != "not json" {
+		t.Errorf("expected non-JSON body unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkContract validates a built response against the endpoint's OpenAPI
+// operation and returns the violations found
+func (h *MockHandler) checkContract(ct *config.ContractConfig, ep *config.Endpoint, result *ResponseResult) []string {
+	doc, err := h.responseBuilder.specs.Load(ct.Spec)
+	if err != nil {
+		return []string{fmt.Sprintf("contract spec %s: %v", ct.Spec, err)}
+	}
+
+	op, ok := doc.FindOperationByPath(ep.Method, ep.Path)
+	if ct.OperationID != "" {
+		op, ok = doc.FindOperation(ct.OperationID)
+	}
+	if !ok {
+		return []string{fmt.Sprintf("operation for %s %s not found in %s", ep.Method, ep.Path, ct.Spec)}
+	}
+
+	resp, ok := op.Response(result.StatusCode)
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented", result.StatusCode)}
+	}
+	if resp.Schema == nil || result.BodyFile != "" {
+		// streamed files are too large to validate in memory
+		return nil
+	}
+	var body interface{}
+	if err := json.Unmarshal(result.Body, &body); err != nil {
+		return []string{"body is not valid JSON: " + err.Error()}
+	}
+	return doc.Validate(resp.Schema, body)
+}
+
+// openAPIExample returns the JSON body and status of a spec example
+func (rb *ResponseBuilder) openAPIExample(ex *config.OpenAPIExample) ([]byte, int, error) {
+	doc, err := rb.specs.Load(ex.Spec)
+	if err != nil {
+		return nil, 0, err
+	}
+	op, ok := doc.FindOperation(ex.OperationID)
+	if !ok {
+		return nil, 0, fmt.Errorf("operation %s not found in %s", ex.OperationID, ex.Spec)
+	}
+
+	var resp openapi.Response
+	var body interface{}
+	if ex.Example != "" {
+		if resp, ok = op.ResponseWithExample(ex.Example); !ok {
+			return nil, 0, fmt.Errorf("example %s of operation %s not found in %s", ex.Example, ex.OperationID, ex.Spec)
+		}
+		body = resp.Examples[ex.Example]
+	} else {
+		if resp, ok = op.PrimaryResponse(); !ok {
+			return nil, 0, fmt.Errorf("operation %s documents no response", ex.OperationID)
+		}
+		body = doc.Example(resp)
+	}
+	if ex.Generate && resp.Schema != nil {
+		body = doc.Generate(resp.Schema, newRand())
+	}
+
+	data, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, resp.Status, nil
+}
+
+// serveFromSchema answers an unmatched request documented by one of the
+// schema_fallback specs with a body generated from its response schema
+func (h *MockHandler) serveFromSchema(c *gin.Context, cfg *config.Config) bool {
+	for _, spec := range cfg.Server.SchemaFallback.Specs {
+		doc, err := h.responseBuilder.specs.Load(spec)
+		if err != nil {
+			continue
+		}
+		op, ok := doc.MatchOperation(c.Request.Method, c.Request.URL.Path)
+		if !ok {
+			continue
+		}
+		resp, _ := op.PrimaryResponse()
+		status := resp.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		c.Set("matched_rule", "schema_fallback")
+		if resp.Schema == nil {
+			c.Status(status)
+			return true
+		}
+		c.JSON(status, doc.Generate(resp.Schema, newRand()))
+		return true
+	}
+	return false
+}
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// respondContractViolation replaces a response that broke its contract
+func respondContractViolation(c *gin.Context, violations []string) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": gin.H{
+			"code":       "CONTRACT_VIOLATION",
+			"message":    "The mock response does not match its OpenAPI contract",
+			"violations": violations,
+		},
+	})
+}
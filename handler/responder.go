@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/responder"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultResponderTimeout bounds responders that configure no timeout_ms
+const defaultResponderTimeout = 5 * time.Second
+
+// errExecDisabled fails command responders unless the server opted in
+var errExecDisabled = errors.New("responder commands are disabled, see server.allow_exec_responders")
+
+// runResponder produces the response with an external program or HTTP hook
+func (h *MockHandler) runResponder(c *gin.Context, rc *config.ResponderConfig, respCfg ResponseBuildConfig, values, pathParams map[string]string, body []byte) (*ResponseResult, error) {
+	timeout := defaultResponderTimeout
+	if rc.TimeoutMs > 0 {
+		timeout = time.Duration(rc.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	query, headers := flatQueryAndHeaders(c)
+	req := responder.Request{
+		Method:  c.Request.Method,
+		Path:    c.Request.URL.Path,
+		Query:   query,
+		Headers: headers,
+		Params:  pathParams,
+		Values:  values,
+		Body:    string(body),
+	}
+
+	var resp *responder.Response
+	var err error
+	if len(rc.Command) > 0 {
+		if cfg := h.configManager.GetConfig(); cfg == nil || !cfg.Server.AllowExecResponders {
+			return nil, errExecDisabled
+		}
+		resp, err = responder.Exec(ctx, rc.Command, req)
+	} else {
+		resp, err = responder.Call(ctx, h.hookClient, rc.URL, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return externalResult(respCfg, resp.Status, resp.Headers, resp.BodyBytes()), nil
+}
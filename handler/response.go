@@ -1,24 +1,33 @@
 package handler
 
 import (
+	"fmt"
 	"math/rand"
+	"net/http"
 	"os"
 	"time"
 
+	"mock-api-server/config"
+	"mock-api-server/pkg/openapi"
 	"mock-api-server/pkg/template"
+
+	"github.com/gin-gonic/gin"
 )
 
 // ResponseBuilder builds HTTP responses
-type ResponseBuilder struct{}
+type ResponseBuilder struct {
+	specs *openapi.Cache // OpenAPI documents of spec examples and contracts
+}
 
 // NewResponseBuilder creates a new ResponseBuilder
 func NewResponseBuilder() *ResponseBuilder {
-	return &ResponseBuilder{}
+	return &ResponseBuilder{specs: openapi.NewCache()}
 }
 
 // ResponseResult contains the built response data
 type ResponseResult struct {
 	Body       []byte
+	BodyFile   string // streamed from disk instead of Body when set
 	StatusCode int
 	Headers    map[string]string
 	DelayMs    int
@@ -40,6 +49,10 @@ type ResponseBuildConfig struct {
 	Headers         map[string]string
 	TemplateEnabled bool
 	RandomResponses []RandomResponseConfig
+	Mutate          []config.Mutation
+	FromOpenAPI     *config.OpenAPIExample
+	MaxFileBytes    int64 // larger response files are streamed, 0 means no limit
+	StrictFileSize  bool  // refuse larger response files instead of streaming them
 }
 
 // Build builds a response based on configuration and extracted values
@@ -58,17 +71,40 @@ func (rb *ResponseBuilder) Build(cfg ResponseBuildConfig, values map[string]stri
 
 	// Read response file
 	if cfg.ResponseFile != "" {
-		content, err := os.ReadFile(cfg.ResponseFile)
+		large, err := isLargeFile(cfg.ResponseFile, cfg.MaxFileBytes)
 		if err != nil {
 			return nil, err
 		}
-		result.Body = content
+		switch {
+		case large && cfg.StrictFileSize:
+			return nil, fmt.Errorf("response file %s exceeds max_response_file_bytes %d", cfg.ResponseFile, cfg.MaxFileBytes)
+		case large:
+			result.BodyFile = cfg.ResponseFile
+		default:
+			content, err := os.ReadFile(cfg.ResponseFile)
+			if err != nil {
+				return nil, err
+			}
+			result.Body = content
+		}
+	} else if cfg.FromOpenAPI != nil {
+		body, status, err := rb.openAPIExample(cfg.FromOpenAPI)
+		if err != nil {
+			return nil, err
+		}
+		result.Body = body
+		if cfg.StatusCode == 0 {
+			cfg.StatusCode = status
+		}
 	}
 
 	// Apply template substitution
 	if cfg.TemplateEnabled && len(result.Body) > 0 {
 		result.Body = template.ReplaceVariables(result.Body, values)
 	}
+	if len(cfg.Mutate) > 0 && len(result.Body) > 0 {
+		result.Body = applyMutations(result.Body, cfg.Mutate)
+	}
 
 	// Set status code
 	result.StatusCode = cfg.StatusCode
@@ -92,6 +128,42 @@ func (rb *ResponseBuilder) Build(cfg ResponseBuildConfig, values map[string]stri
 	return result, nil
 }
 
+// isLargeFile reports whether the file at path is over limit bytes
+func isLargeFile(path string, limit int64) (bool, error) {
+	if limit <= 0 {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return info.Size() > limit, nil
+}
+
+// write sends the result's status, headers and body, streaming BodyFile
+// from disk when set
+func (r *ResponseResult) write(c *gin.Context) {
+	for k, v := range r.Headers {
+		c.Header(k, v)
+	}
+	if r.BodyFile == "" {
+		c.Data(r.StatusCode, r.Headers["Content-Type"], r.Body)
+		return
+	}
+	f, err := os.Open(r.BodyFile)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.DataFromReader(r.StatusCode, info.Size(), r.Headers["Content-Type"], f, nil)
+}
+
 // selectRandomResponse selects a random response based on weights
 func selectRandomResponse(responses []RandomResponseConfig) RandomResponseConfig {
 	if len(responses) == 0 {
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBuildStreamsLargeResponseFiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "big.json")
+	body := `{"items":["{{.id}}"]}`
+	if err := os.WriteFile(file, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rb := NewResponseBuilder()
+	values := map[string]string{"id": "42"}
+
+	small, err := rb.Build(ResponseBuildConfig{ResponseFile: file, TemplateEnabled: true, MaxFileBytes: 1 << 20}, values)
+	if err != nil || small.BodyFile != "" || string(small.Body) != `{"items":["42"]}` {
+		t.Fatalf("expected small file read and templated, got %q / %q, err %v", small.Body, small.BodyFile, err)
+	}
+
+	large, err := rb.Build(ResponseBuildConfig{ResponseFile: file, TemplateEnabled: true, MaxFileBytes: 8}, values)
+	if err != nil || large.BodyFile != file || large.Body != nil {
+		t.Fatalf("expected large file streamed, got %q / %q, err %v", large.Body, large.BodyFile, err)
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	large.write(c)
+	if w.Code != http.StatusOK || w.Body.String() != body {
+		t.Errorf("expected streamed file unchanged, got %d %q", w.Code, w.Body.String())
+	}
+
+	if _, err := rb.Build(ResponseBuildConfig{ResponseFile: file, MaxFileBytes: 8, StrictFileSize: true}, values); err == nil {
+		t.Error("expected strict mode to refuse the large file")
+	}
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeMethods are the methods crud, sink and ANY endpoints are routed for,
+// as with gin's Any
+var routeMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodHead,
+	http.MethodOptions, http.MethodDelete, http.MethodConnect, http.MethodTrace,
+}
+
+// routeTable serves the enabled endpoints of one config through concrete
+// gin routes. Routes cannot be removed from a gin engine, so each config,
+// and each change of the disabled endpoints, gets an engine of its own that
+// replaces the previous one at once.
+type routeTable struct {
+	cfg      *config.Config
+	disabled uint64 // DisabledGeneration the table was built for
+	engine   *gin.Engine
+}
+
+// route lists the endpoints registered for one method and path, those with
+// more literal segments first, then specific methods before ANY, then in
+// config order. The first whose literal segments match serves the request.
+type route struct {
+	targets []routeTarget
+}
+
+// routeTarget is an endpoint of a route. Path parameters are registered by
+// position (":p1"), so endpoints naming a segment differently share a route.
+// Segments gin would read as wildcards, like "things:batch", are registered
+// as parameters too and compared in matches.
+type routeTarget struct {
+	ep       *config.Endpoint
+	generic  bool              // crud, sink and ANY endpoints accept every method
+	names    map[string]string // registered name -> endpoint parameter name
+	literals map[string]string // registered name -> required segment
+}
+
+// dispatch carries a mock request into the route engine: the keys of the
+// outer context, which the route handlers share, or for lookups the match
+// to report instead of serving
+type dispatch struct {
+	keys  map[any]any
+	match *routeMatch
+}
+
+type routeMatch struct {
+	ep     *config.Endpoint
+	params map[string]string
+}
+
+type dispatchKey struct{}
+
+// routes returns the route table of cfg with the endpoints enabled now,
+// building it when either changed
+func (h *MockHandler) routes(cfg *config.Config) *routeTable {
+	disabled := h.configManager.DisabledGeneration()
+	rt := h.router.Load()
+	if rt == nil || rt.cfg != cfg || rt.disabled != disabled {
+		rt = h.newRouteTable(cfg, disabled)
+		h.router.Store(rt)
+	}
+	return rt
+}
+
+func (h *MockHandler) newRouteTable(cfg *config.Config, disabled uint64) *routeTable {
+	engine := gin.New()
+	// Trailing slashes are trimmed before routing, see serve
+	engine.RedirectTrailingSlash = false
+	engine.RedirectFixedPath = false
+	engine.RemoveExtraSlash = true
+	engine.HandleMethodNotAllowed = cfg.Server.ErrorHandling.MethodNotAllowed
+	engine.Use(shareKeys)
+	engine.NoRoute(h.routeNotFound(cfg))
+	engine.NoMethod(h.routeMethodNotAllowed(cfg))
+
+	routes := make(map[string]*route)
+	add := func(method, pattern string, ep *config.Endpoint, generic bool) {
+		path, names, literals := routePath(pattern)
+		target := routeTarget{ep: ep, generic: generic, names: names, literals: literals}
+		key := method + " " + path
+		if r, exists := routes[key]; exists {
+			r.targets = append(r.targets, target)
+			return
+		}
+		r := &route{targets: []routeTarget{target}}
+		routes[key] = r
+		engine.Handle(method, path, h.serveRoute(cfg, r))
+	}
+
+	var generic []*config.Endpoint
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		if !h.configManager.IsEndpointEnabled(ep.ID) {
+			continue
+		}
+		if isGenericEndpoint(ep) {
+			generic = append(generic, ep)
+			continue
+		}
+		add(strings.ToUpper(ep.Method), ep.Path, ep, false)
+	}
+	for _, ep := range generic {
+		for _, method := range routeMethods {
+			add(method, ep.Path, ep, true)
+			if ep.Mode == config.EndpointModeCRUD {
+				add(method, strings.TrimRight(ep.Path, "/")+"/:"+crudItemParam, ep, true)
+			}
+		}
+	}
+	for _, r := range routes {
+		slices.SortStableFunc(r.targets, func(a, b routeTarget) int {
+			if n := len(b.literals) - len(a.literals); n != 0 {
+				return n
+			}
+			switch {
+			case a.generic == b.generic:
+				return 0
+			case b.generic:
+				return -1
+			}
+			return 1
+		})
+	}
+
+	return &routeTable{cfg: cfg, disabled: disabled, engine: engine}
+}
+
+// isGenericEndpoint reports whether ep answers every method
+func isGenericEndpoint(ep *config.Endpoint) bool {
+	return ep.Mode == config.EndpointModeCRUD || ep.Mode == config.EndpointModeSink || strings.EqualFold(ep.Method, config.MethodAny)
+}
+
+// routePath converts an endpoint path to a gin path with positional
+// parameters, returning the endpoint's name of each parameter and the
+// segments that must match literally
+func routePath(pattern string) (path string, names, literals map[string]string) {
+	segments := splitPath(pattern)
+	for i, seg := range segments {
+		key := "p" + strconv.Itoa(i)
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			if names == nil {
+				names = make(map[string]string)
+			}
+			names[key] = seg[1:]
+		case strings.ContainsAny(seg, ":*"):
+			if literals == nil {
+				literals = make(map[string]string)
+			}
+			literals[key] = seg
+		default:
+			continue
+		}
+		segments[i] = ":" + key
+	}
+	return "/" + strings.Join(segments, "/"), names, literals
+}
+
+// splitPath splits a path into segments, ignoring surrounding slashes
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(p, "/"), "/")
+}
+
+// match returns the endpoint's path parameters from the registered ones,
+// or false when a literal segment differs
+func (t *routeTarget) match(params gin.Params) (map[string]string, bool) {
+	values := make(map[string]string, len(t.names))
+	for _, p := range params {
+		if literal, ok := t.literals[p.Key]; ok {
+			if p.Value != literal {
+				return nil, false
+			}
+			continue
+		}
+		values[t.names[p.Key]] = p.Value
+	}
+	return values, true
+}
+
+// serve routes req through the engine, writing the response to w. The
+// request is passed on with d; a trailing slash is trimmed so "/users/"
+// finds the "/users" endpoint.
+func (rt *routeTable) serve(w http.ResponseWriter, req *http.Request, d *dispatch) {
+	req = req.WithContext(context.WithValue(req.Context(), dispatchKey{}, d))
+	if p := req.URL.Path; len(p) > 1 && strings.HasSuffix(p, "/") {
+		u := *req.URL
+		u.Path, u.RawPath = strings.TrimRight(p, "/"), ""
+		req.URL = &u
+	}
+	rt.engine.ServeHTTP(w, req)
+}
+
+// lookup returns the endpoint serving req and its path parameters without
+// serving it, or nil when no endpoint does
+func (rt *routeTable) lookup(req *http.Request) (*config.Endpoint, map[string]string) {
+	m := &routeMatch{}
+	rt.serve(discardWriter{header: http.Header{}}, req, &dispatch{match: m})
+	return m.ep, m.params
+}
+
+// dispatchOf returns the dispatch c was routed with
+func dispatchOf(c *gin.Context) *dispatch {
+	d, _ := c.Request.Context().Value(dispatchKey{}).(*dispatch)
+	if d == nil {
+		d = &dispatch{}
+	}
+	return d
+}
+
+// shareKeys makes the route engine's context share the keys of the outer
+// one, so values set while serving reach the middlewares around it
+func shareKeys(c *gin.Context) {
+	if d := dispatchOf(c); d.keys != nil {
+		c.Keys = d.keys
+	}
+}
+
+// serveRoute serves the first target of r matching the request
+func (h *MockHandler) serveRoute(cfg *config.Config, r *route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := dispatchOf(c)
+		for i := range r.targets {
+			target := &r.targets[i]
+			params, ok := target.match(c.Params)
+			if !ok {
+				continue
+			}
+			if d.match != nil {
+				d.match.ep, d.match.params = target.ep, params
+				return
+			}
+			c.Params = c.Params[:0]
+			h.serveEndpoint(c, cfg, target.ep, params)
+			return
+		}
+		if d.match == nil {
+			h.handleUnmatched(c, cfg)
+		}
+	}
+}
+
+// routeNotFound answers requests no route matches
+func (h *MockHandler) routeNotFound(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if dispatchOf(c).match == nil {
+			h.handleUnmatched(c, cfg)
+		}
+	}
+}
+
+// routeMethodNotAllowed answers requests for a routed path with a method
+// none of its endpoints accepts, unless an OpenAPI spec documents them
+func (h *MockHandler) routeMethodNotAllowed(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if dispatchOf(c).match != nil {
+			return
+		}
+		// gin lists the allowed methods in registration order
+		allowed := strings.Split(c.Writer.Header().Get("Allow"), ", ")
+		c.Writer.Header().Del("Allow")
+		if h.serveFromSchema(c, cfg) {
+			return
+		}
+		sort.Strings(allowed)
+		h.handleMethodNotAllowed(c, cfg, allowed)
+	}
+}
+
+// discardWriter swallows what the route engine writes for lookups
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header       { return w.header }
+func (discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardWriter) WriteHeader(int)             {}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatchEndpointRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "any_user", Path: "/users/:name", Method: config.MethodAny},
+		{ID: "user", Path: "/users/:id", Method: "GET"},
+		{ID: "orders", Path: "/users/:uid/orders/:order", Method: "GET"},
+		{ID: "me", Path: "/users/me", Method: "GET"},
+		{ID: "items", Path: "/items", Mode: config.EndpointModeCRUD},
+		{ID: "odd", Path: "/v1/things:batch", Method: "POST"},
+	}})
+	h := NewMockHandler(cm, nil, nil)
+
+	for _, tt := range []struct {
+		method, path string
+		wantID       string
+		wantParams   map[string]string
+	}{
+		{"GET", "/users/42", "user", map[string]string{"id": "42"}},
+		{"DELETE", "/users/42", "any_user", map[string]string{"name": "42"}},
+		{"GET", "/users/me", "me", map[string]string{}},
+		{"GET", "/users/7/orders/9", "orders", map[string]string{"uid": "7", "order": "9"}},
+		{"GET", "/users/42/", "user", map[string]string{"id": "42"}},
+		{"PATCH", "/users/me", "any_user", map[string]string{"name": "me"}},
+		{"PUT", "/items/3", "items", map[string]string{crudItemParam: "3"}},
+		{"POST", "/v1/things:batch", "odd", map[string]string{}},
+		{"GET", "/missing", "", nil},
+	} {
+		ep, params := h.matchEndpoint(cm.GetConfig(), httptest.NewRequest(tt.method, tt.path, nil))
+		var id string
+		if ep != nil {
+			id = ep.ID
+		}
+		if id != tt.wantID {
+			t.Errorf("%s %s: matched %q, want %q", tt.method, tt.path, id, tt.wantID)
+			continue
+		}
+		if len(params) != len(tt.wantParams) {
+			t.Errorf("%s %s: params %v, want %v", tt.method, tt.path, params, tt.wantParams)
+		}
+		for k, v := range tt.wantParams {
+			if params[k] != v {
+				t.Errorf("%s %s: param %s = %q, want %q", tt.method, tt.path, k, params[k], v)
+			}
+		}
+	}
+
+	// Disabled endpoints give way to the next endpoint on the same route
+	cm.SetEndpointEnabled("user", false)
+	if ep, _ := h.matchEndpoint(cm.GetConfig(), httptest.NewRequest("GET", "/users/42", nil)); ep == nil || ep.ID != "any_user" {
+		t.Errorf("expected any_user once user is disabled, got %v", ep)
+	}
+}
+
+func TestRoutesFollowConfigChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "name", Path: "/:name", Method: "GET", Default: config.ResponseConfig{StatusCode: http.StatusAccepted}},
+	}})
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	if code := serve("/health"); code != http.StatusNoContent {
+		t.Errorf("/health: status %d", code)
+	}
+	if code := serve("/alice"); code != http.StatusAccepted {
+		t.Errorf("/alice: status %d", code)
+	}
+
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "users", Path: "/users", Method: "GET", Default: config.ResponseConfig{StatusCode: http.StatusCreated}},
+	}})
+	if code := serve("/users"); code != http.StatusCreated {
+		t.Errorf("/users after reload: status %d", code)
+	}
+	if code := serve("/alice"); code != http.StatusNotFound {
+		t.Errorf("/alice after reload: status %d", code)
+	}
+}
+
+func TestRouteEngineSharesContextKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "user", Path: "/users/:id", Method: "GET"},
+		{ID: "odd", Path: "/files/*", Method: "GET"},
+		{ID: "root", Path: "/", Method: "GET"},
+		{ID: "unnamed", Path: "/:", Method: "POST"},
+	}})
+	var endpointID any
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		endpointID, _ = c.Get("endpoint_id")
+	})
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	for _, tt := range []struct{ method, path, wantID string }{
+		{"GET", "/users/42", "user"},
+		{"GET", "/files/*", "odd"},
+		{"GET", "/files/x", ""},
+		{"GET", "/", "root"},
+		{"POST", "/anything", "unnamed"},
+	} {
+		endpointID = nil
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if got, _ := endpointID.(string); got != tt.wantID {
+			t.Errorf("%s %s: endpoint_id %q, want %q", tt.method, tt.path, got, tt.wantID)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{Endpoints: []config.Endpoint{
+		{ID: "get_user", Path: "/users/:id", Method: "GET"},
+		{ID: "put_user", Path: "/users/:uid", Method: "PUT"},
+		{ID: "delete_user", Path: "/users/:id", Method: "DELETE"},
+	}}
+	cfg.Server.ErrorHandling.MethodNotAllowed = true
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(cfg)
+	cm.SetEndpointEnabled("delete_user", false)
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+	w := serve(http.MethodPost, "/users/1")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("POST /users/1: status %d, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+	if w := serve(http.MethodPost, "/orders"); w.Code != http.StatusNotFound {
+		t.Errorf("POST /orders: status %d, want 404", w.Code)
+	}
+
+	cfg.Server.ErrorHandling.MethodNotAllowed = false
+	cm.SetConfig(cfg)
+	if w := serve(http.MethodPost, "/users/1"); w.Code != http.StatusNotFound || w.Header().Get("Allow") != "" {
+		t.Errorf("with method_not_allowed off: status %d, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestPreflightMethods(t *testing.T) {
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{
+		{ID: "get_user", Path: "/users/:id", Method: "GET"},
+		{ID: "put_user", Path: "/users/:id", Method: "PUT"},
+		{ID: "delete_user", Path: "/users/:id", Method: "DELETE"},
+		{ID: "events", Path: "/events", Method: "ANY"},
+		{ID: "report", Path: "/report", Method: "GET"},
+		{ID: "report_options", Path: "/report", Method: "OPTIONS"},
+	}})
+	cm.SetEndpointEnabled("delete_user", false)
+	h := NewMockHandler(cm, nil, nil)
+
+	preflight := func(path string) ([]string, bool) {
+		return h.PreflightMethods(httptest.NewRequest(http.MethodOptions, path, nil))
+	}
+	if methods, ok := preflight("/users/1"); !ok || len(methods) != 2 || methods[0] != "GET" || methods[1] != "PUT" {
+		t.Errorf("/users/1: got %v %v, want [GET PUT]", methods, ok)
+	}
+	if methods, ok := preflight("/events"); !ok || len(methods) != len(anyMethods) {
+		t.Errorf("/events: got %v %v, want every method", methods, ok)
+	}
+	if _, ok := preflight("/report"); ok {
+		t.Error("/report: expected the OPTIONS endpoint to answer the preflight")
+	}
+	if _, ok := preflight("/missing"); ok {
+		t.Error("/missing: expected no preflight for unknown paths")
+	}
+}
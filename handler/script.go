@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/script"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runScript produces the response from a Lua script. Status and headers the
+// script leaves out come from the response config.
+func runScript(c *gin.Context, sc *config.ScriptConfig, respCfg ResponseBuildConfig, values, pathParams map[string]string, body []byte) (*ResponseResult, error) {
+	source, name, err := scriptSource(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	query, headers := flatQueryAndHeaders(c)
+	req := script.Request{
+		Method:  c.Request.Method,
+		Path:    c.Request.URL.Path,
+		Query:   query,
+		Headers: headers,
+		Params:  pathParams,
+		Values:  values,
+		Body:    string(body),
+	}
+
+	resp, err := script.Run(source, name, req, time.Duration(sc.TimeoutMs)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	return externalResult(respCfg, resp.Status, resp.Headers, resp.Body), nil
+}
+
+// scriptSource returns the source of sc and the name errors refer to it by
+func scriptSource(sc *config.ScriptConfig) (string, string, error) {
+	if sc.File == "" {
+		return sc.Source, "inline script", nil
+	}
+	data, err := os.ReadFile(sc.File)
+	if err != nil {
+		return "", "", err
+	}
+	return string(data), sc.File, nil
+}
+
+// flatQueryAndHeaders joins repeated query parameters and headers into
+// single strings for scripts and responders
+func flatQueryAndHeaders(c *gin.Context) (map[string]string, map[string]string) {
+	query := make(map[string]string)
+	for k, v := range c.Request.URL.Query() {
+		query[k] = strings.Join(v, ",")
+	}
+	headers := make(map[string]string)
+	for k, v := range c.Request.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
+	return query, headers
+}
+
+// externalResult combines a response produced by a script or responder with
+// the status, headers and delay of the response config
+func externalResult(respCfg ResponseBuildConfig, status int, headers map[string]string, body []byte) *ResponseResult {
+	result := &ResponseResult{
+		Body:       body,
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		DelayMs:    respCfg.DelayMs,
+	}
+	if result.StatusCode == 0 {
+		result.StatusCode = respCfg.StatusCode
+	}
+	if result.StatusCode == 0 {
+		result.StatusCode = 200
+	}
+	for k, v := range respCfg.Headers {
+		result.Headers[k] = v
+	}
+	for k, v := range headers {
+		result.Headers[k] = v
+	}
+	return result
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,11 +12,16 @@ import (
 
 // ExtractValues extracts values from request based on selectors
 func ExtractValues(c *gin.Context, selectors []Selector, pathParams map[string]string) map[string]string {
-	values := make(map[string]string)
+	return extractValues(c, selectors, pathParams, nil)
+}
+
+// extractValues is ExtractValues for a body already read by the caller; a
+// nil body is read from the request when a body selector needs it
+func extractValues(c *gin.Context, selectors []Selector, pathParams map[string]string, bodyBytes []byte) map[string]string {
+	values := make(map[string]string, len(selectors))
 
 	// Read body once
-	var bodyBytes []byte
-	var bodyRead bool
+	bodyRead := bodyBytes != nil
 
 	for _, sel := range selectors {
 		var value string
@@ -53,6 +60,51 @@ func ExtractValues(c *gin.Context, selectors []Selector, pathParams map[string]s
 	return values
 }
 
+// StateStepKey is the key of a state selector that reads the current scenario step
+const StateStepKey = "step"
+
+// ExtractStateValues fills "state" selectors from the endpoint's scenario
+// partition: key "step" reads the current step, any other key reads the
+// scenario variable of that name. Other selector types are left untouched.
+func ExtractStateValues(selectors []Selector, values map[string]string, step string, vars map[string]string) {
+	for _, sel := range selectors {
+		if !strings.EqualFold(sel.Type, "state") {
+			continue
+		}
+		if sel.Key == StateStepKey {
+			values[sel.Name] = step
+		} else {
+			values[sel.Name] = vars[sel.Key]
+		}
+	}
+}
+
+// ExtractCounterValues fills "counter" selectors with the value of the
+// counter named by their key, as returned by get. Placeholders in the key
+// are expanded with CounterName.
+func ExtractCounterValues(selectors []Selector, values map[string]string, get func(name string) int64) {
+	for _, sel := range selectors {
+		if strings.EqualFold(sel.Type, "counter") {
+			values[sel.Name] = strconv.FormatInt(get(CounterName(sel.Key, values)), 10)
+		}
+	}
+}
+
+var counterPlaceholder = regexp.MustCompile(`\{\{\.([^}]+)\}\}`)
+
+// CounterName expands {{.selector}} placeholders in a counter name with the
+// selector values, so counters can be kept per id: a POST incrementing
+// "order:{{.id}}" is seen by a GET reading the counter "order:{{.id}}" with
+// the same id. Unknown selectors expand to "".
+func CounterName(name string, values map[string]string) string {
+	if !strings.Contains(name, "{{") {
+		return name
+	}
+	return counterPlaceholder.ReplaceAllStringFunc(name, func(m string) string {
+		return values[counterPlaceholder.FindStringSubmatch(m)[1]]
+	})
+}
+
 // Selector represents a selector configuration
 type Selector struct {
 	Name string
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/state"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sinks returns the requests recorded by sink endpoints
+func (h *MockHandler) Sinks() *state.SinkStore {
+	return h.sinks
+}
+
+// handleSink records the request and answers with the endpoint's default
+// response, whatever the method or body
+func (h *MockHandler) handleSink(c *gin.Context, cfg *config.Config, ep *config.Endpoint, pathParams map[string]string) {
+	c.Set("matched_rule", "sink")
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil && middleware.IsBodyTooLarge(err) {
+		middleware.AbortBodyTooLarge(c, cfg)
+		return
+	}
+
+	headers := make(map[string]string, len(c.Request.Header))
+	for k, v := range c.Request.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
+	maxEntries := 0
+	if ep.Sink != nil {
+		maxEntries = ep.Sink.MaxEntries
+	}
+	h.sinks.Record(ep.ID, state.SinkEntry{
+		Time:      time.Now(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Query:     c.Request.URL.RawQuery,
+		Headers:   headers,
+		Body:      string(body),
+		ClientIP:  c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	}, maxEntries)
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	def := ep.DefaultFor(c.Request.Method)
+	values := ExtractValues(c, toSelectors(ep), pathParams)
+	result, err := h.responseBuilder.Build(ResponseBuildConfig{
+		ResponseFile:    def.ResponseFile,
+		StatusCode:      def.StatusCode,
+		DelayMs:         def.DelayMs,
+		Headers:         def.Headers,
+		TemplateEnabled: def.Template != nil && def.Template.Enabled,
+		Mutate:          def.Mutate,
+		FromOpenAPI:     def.FromOpenAPI,
+		MaxFileBytes:    cfg.Server.MaxResponseFileBytes,
+		StrictFileSize:  cfg.Server.StrictResponseFileSize,
+	}, values)
+	if err != nil {
+		h.handleError(c, cfg, err)
+		return
+	}
+
+	ApplyDelay(result.DelayMs)
+	result.write(c)
+}
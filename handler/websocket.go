@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"maps"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/pkg/script"
+	"mock-api-server/pkg/template"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+)
+
+// wsUpgrader accepts connections from any origin, like the mock's CORS
+// handling, since clients under test run on arbitrary hosts
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsRule is a websocket message rule with its match condition compiled
+type wsRule struct {
+	msg  *config.WSMessage
+	cond Condition
+}
+
+func toWSRules(messages []config.WSMessage) []wsRule {
+	rules := make([]wsRule, len(messages))
+	for i := range messages {
+		matchType := messages[i].MatchType
+		if matchType == "" {
+			matchType = "exact"
+		}
+		rules[i] = wsRule{
+			msg:  &messages[i],
+			cond: Condition{MatchType: matchType, Value: messages[i].Value},
+		}
+		compileCondition(&rules[i].cond)
+	}
+	return rules
+}
+
+// matchWSRule returns the first rule matching message, with the groups of a
+// regex rule as match.1, match.2, ...
+func matchWSRule(rules []wsRule, message string) (*wsRule, map[string]string) {
+	for i := range rules {
+		rule := &rules[i]
+		target := message
+		if rule.msg.Path != "" {
+			target = gjson.Get(message, rule.msg.Path).String()
+		}
+		if !matchCondition(target, rule.cond) {
+			continue
+		}
+		groups := make(map[string]string)
+		if rule.cond.regex != nil {
+			for n, group := range rule.cond.regex.FindStringSubmatch(target) {
+				if n > 0 {
+					groups["match."+strconv.Itoa(n)] = group
+				}
+			}
+		}
+		return rule, groups
+	}
+	return nil, nil
+}
+
+// wsSession is one client connection. Replies and push frames are written
+// from different goroutines, so writes hold mu.
+type wsSession struct {
+	conn       *websocket.Conn
+	mu         sync.Mutex
+	done       chan struct{} // closed when the client disconnects
+	values     map[string]string
+	pathParams map[string]string
+	method     string
+	path       string
+	query      map[string]string
+	headers    map[string]string
+}
+
+// handleWebSocket upgrades the request and answers the client's frames by
+// the endpoint's message rules until it disconnects
+func (h *MockHandler) handleWebSocket(c *gin.Context, cfg *config.Config, ep *config.Endpoint, pathParams map[string]string) {
+	c.Set("matched_rule", "websocket")
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		middleware.AbortWithError(c, cfg, http.StatusUpgradeRequired, gin.H{
+			"code":    "UPGRADE_REQUIRED",
+			"message": "This endpoint only accepts websocket connections",
+		})
+		return
+	}
+	ws := ep.WebSocket
+	if ws == nil {
+		ws = &config.WSConfig{}
+	}
+
+	compiled := h.compiled.get(cfg, ep)
+	values := extractValues(c, compiled.selectors, pathParams, nil)
+	h.addServerValues(c, cfg, ep, values)
+	query, headers := flatQueryAndHeaders(c)
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // the upgrader has answered with an HTTP error
+	}
+	defer conn.Close()
+	// The endpoint's own slot is kept for the connection's lifetime, so its
+	// max_concurrent_requests still caps open sockets
+	middleware.ReleaseConcurrency(c)
+	if cfg.Server.MaxRequestBodyBytes > 0 {
+		conn.SetReadLimit(cfg.Server.MaxRequestBodyBytes)
+	}
+
+	s := &wsSession{
+		conn:       conn,
+		done:       make(chan struct{}),
+		values:     values,
+		pathParams: pathParams,
+		method:     c.Request.Method,
+		path:       c.Request.URL.Path,
+		query:      query,
+		headers:    headers,
+	}
+	var pushers sync.WaitGroup
+	defer pushers.Wait()
+	defer close(s.done)
+
+	if !s.send(ws.OnConnect, values, "") {
+		return
+	}
+	for _, push := range ws.Push {
+		pushers.Add(1)
+		go func() {
+			defer pushers.Done()
+			s.push(push)
+		}()
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		message := string(data)
+		frameValues := s.with(map[string]string{"message": message})
+		replies := ws.Default
+		rule, groups := matchWSRule(compiled.wsRules, message)
+		if rule != nil {
+			maps.Copy(frameValues, groups)
+			replies = rule.msg.Reply
+		}
+		if !s.send(replies, frameValues, message) {
+			return
+		}
+		if rule != nil && rule.msg.Close {
+			s.close(websocket.CloseNormalClosure, "")
+			return
+		}
+	}
+}
+
+// push sends p's frame on its interval until the count is reached or the
+// client disconnects
+func (s *wsSession) push(p config.WSPush) {
+	if p.IntervalMs <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(p.IntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+	for seq := 1; p.Count == 0 || seq <= p.Count; seq++ {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		values := s.with(map[string]string{"seq": strconv.Itoa(seq)})
+		if !s.send([]config.WSFrame{p.WSFrame}, values, "") {
+			return
+		}
+	}
+}
+
+// send renders and writes frames in order after their delays. It reports
+// false once the connection can no longer be used.
+func (s *wsSession) send(frames []config.WSFrame, values map[string]string, message string) bool {
+	for _, f := range frames {
+		if f.DelayMs > 0 {
+			select {
+			case <-s.done:
+				return false
+			case <-time.After(time.Duration(f.DelayMs) * time.Millisecond):
+			}
+		}
+		data, err := s.render(f, values, message)
+		if err != nil {
+			s.close(websocket.CloseInternalServerErr, "frame failed")
+			return false
+		}
+		s.mu.Lock()
+		err = s.conn.WriteMessage(websocket.TextMessage, data)
+		s.mu.Unlock()
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// render produces the content of f. A script is handed message as the
+// request body and its response body is the frame.
+func (s *wsSession) render(f config.WSFrame, values map[string]string, message string) ([]byte, error) {
+	if f.Script != nil {
+		source, name, err := scriptSource(f.Script)
+		if err != nil {
+			return nil, err
+		}
+		req := script.Request{
+			Method:  s.method,
+			Path:    s.path,
+			Query:   s.query,
+			Headers: s.headers,
+			Params:  s.pathParams,
+			Values:  values,
+			Body:    message,
+		}
+		resp, err := script.Run(source, name, req, time.Duration(f.Script.TimeoutMs)*time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Body, nil
+	}
+
+	content := []byte(f.Body)
+	if f.File != "" {
+		data, err := os.ReadFile(f.File)
+		if err != nil {
+			return nil, err
+		}
+		content = data
+	}
+	return template.ReplaceVariables(content, values), nil
+}
+
+// close sends a close frame; the read loop ends when the client answers
+func (s *wsSession) close(code int, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+}
+
+// with returns the connection's values extended by extra
+func (s *wsSession) with(extra map[string]string) map[string]string {
+	values := maps.Clone(s.values)
+	maps.Copy(values, extra)
+	return values
+}
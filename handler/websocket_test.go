@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/middleware"
+	"mock-api-server/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestHandleWebSocket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Endpoints: []config.Endpoint{{
+		ID: "ticker", Path: "/ws/:room", Method: "GET", Mode: config.EndpointModeWebSocket,
+		Selectors: []config.Selector{{Name: "room", Type: "path", Key: "room"}},
+		WebSocket: &config.WSConfig{
+			OnConnect: []config.WSFrame{{Body: `{"type":"welcome","room":"{{.room}}"}`}},
+			Messages: []config.WSMessage{
+				{MatchType: "exact", Value: "ping", Reply: []config.WSFrame{{Body: "pong"}}},
+				{Path: "type", Value: "subscribe", Reply: []config.WSFrame{{Body: `{"subscribed":true}`}}},
+				{MatchType: "regex", Value: `^echo (\w+)$`, Reply: []config.WSFrame{
+					{Script: &config.ScriptConfig{Source: `return {body = "script:" .. request.body}`}},
+					{Body: "{{.match.1}}"},
+				}},
+				{Value: "bye", Reply: []config.WSFrame{{Body: "see you"}}, Close: true},
+			},
+			Default: []config.WSFrame{{Body: "unknown: {{.message}}"}},
+			Push:    []config.WSPush{{IntervalMs: 20, Count: 2, WSFrame: config.WSFrame{Body: "tick {{.seq}}"}}},
+		},
+	}}})
+	router := gin.New()
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/lobby", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// Push frames interleave with replies, so they are collected separately
+	var pushed []string
+	read := func() string {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if strings.HasPrefix(string(data), "tick ") {
+				pushed = append(pushed, string(data))
+				continue
+			}
+			return string(data)
+		}
+	}
+	send := func(message string) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	if got, want := read(), `{"type":"welcome","room":"lobby"}`; got != want {
+		t.Errorf("on_connect frame = %s, want %s", got, want)
+	}
+	tests := []struct {
+		send string
+		want []string
+	}{
+		{"ping", []string{"pong"}},
+		{`{"type":"subscribe","channel":"prices"}`, []string{`{"subscribed":true}`}},
+		{"echo hello", []string{"script:echo hello", "hello"}},
+		{"what", []string{"unknown: what"}},
+	}
+	for _, tt := range tests {
+		send(tt.send)
+		for _, want := range tt.want {
+			if got := read(); got != want {
+				t.Errorf("reply to %q = %q, want %q", tt.send, got, want)
+			}
+		}
+	}
+
+	// Let both push frames arrive before closing
+	time.Sleep(60 * time.Millisecond)
+	send("bye")
+	if got := read(); got != "see you" {
+		t.Errorf("reply to bye = %q, want %q", got, "see you")
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("after bye: err = %v, want normal closure", err)
+	}
+	if len(pushed) != 2 || pushed[0] != "tick 1" || pushed[1] != "tick 2" {
+		t.Errorf("pushed = %v, want [tick 1 tick 2]", pushed)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws/lobby", nil))
+	if w.Code != http.StatusUpgradeRequired {
+		t.Errorf("plain GET: status = %d, want %d", w.Code, http.StatusUpgradeRequired)
+	}
+}
+
+func TestWebSocketReleasesSharedResources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{
+		Server: config.ServerConfig{MaxConcurrentRequests: 1},
+		Endpoints: []config.Endpoint{
+			{
+				ID: "feed", Path: "/ws", Method: "GET", Mode: config.EndpointModeWebSocket,
+				Idempotency: &config.Idempotency{},
+				WebSocket:   &config.WSConfig{OnConnect: []config.WSFrame{{Body: "hello"}}},
+			},
+			{ID: "ping", Path: "/ping", Method: "GET", Default: config.ResponseConfig{StatusCode: http.StatusOK}},
+		},
+	})
+	router := gin.New()
+	router.Use(middleware.Concurrency(cm, ratelimit.NewConcurrencyLimiter()))
+	NewMockHandler(cm, nil, nil).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Two sockets with the same idempotency key stay open side by side
+	header := http.Header{"Idempotency-Key": []string{"k1"}}
+	for i := 0; i < 2; i++ {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+		if err != nil {
+			t.Fatalf("dial %d failed: %v (response %v)", i, err, resp)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, data, err := conn.ReadMessage(); err != nil || string(data) != "hello" {
+			t.Fatalf("connection %d: read %q, %v", i, data, err)
+		}
+	}
+
+	// Open sockets do not hold the server-wide concurrency slot
+	resp, err := http.Get(server.URL + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /ping while sockets are open: status = %d, want 200", resp.StatusCode)
+	}
+}
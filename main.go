@@ -1,21 +1,52 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"mock-api-server/admin"
+	"mock-api-server/broker"
 	"mock-api-server/config"
 	"mock-api-server/handler"
 	"mock-api-server/middleware"
+	"mock-api-server/oauth"
+	"mock-api-server/pkg/bundle"
+	"mock-api-server/pkg/chaos"
+	"mock-api-server/pkg/events"
+	"mock-api-server/pkg/ratelimit"
+	"mock-api-server/pkg/scaffold"
+	"mock-api-server/pkg/selfcheck"
+	"mock-api-server/state"
+	"mock-api-server/webhook"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "generate":
+			os.Exit(scaffold.Run(os.Args[2:], os.Stdout, os.Stderr))
+		case "bundle":
+			os.Exit(bundle.Run(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	// Parse command line flags
 	configPath := flag.String("config", "config.yaml", "Path to configuration file")
+	selfCheckOnly := flag.Bool("selfcheck", false, "Print the self-check report as JSON and exit, with status 1 if a check failed")
 	flag.Parse()
 
 	// Create logger for startup
@@ -34,6 +65,22 @@ func main() {
 		startupLogger.Printf("[WARN] %s", warn)
 	}
 
+	// Deep checks: response files parse, templates render, hook hosts resolve
+	report := selfcheck.Run(context.Background(), cfg, nil)
+	if *selfCheckOnly {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		if !report.OK {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	for _, check := range report.Failures() {
+		startupLogger.Printf("[WARN] Self-check %s %s %s: %s", check.Kind, check.Location, check.Target, check.Message)
+	}
+	startupLogger.Printf("Self-check: %d passed, %d failed", report.Summary.Passed, report.Summary.Failed)
+
 	// Create config manager
 	cfgManager := config.NewConfigManager(*configPath)
 	cfgManager.SetConfig(cfg)
@@ -43,11 +90,19 @@ func main() {
 		cfg.Server.Logging.Level,
 		cfg.Server.Logging.LogFormat,
 		cfg.Server.Logging.LogFile,
+		cfg.Server.Logging.Rotation,
 	)
 	if err != nil {
 		startupLogger.Printf("[WARN] Failed to create zap logger, using default: %v", err)
 	}
 
+	// Keep recent log entries in memory for the admin API
+	var logBuffer *middleware.LogBuffer
+	if zapLogger != nil && cfg.Admin.Enabled {
+		logBuffer = middleware.NewLogBuffer(cfg.Server.Logging.BufferSize)
+		zapLogger = logBuffer.Attach(zapLogger)
+	}
+
 	// Set Gin mode based on log level
 	if cfg.Server.Logging.Level == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -55,36 +110,157 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Create runtime state shared by the mock handler and admin API
+	var scenarioStore state.Store
+	if cfg.State.Backend == "redis" {
+		scenarioStore = newRedisStore(cfg.State.Redis, startupLogger)
+	} else {
+		scenarioStore = state.NewScenarioStore()
+	}
+	if cfg.State.PersistFile != "" && cfg.State.Backend == "redis" {
+		startupLogger.Printf("[WARN] state.persist_file %s is ignored: the redis backend keeps scenario state itself", cfg.State.PersistFile)
+	} else if cfg.State.PersistFile != "" {
+		if err := state.LoadFile(scenarioStore, cfg.State.PersistFile); err != nil {
+			startupLogger.Printf("[WARN] Failed to load scenario state from %s: %v", cfg.State.PersistFile, err)
+		}
+		stopFlusher := state.StartFlusher(scenarioStore, cfg.State.PersistFile,
+			time.Duration(cfg.State.FlushIntervalSec)*time.Second,
+			func(err error) { startupLogger.Printf("[WARN] Failed to persist scenario state: %v", err) })
+		defer stopFlusher()
+		startupLogger.Printf("Scenario state persisted to: %s", cfg.State.PersistFile)
+	}
+	eventBus := events.NewBus()
+	stopExpirer := state.StartExpirer(scenarioStore, func() map[string]time.Duration {
+		ttls := make(map[string]time.Duration)
+		if current := cfgManager.GetConfig(); current != nil {
+			for name, sc := range current.Scenarios {
+				ttls[name] = time.Duration(sc.TTLSec) * time.Second
+			}
+		}
+		return ttls
+	}, time.Second, func(scenario string, expired []state.PartitionState) {
+		for _, ps := range expired {
+			scenarioStore.RecordTransition(scenario, state.Transition{
+				Partition:    ps.Partition,
+				PreviousStep: ps.Step,
+				Step:         state.DefaultStep,
+				Source:       "ttl",
+			})
+			eventBus.Publish(events.TypeScenarioTransition, map[string]interface{}{
+				"scenario":      scenario,
+				"partition":     ps.Partition,
+				"previous_step": ps.Step,
+				"step":          state.DefaultStep,
+				"source":        "ttl",
+			})
+		}
+	})
+	defer stopExpirer()
+	stopWebhooks := webhook.NewDispatcher(cfgManager, scenarioStore,
+		func(err error) { startupLogger.Printf("[WARN] Scenario webhook failed: %v", err) }).Start(eventBus)
+	defer stopWebhooks()
+	chaosController := chaos.NewController()
+	healthOverride := handler.NewHealthOverride()
+
 	// Create Gin router
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		startupLogger.Fatalf("Invalid server.trusted_proxies: %v", err)
+	}
 
 	// Add middleware
+	router.Use(middleware.RequestID())
+	router.Use(middleware.TraceContext(cfgManager))
+	router.Use(middleware.BodyLimit(cfgManager))
+	router.Use(middleware.DefaultHeaders(cfgManager))
 	if zapLogger != nil {
-		router.Use(middleware.Logger(zapLogger, cfg.Server.Logging.AccessLog))
+		router.Use(accessLogMiddleware(cfg.Server.Logging, zapLogger, logBuffer, startupLogger))
 		router.Use(middleware.Recovery(zapLogger, cfg.Server.ErrorHandling.ShowDetails))
 	} else {
 		router.Use(gin.Logger())
 		router.Use(gin.Recovery())
 	}
 
+	if cfg.Admin.Enabled && cfg.Recorder.Enabled {
+		router.Use(middleware.Events(eventBus, cfg.Recorder.Exclude, "/admin"))
+	}
+	// Outside chaos and the limits below, so their rejections are reported too
+	router.Use(middleware.MatchHeaders(cfgManager, "/admin"))
+
+	// CORS, IP filtering, rate limits, mock auth and concurrency limits apply to mock endpoints only
+	mockOnlyExcludes := []string{"/admin"}
+	if cfg.HealthCheck.Enabled && cfg.HealthCheck.Path != "" {
+		mockOnlyExcludes = append(mockOnlyExcludes, cfg.HealthCheck.Path)
+	}
+	mockHandler := handler.NewMockHandler(cfgManager, scenarioStore, eventBus)
+	// Preflights carry no credentials and must not fail under chaos, so
+	// they are answered first
+	router.Use(middleware.MockCORS(cfgManager, mockHandler.PreflightMethods, mockOnlyExcludes...))
+	router.Use(middleware.GRPCWeb("/admin"))
+	router.Use(middleware.Chaos(chaosController, cfgManager, "/admin"))
+	router.Use(middleware.IPFilter(cfgManager, mockOnlyExcludes...))
+	router.Use(middleware.RateLimit(cfgManager, ratelimit.NewLimiter(), mockOnlyExcludes...))
+	router.Use(middleware.MockAuth(cfgManager, mockOnlyExcludes...))
+	router.Use(middleware.Concurrency(cfgManager, ratelimit.NewConcurrencyLimiter(), mockOnlyExcludes...))
+
 	// Register health check endpoint if enabled
 	if cfg.HealthCheck.Enabled {
 		healthPath := cfg.HealthCheck.Path
 		if healthPath == "" {
 			healthPath = "/health"
 		}
-		router.GET(healthPath, handler.HealthHandler(cfgManager))
+		router.GET(healthPath, handler.HealthHandler(cfgManager, healthOverride))
+		router.GET(strings.TrimSuffix(healthPath, "/")+"/live", handler.LiveHandler())
+		router.GET(strings.TrimSuffix(healthPath, "/")+"/ready", handler.ReadyHandler(cfgManager, healthOverride))
 		startupLogger.Printf("Health check endpoint registered at: %s", healthPath)
 	}
 
-	// Create and register mock handler
-	mockHandler := handler.NewMockHandler(cfgManager)
+	// Register the simulated OAuth2/OIDC provider if enabled
+	if cfg.OAuth.Enabled {
+		oauthServer, err := oauth.NewServer(cfgManager)
+		if err != nil {
+			startupLogger.Fatalf("Failed to start OAuth provider: %v", err)
+		}
+		oauthServer.RegisterRoutes(router)
+		startupLogger.Printf("OAuth provider registered at: %s", cfg.OAuth.PathPrefix)
+	}
+
+	emitter := broker.NewEmitter(cfgManager,
+		func(err error) { startupLogger.Printf("[WARN] Event publish failed: %v", err) })
+	defer emitter.Close()
+	mockHandler.SetEmitter(emitter)
+
+	// Register admin API if enabled
+	if cfg.Admin.Enabled {
+		adminServer := admin.NewServer(admin.Options{
+			ConfigManager: cfgManager,
+			MockHandler:   mockHandler,
+			ScenarioStore: scenarioStore,
+			EventBus:      eventBus,
+			Chaos:         chaosController,
+			Health:        healthOverride,
+			LogBuffer:     logBuffer,
+		})
+		adminServer.RegisterRoutes(router)
+		stopMetrics := adminServer.StartMetricsTicker(0)
+		defer stopMetrics()
+		startupLogger.Printf("Admin API registered at: /admin")
+	}
+
+	// Register mock routes
 	mockHandler.RegisterRoutes(router)
 
 	// Start config watcher if hot reload is enabled
 	if cfg.Server.HotReload {
 		stdLogger := log.New(os.Stdout, "[CONFIG] ", log.LstdFlags)
 		watcher := config.NewWatcher(*configPath, cfgManager, stdLogger)
+		watcher.OnReload(func(newCfg *config.Config) {
+			eventBus.Publish(events.TypeConfigReloaded, map[string]interface{}{
+				"source":            "file",
+				"endpoints_count":   len(newCfg.Endpoints),
+				"config_generation": cfgManager.Generation(),
+			})
+		})
 		watcher.Start(cfg.Server.ReloadIntervalSec)
 		defer watcher.Stop()
 		startupLogger.Printf("Hot reload enabled, watching: %s", *configPath)
@@ -95,7 +271,99 @@ func main() {
 	startupLogger.Printf("Starting Mock API Server on %s", addr)
 	startupLogger.Printf("Loaded %d endpoint(s)", len(cfg.Endpoints))
 
-	if err := router.Run(addr); err != nil {
+	// Stop on SIGINT or SIGTERM by returning from main, so the deferred
+	// flush of persisted state and the other cleanups run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := newHTTPServer(addr, router, cfg.Server.Timeouts)
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-serveErr:
 		startupLogger.Fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+	startupLogger.Printf("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		startupLogger.Printf("[WARN] Shutdown did not complete: %v", err)
+	}
+}
+
+// shutdownTimeout bounds how long in-flight requests may finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
+// accessLogMiddleware writes the access log to the application log, or to
+// logging.access_log_file and in the Apache combined format as configured
+func accessLogMiddleware(logging config.LoggingConfig, appLogger *zap.Logger, logBuffer *middleware.LogBuffer, logger *log.Logger) gin.HandlerFunc {
+	if logging.AccessLogFormat == middleware.AccessLogCombined {
+		var w io.Writer = os.Stdout
+		if logging.AccessLogFile != "" {
+			file, err := middleware.OpenLogWriter(logging.AccessLogFile, logging.Rotation)
+			if err != nil {
+				logger.Fatalf("Failed to open access log file: %v", err)
+			}
+			w = file
+		}
+		return middleware.CombinedLogger(w, logging.AccessLog, logging.Overrides)
+	}
+
+	accessLogger := appLogger
+	if logging.AccessLogFile != "" {
+		var err error
+		if accessLogger, err = middleware.NewAccessLogger(logging.LogFormat, logging.AccessLogFile, logging.Rotation); err != nil {
+			logger.Fatalf("Failed to open access log file: %v", err)
+		}
+		if logBuffer != nil {
+			accessLogger = logBuffer.Attach(accessLogger)
+		}
+	}
+	return middleware.Logger(accessLogger, logging.AccessLog, logging.Overrides)
+}
+
+// newHTTPServer creates the server for router with the configured timeouts
+func newHTTPServer(addr string, router http.Handler, t config.Timeouts) *http.Server {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           router,
+		ReadTimeout:       ms(t.ReadMs),
+		ReadHeaderTimeout: ms(t.ReadHeaderMs),
+		WriteTimeout:      ms(t.WriteMs),
+		IdleTimeout:       ms(t.IdleMs),
+	}
+	srv.SetKeepAlivesEnabled(!t.DisableKeepAlive)
+	return srv
+}
+
+// newRedisStore connects the shared scenario state backend, failing startup
+// when Redis is unreachable
+func newRedisStore(cfg config.RedisConfig, logger *log.Logger) *state.RedisStore {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = "mock:"
 	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		logger.Fatalf("Failed to connect to Redis at %s: %v", addr, err)
+	}
+	logger.Printf("Scenario state shared through Redis at: %s (prefix %q)", addr, prefix)
+
+	return state.NewRedisStore(client, prefix, func(err error) {
+		logger.Printf("[WARN] Redis state backend error: %v", err)
+	})
 }
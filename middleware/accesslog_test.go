@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCombinedLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(CombinedLogger(&buf, true, []config.LogOverride{{PathPrefix: "/health", Level: "none"}}))
+	router.GET("/*path", func(c *gin.Context) { c.String(http.StatusCreated, "hello") })
+
+	req := httptest.NewRequest(http.MethodGet, "/orders?page=2", nil)
+	req.SetBasicAuth("alice", "secret")
+	req.Header.Set("Referer", "http://app.test/")
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.RemoteAddr = "10.0.0.7:5555"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one line, got %q", buf.String())
+	}
+	combined := regexp.MustCompile(`^10\.0\.0\.7 - alice \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /orders\?page=2 HTTP/1\.1" 201 5 "http://app\.test/" "curl/8\.0"$`)
+	if !combined.MatchString(lines[0]) {
+		t.Errorf("not a combined log line: %q", lines[0])
+	}
+}
+
+func TestNewAccessLogger(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "access.log")
+	logger, err := NewAccessLogger("json", logFile, config.LogRotation{})
+	if err != nil {
+		t.Fatalf("NewAccessLogger returned error: %v", err)
+	}
+
+	logger.Info("Request completed")
+	_ = logger.Sync()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("expected access log file to be written: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"Request completed"`) {
+		t.Errorf("expected a JSON entry in the access log, got %q", data)
+	}
+}
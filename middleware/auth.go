@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/chaos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockAuth returns a gin middleware enforcing server.auth rules on every
+// request except paths under the given prefixes
+func MockAuth(cfgManager *config.ConfigManager, excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		cfg := cfgManager.GetConfig()
+		if cfg == nil {
+			c.Next()
+			return
+		}
+
+		rule := findAuthRule(cfg.Server.Auth.Rules, c.Request.Method, c.Request.URL.Path)
+		if rule == nil {
+			c.Next()
+			return
+		}
+
+		credential := authCredential(c, rule)
+		switch {
+		case credential == "":
+			AbortAuth(c, cfg, rule.Unauthorized, http.StatusUnauthorized, "UNAUTHORIZED", "Missing credentials")
+		case !acceptedCredential(rule.Keys, credential):
+			AbortAuth(c, cfg, rule.Forbidden, http.StatusForbidden, "FORBIDDEN", "Invalid credentials")
+		default:
+			c.Next()
+		}
+	}
+}
+
+// findAuthRule returns the first rule matching method and path
+func findAuthRule(rules []config.AuthRule, method, requestPath string) *config.AuthRule {
+	for i := range rules {
+		rule := &rules[i]
+		if len(rule.Methods) > 0 && !containsFold(rule.Methods, method) {
+			continue
+		}
+		for _, pattern := range rule.Paths {
+			if chaos.MatchPath(pattern, requestPath) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// authCredential extracts the API key or Bearer token the rule asks for
+func authCredential(c *gin.Context, rule *config.AuthRule) string {
+	if strings.EqualFold(rule.Type, "bearer") {
+		auth := c.GetHeader("Authorization")
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+			return strings.TrimSpace(auth[7:])
+		}
+		return ""
+	}
+
+	header := rule.Header
+	if header == "" {
+		header = "X-API-Key"
+	}
+	return c.GetHeader(header)
+}
+
+func acceptedCredential(keys []string, credential string) bool {
+	if len(keys) == 0 {
+		return true
+	}
+	for _, key := range keys {
+		if key == credential {
+			return true
+		}
+	}
+	return false
+}
+
+// AbortAuth writes the configured auth failure response, or the error
+// response for the status when resp is nil
+func AbortAuth(c *gin.Context, cfg *config.Config, resp *config.ResponseConfig, defaultStatus int, code, message string) {
+	c.Set("matched_rule", "auth")
+	AbortWithResponse(c, cfg, resp, defaultStatus, code, message)
+}
+
+// AbortWithResponse aborts with the status, headers and response file of
+// resp, falling back to defaultStatus and the error response for code and
+// message for whatever resp leaves out
+func AbortWithResponse(c *gin.Context, cfg *config.Config, resp *config.ResponseConfig, defaultStatus int, code, message string) {
+	status := defaultStatus
+	if resp != nil && resp.StatusCode != 0 {
+		status = resp.StatusCode
+	}
+	if resp != nil {
+		for k, v := range resp.Headers {
+			c.Header(k, v)
+		}
+		if resp.ResponseFile != "" {
+			if content, err := os.ReadFile(resp.ResponseFile); err == nil {
+				c.Set("response_file", resp.ResponseFile)
+				contentType := resp.Headers["Content-Type"]
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				c.Data(status, contentType, content)
+				c.Abort()
+				return
+			}
+		}
+	}
+
+	AbortWithError(c, cfg, status, gin.H{
+		"code":    code,
+		"message": message,
+	})
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMockAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Server: config.ServerConfig{Auth: config.MockAuth{Rules: []config.AuthRule{
+		{Paths: []string{"/api/keyed"}, Keys: []string{"k1"}},
+		{Paths: []string{"/api/token/*"}, Type: "bearer", Methods: []string{"POST"}},
+	}}}})
+
+	router := gin.New()
+	router.Use(MockAuth(cm, "/admin"))
+	router.Any("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"unprotected path", http.MethodGet, "/public", "", "", http.StatusOK},
+		{"missing api key", http.MethodGet, "/api/keyed", "", "", http.StatusUnauthorized},
+		{"wrong api key", http.MethodGet, "/api/keyed", "X-API-Key", "bad", http.StatusForbidden},
+		{"valid api key", http.MethodGet, "/api/keyed", "X-API-Key", "k1", http.StatusOK},
+		{"bearer any token", http.MethodPost, "/api/token/x", "Authorization", "Bearer abc", http.StatusOK},
+		{"bearer missing", http.MethodPost, "/api/token/x", "", "", http.StatusUnauthorized},
+		{"bearer wrong scheme counts as missing", http.MethodPost, "/api/token/x", "Authorization", "Basic abc", http.StatusUnauthorized},
+		{"method not covered", http.MethodGet, "/api/token/x", "", "", http.StatusOK},
+		{"excluded prefix", http.MethodGet, "/admin/keyed", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
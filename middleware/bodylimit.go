@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit returns a gin middleware enforcing server.max_request_body_bytes.
+// Requests declaring a larger Content-Length are rejected up front. Bodies of
+// unknown length (chunked uploads) are buffered up to the limit before
+// dispatch, so they are rejected even when the endpoint never reads them.
+func BodyLimit(cfgManager *config.ConfigManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := cfgManager.GetConfig()
+		if cfg == nil || cfg.Server.MaxRequestBodyBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		limit := cfg.Server.MaxRequestBodyBytes
+		if c.Request.ContentLength > limit {
+			AbortBodyTooLarge(c, cfg)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		if c.Request.ContentLength < 0 {
+			data, err := io.ReadAll(c.Request.Body)
+			c.Request.Body.Close()
+			if IsBodyTooLarge(err) {
+				AbortBodyTooLarge(c, cfg)
+				return
+			}
+			if err != nil {
+				AbortWithError(c, cfg, http.StatusBadRequest, gin.H{
+					"code":    "INVALID_BODY",
+					"message": "Failed to read request body: " + err.Error(),
+				})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(data))
+		}
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge reports whether err came from reading past the body limit
+func IsBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// AbortBodyTooLarge writes a 413 response for cfg's body limit
+func AbortBodyTooLarge(c *gin.Context, cfg *config.Config) {
+	AbortWithError(c, cfg, http.StatusRequestEntityTooLarge, gin.H{
+		"code":    "REQUEST_TOO_LARGE",
+		"message": "Request body exceeds " + strconv.FormatInt(cfg.Server.MaxRequestBodyBytes, 10) + " bytes",
+	})
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Server: config.ServerConfig{MaxRequestBodyBytes: 8}})
+
+	router := gin.New()
+	router.Use(BodyLimit(cm))
+	router.POST("/upload", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); IsBodyTooLarge(err) {
+			AbortBodyTooLarge(c, cm.GetConfig())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	router.POST("/ignore", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		contentLength int64
+		wantStatus    int
+	}{
+		{"within limit", "/upload", "small", 5, http.StatusOK},
+		{"declared too large", "/upload", strings.Repeat("x", 20), 20, http.StatusRequestEntityTooLarge},
+		{"streamed too large", "/upload", strings.Repeat("x", 20), -1, http.StatusRequestEntityTooLarge},
+		{"streamed within limit", "/upload", "small", -1, http.StatusOK},
+		{"streamed too large, body unread", "/ignore", strings.Repeat("x", 20), -1, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
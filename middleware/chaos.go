@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/chaos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Chaos returns a gin middleware applying fault injection to every request
+// except paths under the given prefixes. Settings made through the admin API
+// take precedence over server.chaos from the config.
+func Chaos(controller *chaos.Controller, cfgManager *config.ConfigManager, excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		settings, ok := controller.Get()
+		if !ok {
+			cfg := cfgManager.GetConfig()
+			if cfg == nil || !cfg.Server.Chaos.Enabled {
+				c.Next()
+				return
+			}
+			settings = ChaosSettings(cfg.Server.Chaos)
+		}
+
+		decision := settings.Decide(c.Request.URL.Path)
+		if decision.Delay > 0 {
+			time.Sleep(decision.Delay)
+		}
+		if decision.ErrorStatus != 0 {
+			c.Set("matched_rule", "chaos")
+			AbortWithError(c, cfgManager.GetConfig(), decision.ErrorStatus, gin.H{
+				"code":    "CHAOS_INJECTED",
+				"message": "Error injected by chaos settings",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ChaosSettings converts server.chaos config to chaos settings
+func ChaosSettings(cfg config.ChaosConfig) chaos.Settings {
+	return chaos.Settings{
+		LatencyMs:       cfg.LatencyMs,
+		LatencyJitterMs: cfg.LatencyJitterMs,
+		LatencyRate:     cfg.LatencyRate,
+		ErrorRate:       cfg.ErrorRate,
+		ErrorStatus:     cfg.ErrorStatus,
+		ErrorStatuses:   cfg.ErrorStatuses,
+		Paths:           cfg.Paths,
+	}
+}
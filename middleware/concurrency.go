@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Concurrency returns a gin middleware enforcing server.max_concurrent_requests
+// on every request except paths under the given prefixes
+func Concurrency(cfgManager *config.ConfigManager, limiter *ratelimit.ConcurrencyLimiter, excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		cfg := cfgManager.GetConfig()
+		if cfg == nil {
+			c.Next()
+			return
+		}
+
+		release, ok := AcquireConcurrency(c, cfg, limiter, "global", cfg.Server.MaxConcurrentRequests, cfg.Server.ConcurrencyQueueMs)
+		if !ok {
+			return
+		}
+		release = sync.OnceFunc(release)
+		c.Set(concurrencyReleaseKey, release)
+		defer release()
+		c.Next()
+	}
+}
+
+const concurrencyReleaseKey = "concurrency_release"
+
+// ReleaseConcurrency gives back the server-wide slot of the request early.
+// Upgraded websocket connections call it so long-lived sockets do not count
+// against server.max_concurrent_requests.
+func ReleaseConcurrency(c *gin.Context) {
+	if release, ok := c.Value(concurrencyReleaseKey).(func()); ok {
+		release()
+	}
+}
+
+// AcquireConcurrency takes a slot for the request under key. When no slot frees
+// up within queueMs it aborts with 503 and returns false.
+func AcquireConcurrency(c *gin.Context, cfg *config.Config, limiter *ratelimit.ConcurrencyLimiter, key string, limit, queueMs int) (func(), bool) {
+	release, ok := limiter.Acquire(c.Request.Context(), key, limit, time.Duration(queueMs)*time.Millisecond)
+	if ok {
+		return release, true
+	}
+
+	c.Set("matched_rule", "concurrency_limit")
+	AbortWithError(c, cfg, http.StatusServiceUnavailable, gin.H{
+		"code":    "SERVER_BUSY",
+		"message": "Too many concurrent requests",
+	})
+	return nil, false
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type"}
+)
+
+// CORS returns a gin middleware that adds CORS headers for allowed origins
+// and answers preflight requests with 204 before later handlers run
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		if !setCORSHeaders(c, cfg, exposeHeaders) {
+			c.Next()
+			return
+		}
+
+		if isPreflight(c.Request) {
+			answerPreflight(c, cfg, allowMethods, allowHeaders)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// MockCORS returns a gin middleware applying server.cors to mock endpoints,
+// read per request so reloads take effect. A preflight request is answered
+// with 204 when preflight reports the methods of its path, so browsers can
+// call any configured endpoint without an OPTIONS endpoint being defined;
+// otherwise it continues to the handlers. Paths under the given prefixes
+// are skipped.
+func MockCORS(cfgManager *config.ConfigManager, preflight func(*http.Request) ([]string, bool), excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+		cfg := cfgManager.GetConfig()
+		if cfg == nil || !setCORSHeaders(c, cfg.Server.CORS, strings.Join(cfg.Server.CORS.ExposedHeaders, ", ")) {
+			c.Next()
+			return
+		}
+
+		if isPreflight(c.Request) {
+			if methods, ok := preflight(c.Request); ok {
+				cors := cfg.Server.CORS
+				if len(cors.AllowedMethods) > 0 {
+					methods = cors.AllowedMethods
+				}
+				// Without allowed_headers the requested headers are allowed,
+				// so clients sending their own headers need no extra config
+				allowHeaders := strings.Join(cors.AllowedHeaders, ", ")
+				if allowHeaders == "" {
+					allowHeaders = c.GetHeader("Access-Control-Request-Headers")
+				}
+				c.Set("matched_rule", "cors_preflight")
+				answerPreflight(c, cors, strings.Join(methods, ", "), allowHeaders)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// setCORSHeaders adds the CORS response headers when the request's origin
+// is allowed, reporting whether it is
+func setCORSHeaders(c *gin.Context, cfg config.CORSConfig, exposeHeaders string) bool {
+	allowed, ok := allowedOrigin(cfg, c.GetHeader("Origin"))
+	if !ok {
+		return false
+	}
+
+	h := c.Writer.Header()
+	h.Set("Access-Control-Allow-Origin", allowed)
+	if allowed != "*" {
+		h.Add("Vary", "Origin")
+	}
+	if cfg.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if exposeHeaders != "" {
+		h.Set("Access-Control-Expose-Headers", exposeHeaders)
+	}
+	return true
+}
+
+// isPreflight reports whether r is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// answerPreflight ends a preflight request with 204
+func answerPreflight(c *gin.Context, cfg config.CORSConfig, allowMethods, allowHeaders string) {
+	h := c.Writer.Header()
+	h.Set("Access-Control-Allow-Methods", allowMethods)
+	if allowHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", allowHeaders)
+	}
+	if cfg.MaxAgeSec > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAgeSec))
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin value for origin.
+// Credentialed requests may not use "*", so the origin is echoed instead.
+func allowedOrigin(cfg config.CORSConfig, origin string) (string, bool) {
+	if origin == "" {
+		return "", false
+	}
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			if cfg.AllowCredentials {
+				return origin, true
+			}
+			return "*", true
+		}
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
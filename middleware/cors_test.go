@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+	}{
+		{"allowed origin", config.CORSConfig{AllowedOrigins: []string{"http://a.test"}}, http.MethodGet, "http://a.test", false, http.StatusOK, "http://a.test", ""},
+		{"disallowed origin", config.CORSConfig{AllowedOrigins: []string{"http://a.test"}}, http.MethodGet, "http://b.test", false, http.StatusOK, "", ""},
+		{"wildcard", config.CORSConfig{AllowedOrigins: []string{"*"}}, http.MethodGet, "http://b.test", false, http.StatusOK, "*", ""},
+		{"wildcard with credentials echoes origin", config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, http.MethodGet, "http://b.test", false, http.StatusOK, "http://b.test", ""},
+		{"preflight", config.CORSConfig{AllowedOrigins: []string{"*"}}, http.MethodOptions, "http://a.test", true, http.StatusNoContent, "*", "GET, POST, PUT, DELETE, OPTIONS"},
+		{"preflight custom methods", config.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}, http.MethodOptions, "http://a.test", true, http.StatusNoContent, "*", "GET"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CORS(tt.cfg))
+			router.Handle(tt.method, "/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(tt.method, "/x", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", "PUT")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+		})
+	}
+}
+
+func TestMockCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Server: config.ServerConfig{CORS: config.CORSConfig{AllowedOrigins: []string{"http://app.test"}}}})
+	preflight := func(r *http.Request) ([]string, bool) {
+		if r.URL.Path == "/orders" {
+			return []string{"GET", "POST"}, true
+		}
+		return nil, false
+	}
+
+	router := gin.New()
+	router.Use(MockCORS(cm, preflight, "/admin"))
+	router.NoRoute(func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(method, path, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Origin", origin)
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			req.Header.Set("Access-Control-Request-Headers", "X-Client-Id")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call(http.MethodOptions, "/orders", "http://app.test", true)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Errorf("expected 204 allowing GET, POST, got %d %q", w.Code, w.Header().Get("Access-Control-Allow-Methods"))
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "X-Client-Id" {
+		t.Errorf("expected the requested headers to be allowed, got %q", got)
+	}
+	if w := call(http.MethodOptions, "/unknown", "http://app.test", true); w.Code != http.StatusNotFound {
+		t.Errorf("expected preflights for unknown paths to reach the handlers, got %d", w.Code)
+	}
+	if w := call(http.MethodOptions, "/orders", "http://other.test", true); w.Code == http.StatusNoContent {
+		t.Error("expected preflights from disallowed origins not to be answered")
+	}
+	if w := call(http.MethodGet, "/orders", "http://app.test", false); w.Header().Get("Access-Control-Allow-Origin") != "http://app.test" {
+		t.Errorf("expected CORS headers on mock responses, got %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if w := call(http.MethodOptions, "/admin/x", "http://app.test", true); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected excluded paths to be left alone")
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/template"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AbortWithError aborts the request with status and the JSON error object
+// errBody ({"error": errBody}). When server.error_handling configures a
+// custom response for status on the request's path, that file is sent
+// instead, with {{.status}}, {{.method}}, {{.path}}, every field of errBody
+// ({{.code}}, {{.message}}, ...) and the built-in template variables
+// replaced. cfg may be nil.
+func AbortWithError(c *gin.Context, cfg *config.Config, status int, errBody gin.H) {
+	if cfg != nil {
+		if file, ok := cfg.Server.ErrorHandling.ErrorResponseFile(status, c.Request.URL.Path); ok {
+			if content, err := os.ReadFile(file); err == nil {
+				values := map[string]string{
+					"status": strconv.Itoa(status),
+					"method": c.Request.Method,
+					"path":   c.Request.URL.Path,
+				}
+				for k, v := range errBody {
+					if list, ok := v.([]string); ok {
+						values[k] = strings.Join(list, ", ")
+					} else {
+						values[k] = fmt.Sprint(v)
+					}
+				}
+				c.Set("response_file", file)
+				c.Data(status, "application/json", template.ReplaceVariables(content, values))
+				c.Abort()
+				return
+			}
+		}
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": errBody})
+}
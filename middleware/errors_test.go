@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAbortWithErrorUsesCustomResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	file := filepath.Join(t.TempDir(), "forbidden.json")
+	body := `{"fault":"{{.code}}","status":{{.status}},"detail":"{{.message}}","request":"{{.method}} {{.path}}"}`
+	if err := os.WriteFile(file, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Server.ErrorHandling.CustomErrorResponses = map[int]string{http.StatusForbidden: file}
+
+	serve := func(cfg *config.Config, status int) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/orders", func(c *gin.Context) {
+			AbortWithError(c, cfg, status, gin.H{"code": "FORBIDDEN", "message": "no access"})
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+		return w
+	}
+
+	w := serve(cfg, http.StatusForbidden)
+	want := `{"fault":"FORBIDDEN","status":403,"detail":"no access","request":"GET /orders"}`
+	if w.Code != http.StatusForbidden || w.Body.String() != want {
+		t.Errorf("custom response: %d %s", w.Code, w.Body.String())
+	}
+
+	// Other codes and a nil config keep the standard body
+	for _, tt := range []struct {
+		cfg    *config.Config
+		status int
+	}{
+		{cfg, http.StatusTooManyRequests},
+		{nil, http.StatusForbidden},
+	} {
+		w := serve(tt.cfg, tt.status)
+		if w.Code != tt.status || w.Body.String() != `{"error":{"code":"FORBIDDEN","message":"no access"}}` {
+			t.Errorf("status %d: %d %s", tt.status, w.Code, w.Body.String())
+		}
+	}
+}
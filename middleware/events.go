@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxEventBody caps the request and response bodies attached to request events
+const maxEventBody = 64 << 10
+
+// Events returns a gin middleware that publishes a request event for every
+// completed request, except paths under the given prefixes and requests
+// matching the recorder exclusions, which are compiled once here. When
+// someone is subscribed as the request starts, headers and bodies (truncated
+// to 64KB) are attached too; binary bodies are reported by content type and
+// size only, see binaryBody.
+func Events(bus *events.Bus, exclusions []config.RecorderExclusion, excludePrefixes ...string) gin.HandlerFunc {
+	excludes := compileExclusions(exclusions)
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if excludedPath(path, excludePrefixes) {
+			c.Next()
+			return
+		}
+		if isExcluded(excludes, c.Request.Method, path) {
+			c.Next()
+			return
+		}
+
+		var reqBody []byte
+		var reqCounter *countingReader
+		var recorder *bodyRecorder
+		reqType := c.GetHeader("Content-Type")
+		reqBinary := reqType != "" && isBinaryContentType(reqType, nil)
+		if bus.SubscriberCount() > 0 {
+			if reqBinary && c.Request.Body != nil {
+				reqCounter = &countingReader{ReadCloser: c.Request.Body}
+				c.Request.Body = reqCounter
+			} else {
+				reqBody = peekBody(c.Request, maxEventBody)
+			}
+			recorder = &bodyRecorder{ResponseWriter: c.Writer, limit: maxEventBody}
+			c.Writer = recorder
+		}
+
+		start := time.Now()
+		c.Next()
+
+		if bus.SubscriberCount() == 0 {
+			return
+		}
+
+		data := map[string]interface{}{
+			"method":     c.Request.Method,
+			"path":       path,
+			"query":      c.Request.URL.RawQuery,
+			"status":     c.Writer.Status(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+		}
+		if requestID := c.GetString("request_id"); requestID != "" {
+			data["request_id"] = requestID
+		}
+		if endpointID := c.GetString("endpoint_id"); endpointID != "" {
+			data["endpoint_id"] = endpointID
+		}
+		if tags, ok := c.Get("endpoint_tags"); ok {
+			data["endpoint_tags"] = tags
+		}
+		if basePath := c.GetString("endpoint_base_path"); basePath != "" {
+			data["endpoint_base_path"] = basePath
+		}
+		if matchedRule, ok := c.Get("matched_rule"); ok {
+			data["matched_rule"] = matchedRule
+		}
+		if responseFile, ok := c.Get("response_file"); ok {
+			data["response_file"] = responseFile
+		}
+		if recorder != nil {
+			data["request_headers"] = flattenHeader(c.Request.Header)
+			if reqBinary || isBinaryContentType(reqType, reqBody) {
+				size := c.Request.ContentLength
+				if size < 0 && reqCounter != nil {
+					size = reqCounter.n
+				} else if size < 0 {
+					size = int64(len(reqBody))
+				}
+				data["request_body_binary"] = binaryBody(reqType, size)
+			} else {
+				data["request_body"] = string(reqBody)
+			}
+			data["response_headers"] = flattenHeader(recorder.Header())
+			respType := recorder.Header().Get("Content-Type")
+			if recorder.binary || isBinaryContentType(respType, recorder.body.Bytes()) {
+				data["response_body_binary"] = binaryBody(respType, recorder.size)
+			} else {
+				data["response_body"] = recorder.body.String()
+			}
+		}
+
+		bus.Publish(events.TypeRequest, data)
+	}
+}
+
+// peekBody reads up to limit bytes of the request body and puts them back in
+// front of the unread rest, so later handlers and body limits see it intact
+func peekBody(req *http.Request, limit int64) []byte {
+	if req.Body == nil {
+		return nil
+	}
+	head, _ := io.ReadAll(io.LimitReader(req.Body, limit))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+	return head
+}
+
+// requestExclusion is a compiled recorder exclusion
+type requestExclusion struct {
+	pattern *regexp.Regexp
+	methods []string
+}
+
+// compileExclusions compiles the recorder exclusions; invalid ones are
+// reported by config validation and skipped
+func compileExclusions(rules []config.RecorderExclusion) []requestExclusion {
+	var compiled []requestExclusion
+	for _, rule := range rules {
+		pattern, err := rule.Pattern()
+		if err != nil {
+			continue
+		}
+		compiled = append(compiled, requestExclusion{pattern: pattern, methods: rule.Methods})
+	}
+	return compiled
+}
+
+func isExcluded(excludes []requestExclusion, method, path string) bool {
+	for _, e := range excludes {
+		if len(e.methods) > 0 && !slices.ContainsFunc(e.methods, func(m string) bool { return strings.EqualFold(m, method) }) {
+			continue
+		}
+		if e.pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryBody describes a body that is not attached to the event: only its
+// content type and size are kept, so images and protobuf payloads are not
+// mangled into strings or held in subscriber buffers
+func binaryBody(contentType string, size int64) map[string]interface{} {
+	return map[string]interface{}{"content_type": contentType, "size": size}
+}
+
+// isBinaryContentType reports whether a body of contentType is binary. Text,
+// JSON, XML, form and script types are text; a body without a content type
+// is binary when sample is not valid UTF-8.
+func isBinaryContentType(contentType string, sample []byte) bool {
+	if contentType == "" {
+		// A sample cut at maxEventBody may end inside a rune
+		for i := 1; i < utf8.UTFMax && len(sample) > 0 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+		return !utf8.Valid(sample)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return false
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-www-form-urlencoded",
+		"application/javascript", "application/graphql", "application/x-ndjson",
+		"application/yaml", "application/x-yaml":
+		return false
+	}
+	return true
+}
+
+// countingReader counts the bytes read from a request body that is not kept
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// flattenHeader joins repeated header values with ", "
+func flattenHeader(h http.Header) map[string]string {
+	flat := make(map[string]string, len(h))
+	for k, v := range h {
+		flat[k] = strings.Join(v, ", ")
+	}
+	return flat
+}
+
+// bodyRecorder keeps a copy of the first limit bytes written to the
+// response and counts the rest. Nothing is copied once the response turns
+// out to be binary.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	limit  int
+	size   int64
+	binary bool
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyRecorder) keep(data []byte) {
+	if w.size == 0 {
+		if ct := w.Header().Get("Content-Type"); ct != "" {
+			w.binary = isBinaryContentType(ct, nil)
+		}
+	}
+	w.size += int64(len(data))
+	if w.binary {
+		return
+	}
+	if room := w.limit - w.body.Len(); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+		w.body.Write(data)
+	}
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEventsCapturesHeadersAndBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	router := gin.New()
+	router.Use(Events(bus, nil, "/admin"))
+	router.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("X-Echo", "yes")
+		c.Data(http.StatusCreated, "application/json", body)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Body.String() != `{"id":1}` {
+		t.Fatalf("expected handler to read the full body, got %q", w.Body.String())
+	}
+
+	select {
+	case event := <-ch:
+		data := event.Data.(map[string]interface{})
+		if data["request_body"] != `{"id":1}` || data["response_body"] != `{"id":1}` {
+			t.Errorf("unexpected bodies: %v / %v", data["request_body"], data["response_body"])
+		}
+		if data["request_headers"].(map[string]string)["Content-Type"] != "application/json" {
+			t.Errorf("unexpected request headers: %v", data["request_headers"])
+		}
+		if data["response_headers"].(map[string]string)["X-Echo"] != "yes" {
+			t.Errorf("unexpected response headers: %v", data["response_headers"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a request event")
+	}
+}
+
+func TestEventsOmitsBinaryBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	png := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0xff, 0xfe}
+	router := gin.New()
+	router.Use(Events(bus, nil, "/admin"))
+	router.POST("/upload", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "text/plain", []byte(fmt.Sprintf("got %d bytes", len(body))))
+	})
+	router.GET("/avatar", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", png)
+	})
+	router.POST("/raw", func(c *gin.Context) {
+		io.ReadAll(c.Request.Body)
+		c.Status(http.StatusNoContent)
+	})
+
+	next := func() map[string]interface{} {
+		select {
+		case event := <-ch:
+			return event.Data.(map[string]interface{})
+		case <-time.After(time.Second):
+			t.Fatal("expected a request event")
+			return nil
+		}
+	}
+
+	// Binary request, text response; chunked so the size is counted
+	req := httptest.NewRequest(http.MethodPost, "/upload", io.MultiReader(bytes.NewReader(png)))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Body.String() != "got 10 bytes" {
+		t.Fatalf("expected handler to read the full body, got %q", w.Body.String())
+	}
+	data := next()
+	if _, ok := data["request_body"]; ok {
+		t.Errorf("binary request body was attached: %q", data["request_body"])
+	}
+	want := map[string]interface{}{"content_type": "application/x-protobuf", "size": int64(10)}
+	if got := data["request_body_binary"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("request_body_binary = %v, want %v", got, want)
+	}
+	if data["response_body"] != "got 10 bytes" {
+		t.Errorf("response_body = %v", data["response_body"])
+	}
+
+	// Text request, binary response
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/avatar", nil))
+	data = next()
+	if _, ok := data["response_body"]; ok {
+		t.Errorf("binary response body was attached: %q", data["response_body"])
+	}
+	want = map[string]interface{}{"content_type": "image/png", "size": int64(len(png))}
+	if got := data["response_body_binary"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("response_body_binary = %v, want %v", got, want)
+	}
+
+	// Without a content type, invalid UTF-8 is taken as binary
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/raw", bytes.NewReader(png)))
+	data = next()
+	if _, ok := data["request_body_binary"]; !ok {
+		t.Errorf("expected untyped non-UTF-8 request body to be binary, got %v", data["request_body"])
+	}
+}
+
+func TestEventsExclusions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	router := gin.New()
+	router.Use(Events(bus, []config.RecorderExclusion{
+		{Path: "/internal/**"},
+		{Path: "/users/*/avatar", Methods: []string{"get"}},
+		{Regex: `^/v\d+/ping$`},
+	}, "/admin"))
+	router.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		method, path string
+		recorded     bool
+	}{
+		{http.MethodGet, "/admin/config", false},
+		{http.MethodGet, "/admin", false},
+		{http.MethodGet, "/administrator", true},
+		{http.MethodGet, "/internal/a/b", false},
+		{http.MethodGet, "/users/7/avatar", false},
+		{http.MethodPut, "/users/7/avatar", true},
+		{http.MethodGet, "/users/7/x/avatar", true},
+		{http.MethodPost, "/v2/ping", false},
+		{http.MethodPost, "/v2/ping/x", true},
+	}
+	for _, tt := range tests {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		select {
+		case <-ch:
+			if !tt.recorded {
+				t.Errorf("%s %s: expected no event", tt.method, tt.path)
+			}
+		default:
+			if tt.recorded {
+				t.Errorf("%s %s: expected an event", tt.method, tt.path)
+			}
+		}
+	}
+}
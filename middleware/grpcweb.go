@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"mock-api-server/pkg/grpcweb"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GRPCWeb returns a gin middleware that lets browser gRPC clients call mock
+// endpoints. gRPC-Web and Connect streaming requests using the JSON codec are
+// unwrapped into plain JSON requests, and the mock response is framed back
+// with a status mapped from its HTTP status. Endpoints are configured with
+// the RPC path, e.g. POST /greet.v1.GreetService/Greet.
+func GRPCWeb(excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format, ok := grpcweb.Detect(c.GetHeader("Content-Type"))
+		if !ok {
+			c.Next()
+			return
+		}
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		if format.Codec != grpcweb.CodecJSON {
+			writeFramed(c, format, http.StatusNotImplemented, errorBody("only the json codec is supported"))
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if IsBodyTooLarge(err) {
+				writeFramed(c, format, http.StatusRequestEntityTooLarge, nil)
+				return
+			}
+		}
+		message, err := grpcweb.Decode(format, body)
+		if err != nil {
+			writeFramed(c, format, http.StatusBadRequest, errorBody(err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(message))
+		c.Request.ContentLength = int64(len(message))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		buffer := &responseBuffer{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buffer
+		c.Next()
+		c.Writer = buffer.ResponseWriter
+
+		writeFramed(c, format, buffer.status, buffer.body.Bytes())
+	}
+}
+
+// writeFramed sends a framed response; gRPC-Web and Connect streaming always
+// use HTTP 200 and report errors in the trailers
+func writeFramed(c *gin.Context, format grpcweb.Format, status int, body []byte) {
+	out := grpcweb.Encode(format, status, body)
+	c.Writer.Header().Set("Content-Type", format.ContentType)
+	c.Writer.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Write(out)
+	c.Abort()
+}
+
+func errorBody(message string) []byte {
+	body, _ := json.Marshal(gin.H{"message": message})
+	return body
+}
+
+// responseBuffer holds back the status and body of the wrapped handlers so
+// they can be framed once the handlers finish
+type responseBuffer struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (w *responseBuffer) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *responseBuffer) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *responseBuffer) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *responseBuffer) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *responseBuffer) Status() int {
+	return w.status
+}
+
+func (w *responseBuffer) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *responseBuffer) Written() bool {
+	return w.written
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func grpcFrame(payload string) []byte {
+	frame := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func TestGRPCWeb(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(GRPCWeb("/admin"))
+	router.POST("/greet.v1.GreetService/Greet", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		if c.GetHeader("Content-Type") != "application/json" || string(body) != `{"name":"Ann"}` {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": "unexpected request " + string(body)}})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"greeting": "Hello, Ann"})
+	})
+	router.POST("/greet.v1.GreetService/Fail", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": gin.H{"code": "NOT_FOUND", "message": "no greeting"}})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/greet.v1.GreetService/Greet", bytes.NewReader(grpcFrame(`{"name":"Ann"}`)))
+	req.Header.Set("Content-Type", "application/grpc-web+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/grpc-web+json" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	want := string(grpcFrame(`{"greeting":"Hello, Ann"}`))
+	if got := w.Body.String(); !strings.HasPrefix(got, want) || !strings.Contains(got, "grpc-status: 0") {
+		t.Errorf("unexpected body %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/greet.v1.GreetService/Fail", bytes.NewReader(grpcFrame(`{}`)))
+	req.Header.Set("Content-Type", "application/grpc-web+json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "grpc-status: 5\r\ngrpc-message: no greeting") {
+		t.Errorf("unexpected error response %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/greet.v1.GreetService/Greet", bytes.NewReader(grpcFrame("\x0a\x03Ann")))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "grpc-status: 12") {
+		t.Errorf("expected UNIMPLEMENTED for the proto codec, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHeaders returns a gin middleware that sets server.default_headers on
+// every response. They are set before the handler runs, so endpoint headers
+// with the same name take precedence.
+func DefaultHeaders(cfgManager *config.ConfigManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cfg := cfgManager.GetConfig(); cfg != nil {
+			for k, v := range cfg.Server.DefaultHeaders {
+				c.Header(k, v)
+			}
+		}
+		c.Next()
+	}
+}
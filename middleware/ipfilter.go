@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPFilter returns a gin middleware enforcing server.ip_filter on every
+// request except paths under the given prefixes. Deny entries win over allow
+// entries; a non-empty allow list rejects every client not on it.
+func IPFilter(cfgManager *config.ConfigManager, excludePrefixes ...string) gin.HandlerFunc {
+	var (
+		mu        sync.Mutex
+		parsedFor *config.Config
+		allow     []*net.IPNet
+		deny      []*net.IPNet
+	)
+
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		cfg := cfgManager.GetConfig()
+		if cfg == nil || (len(cfg.Server.IPFilter.Allow) == 0 && len(cfg.Server.IPFilter.Deny) == 0) {
+			c.Next()
+			return
+		}
+
+		// Parse the lists once per loaded config
+		mu.Lock()
+		if parsedFor != cfg {
+			allow = ParseCIDRs(cfg.Server.IPFilter.Allow)
+			deny = ParseCIDRs(cfg.Server.IPFilter.Deny)
+			parsedFor = cfg
+		}
+		allowList, denyList := allow, deny
+		mu.Unlock()
+
+		ip := net.ParseIP(c.ClientIP())
+		if ip == nil || containsIP(denyList, ip) || (len(allowList) > 0 && !containsIP(allowList, ip)) {
+			c.Set("matched_rule", "ip_filter")
+			AbortWithError(c, cfg, http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "Client IP is not allowed",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ParseCIDRs parses CIDR blocks or single IPs, skipping invalid entries
+func ParseCIDRs(entries []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				continue
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, n, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	return nets
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
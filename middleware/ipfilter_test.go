@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		filter     config.IPFilter
+		remoteAddr string
+		path       string
+		wantStatus int
+	}{
+		{"no filter", config.IPFilter{}, "203.0.113.9:1234", "/api", http.StatusOK},
+		{"allowed cidr", config.IPFilter{Allow: []string{"10.0.0.0/8"}}, "10.1.2.3:1234", "/api", http.StatusOK},
+		{"not in allow list", config.IPFilter{Allow: []string{"10.0.0.0/8"}}, "192.168.1.1:1234", "/api", http.StatusForbidden},
+		{"single ip allowed", config.IPFilter{Allow: []string{"192.168.1.1"}}, "192.168.1.1:1234", "/api", http.StatusOK},
+		{"deny wins over allow", config.IPFilter{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.5"}}, "10.0.0.5:1234", "/api", http.StatusForbidden},
+		{"deny only", config.IPFilter{Deny: []string{"10.0.0.0/24"}}, "10.0.1.1:1234", "/api", http.StatusOK},
+		{"excluded prefix", config.IPFilter{Allow: []string{"10.0.0.0/8"}}, "192.168.1.1:1234", "/admin/x", http.StatusOK},
+		{"excluded path itself", config.IPFilter{Allow: []string{"10.0.0.0/8"}}, "192.168.1.1:1234", "/admin", http.StatusOK},
+		{"prefix is not a segment", config.IPFilter{Allow: []string{"10.0.0.0/8"}}, "192.168.1.1:1234", "/administrator", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := config.NewConfigManager("")
+			cm.SetConfig(&config.Config{Server: config.ServerConfig{IPFilter: tt.filter}})
+
+			router := gin.New()
+			router.Use(IPFilter(cm, "/admin"))
+			router.GET("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIPFilterForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  string
+		wantStatus int
+	}{
+		{"spoofed header from client", nil, "10.0.0.5:1234", "203.0.113.9", http.StatusForbidden},
+		{"header from untrusted proxy", []string{"192.0.2.1"}, "192.0.2.2:1234", "10.0.0.5", http.StatusOK},
+		{"denied client behind trusted proxy", []string{"192.0.2.0/24"}, "192.0.2.1:1234", "10.0.0.5", http.StatusForbidden},
+		{"allowed client behind trusted proxy", []string{"192.0.2.0/24"}, "192.0.2.1:1234", "203.0.113.9", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := config.NewConfigManager("")
+			cm.SetConfig(&config.Config{Server: config.ServerConfig{IPFilter: config.IPFilter{Deny: []string{"10.0.0.5"}}}})
+
+			// As main.go sets up the router
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatalf("SetTrustedProxies: %v", err)
+			}
+			router.Use(IPFilter(cm))
+			router.GET("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
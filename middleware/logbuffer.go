@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultLogBufferSize = 1000
+
+// LogEntry is a structured log line kept in memory
+type LogEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Caller  string                 `json:"caller,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// LogBuffer keeps the most recent log entries in a ring buffer
+type LogBuffer struct {
+	mu      sync.RWMutex
+	entries []LogEntry
+	levels  []zapcore.Level
+	next    int
+	full    bool
+}
+
+// NewLogBuffer creates a LogBuffer holding at most size entries
+func NewLogBuffer(size int) *LogBuffer {
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+	return &LogBuffer{
+		entries: make([]LogEntry, size),
+		levels:  make([]zapcore.Level, size),
+	}
+}
+
+// Attach returns a logger that writes to both the original core and the buffer,
+// using the logger's own level
+func (b *LogBuffer) Attach(logger *zap.Logger) *zap.Logger {
+	bufferCore := &logBufferCore{buffer: b, LevelEnabler: logger.Level()}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, bufferCore)
+	}))
+}
+
+// Entries returns up to limit entries at or above minLevel, oldest first.
+// limit <= 0 returns all matching entries.
+func (b *LogBuffer) Entries(limit int, minLevel zapcore.Level) []LogEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	count := b.next
+	start := 0
+	if b.full {
+		count = len(b.entries)
+		start = b.next
+	}
+
+	var result []LogEntry
+	// Walk newest to oldest so the limit keeps the most recent entries
+	for i := count - 1; i >= 0; i-- {
+		idx := (start + i) % len(b.entries)
+		if b.levels[idx] < minLevel {
+			continue
+		}
+		result = append(result, b.entries[idx])
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	if result == nil {
+		result = []LogEntry{}
+	}
+	return result
+}
+
+func (b *LogBuffer) add(level zapcore.Level, entry LogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[b.next] = entry
+	b.levels[b.next] = level
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// logBufferCore is a zapcore.Core writing entries into a LogBuffer
+type logBufferCore struct {
+	zapcore.LevelEnabler
+	buffer *LogBuffer
+	fields []zapcore.Field
+}
+
+func (c *logBufferCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &logBufferCore{LevelEnabler: c.LevelEnabler, buffer: c.buffer, fields: combined}
+}
+
+func (c *logBufferCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *logBufferCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	logEntry := LogEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+	}
+	if entry.Caller.Defined {
+		logEntry.Caller = entry.Caller.TrimmedPath()
+	}
+	if len(enc.Fields) > 0 {
+		logEntry.Fields = enc.Fields
+	}
+
+	c.buffer.add(entry.Level, logEntry)
+	return nil
+}
+
+func (c *logBufferCore) Sync() error {
+	return nil
+}
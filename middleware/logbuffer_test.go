@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"testing"
+
+	"mock-api-server/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogBufferCapturesEntries(t *testing.T) {
+	buffer := NewLogBuffer(3)
+	base, err := NewLogger("info", "json", "", config.LogRotation{})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+	logger := buffer.Attach(base)
+
+	logger.Debug("dropped by level")
+	logger.Info("first", zap.String("path", "/a"))
+	logger.Warn("second")
+	logger.With(zap.Int("status", 500)).Error("third")
+	logger.Info("fourth")
+
+	entries := buffer.Entries(0, zapcore.DebugLevel)
+	if len(entries) != 3 {
+		t.Fatalf("expected ring to hold 3 entries, got %d", len(entries))
+	}
+	if entries[0].Message != "second" || entries[2].Message != "fourth" {
+		t.Fatalf("expected oldest-first order, got %+v", entries)
+	}
+	if entries[1].Fields["status"] != int64(500) {
+		t.Errorf("expected With fields to be captured, got %+v", entries[1].Fields)
+	}
+
+	warn := buffer.Entries(0, zapcore.WarnLevel)
+	if len(warn) != 2 || warn[0].Message != "second" || warn[1].Message != "third" {
+		t.Errorf("expected only warn and above, got %+v", warn)
+	}
+
+	if last := buffer.Entries(1, zapcore.DebugLevel); len(last) != 1 || last[0].Message != "fourth" {
+		t.Errorf("expected limit to keep the newest entry, got %+v", last)
+	}
+}
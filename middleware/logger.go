@@ -1,20 +1,42 @@
 package middleware
 
 import (
+	"math/rand"
+	"strings"
 	"time"
 
+	"mock-api-server/config"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-// Logger returns a gin middleware for logging requests
-func Logger(logger *zap.Logger, accessLog bool) gin.HandlerFunc {
+// logOverride is a parsed config.LogOverride
+type logOverride struct {
+	prefix   string
+	disabled bool
+	minLevel zapcore.Level
+	sample   float64
+}
+
+// Logger returns a gin middleware for logging requests. Overrides raise the
+// minimum level or sample successful requests for matching path prefixes.
+func Logger(logger *zap.Logger, accessLog bool, overrides []config.LogOverride) gin.HandlerFunc {
+	parsed := parseLogOverrides(overrides)
+
 	return func(c *gin.Context) {
 		if !accessLog {
 			c.Next()
 			return
 		}
 
+		override := findLogOverride(parsed, c.Request.URL.Path)
+		if override != nil && override.disabled {
+			c.Next()
+			return
+		}
+
 		// Start timer
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -44,6 +66,21 @@ func Logger(logger *zap.Logger, accessLog bool) gin.HandlerFunc {
 			fields = append(fields, zap.String("query", query))
 		}
 
+		if requestID := c.GetString("request_id"); requestID != "" {
+			fields = append(fields, zap.String("request_id", requestID))
+		}
+
+		if traceID := c.GetString("trace_id"); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
+		}
+
+		if tags := c.GetStringSlice("endpoint_tags"); len(tags) > 0 {
+			fields = append(fields, zap.Strings("endpoint_tags", tags))
+		}
+		if basePath := c.GetString("endpoint_base_path"); basePath != "" {
+			fields = append(fields, zap.String("endpoint_base_path", basePath))
+		}
+
 		if matchedRule != nil {
 			fields = append(fields, zap.Any("matched_rule", matchedRule))
 		}
@@ -52,17 +89,84 @@ func Logger(logger *zap.Logger, accessLog bool) gin.HandlerFunc {
 			fields = append(fields, zap.Any("response_file", responseFile))
 		}
 
+		// Match details are only set when logging.log_match_details is on
+		if values, ok := c.Get("selector_values"); ok {
+			fields = append(fields, zap.Any("selector_values", values))
+		}
+		if evaluations, ok := c.Get("rule_evaluations"); ok {
+			fields = append(fields, zap.Any("rule_evaluations", evaluations))
+		}
+		if step, ok := c.Get("scenario_step"); ok {
+			fields = append(fields, zap.Any("scenario_step", step))
+		}
+		violations, drifted := c.Get("contract_violations")
+		if drifted {
+			fields = append(fields, zap.Any("contract_violations", violations))
+		}
+
 		// Log based on status code
 		status := c.Writer.Status()
+		level := zapcore.InfoLevel
 		switch {
 		case status >= 500:
-			logger.Error("Request completed", fields...)
-		case status >= 400:
-			logger.Warn("Request completed", fields...)
+			level = zapcore.ErrorLevel
+		case status >= 400, drifted:
+			level = zapcore.WarnLevel
+		}
+
+		if !override.allows(level) {
+			return
+		}
+
+		if ce := logger.Check(level, "Request completed"); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+}
+
+// allows reports whether a request logged at level passes the override's
+// minimum level and sampling; a nil override allows everything
+func (o *logOverride) allows(level zapcore.Level) bool {
+	if o == nil {
+		return true
+	}
+	if level < o.minLevel {
+		return false
+	}
+	// Failed requests are always logged so sampling never hides errors
+	return level != zapcore.InfoLevel || o.sample <= 0 || rand.Float64() < o.sample
+}
+
+// parseLogOverrides converts config overrides. An empty level keeps every
+// request; unknown levels, which config validation rejects, fall back to info.
+func parseLogOverrides(overrides []config.LogOverride) []logOverride {
+	parsed := make([]logOverride, 0, len(overrides))
+	for _, o := range overrides {
+		p := logOverride{prefix: o.PathPrefix, minLevel: zapcore.DebugLevel, sample: o.Sample}
+		switch strings.ToLower(o.Level) {
+		case "":
+		case "none", "off":
+			p.disabled = true
 		default:
-			logger.Info("Request completed", fields...)
+			level, err := zapcore.ParseLevel(o.Level)
+			if err != nil {
+				level = zapcore.InfoLevel
+			}
+			p.minLevel = level
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed
+}
+
+// findLogOverride returns the first override whose prefix matches path
+func findLogOverride(overrides []logOverride, path string) *logOverride {
+	for i := range overrides {
+		if strings.HasPrefix(path, overrides[i].prefix) {
+			return &overrides[i]
 		}
 	}
+	return nil
 }
 
 // TextLogger returns a simple text-based logger for development
@@ -79,7 +183,7 @@ func TextLogger(accessLog bool) gin.HandlerFunc {
 }
 
 // NewLogger creates a new zap logger based on configuration
-func NewLogger(level, format, logFile string) (*zap.Logger, error) {
+func NewLogger(level, format, logFile string, rotation config.LogRotation) (*zap.Logger, error) {
 	var config zap.Config
 
 	if format == "json" {
@@ -102,6 +206,23 @@ func NewLogger(level, format, logFile string) (*zap.Logger, error) {
 		config.Level.SetLevel(zap.InfoLevel)
 	}
 
+	// Rotated files are written through lumberjack instead of a zap output path
+	if logFile != "" && rotation.MaxSizeMB > 0 {
+		writer, err := OpenLogWriter(logFile, rotation)
+		if err != nil {
+			return nil, err
+		}
+		encoder := zapcore.NewConsoleEncoder(config.EncoderConfig)
+		if config.Encoding == "json" {
+			encoder = zapcore.NewJSONEncoder(config.EncoderConfig)
+		}
+		fileCore := zapcore.NewCore(encoder, writer, config.Level)
+
+		return config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+
 	// Set output paths
 	if logFile != "" {
 		config.OutputPaths = []string{"stdout", logFile}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggerOverrides(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	core, logs := observer.New(zap.DebugLevel)
+	router := gin.New()
+	router.Use(Logger(zap.New(core), true, []config.LogOverride{
+		{PathPrefix: "/health", Level: "none"},
+		{PathPrefix: "/api/quiet", Level: "warn"},
+		{PathPrefix: "/api/sampled", Sample: 0.000001},
+	}))
+	router.GET("/*path", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		logged bool
+	}{
+		{"no override", "/api/other", true},
+		{"disabled", "/health", false},
+		{"disabled even on error", "/health?fail=1", false},
+		{"below minimum level", "/api/quiet", false},
+		{"at minimum level", "/api/quiet?fail=1", true},
+		{"sampled out", "/api/sampled", false},
+		{"errors bypass sampling", "/api/sampled?fail=1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := logs.Len()
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if logged := logs.Len() > before; logged != tt.logged {
+				t.Errorf("logged = %v, want %v", logged, tt.logged)
+			}
+		})
+	}
+}
+
+func TestParseLogOverridesLevels(t *testing.T) {
+	parsed := parseLogOverrides([]config.LogOverride{
+		{PathPrefix: "/a"},
+		{PathPrefix: "/b", Level: "warn"},
+		{PathPrefix: "/c", Level: "verbose"},
+	})
+	want := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.InfoLevel}
+	for i, p := range parsed {
+		if p.minLevel != want[i] {
+			t.Errorf("override %s: minLevel = %v, want %v", p.prefix, p.minLevel, want[i])
+		}
+	}
+}
+
+func TestNewLoggerWithRotation(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "server.log")
+	logger, err := NewLogger("info", "json", logFile, config.LogRotation{MaxSizeMB: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+
+	logger.Info("rotated output")
+	_ = logger.Sync()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("expected log file to be written: %v", err)
+	}
+	if !strings.Contains(string(data), "rotated output") {
+		t.Errorf("expected log entry in file, got %q", data)
+	}
+}
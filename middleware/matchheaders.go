@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MatchHeaders returns a gin middleware that, when server.match_headers is
+// on, reports how a response was produced in X-Mock-Matched-Rule,
+// X-Mock-Endpoint and X-Mock-Response-File headers. It applies to every
+// request except paths under the given prefixes.
+func MatchHeaders(cfgManager *config.ConfigManager, excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		if cfg := cfgManager.GetConfig(); cfg == nil || !cfg.Server.MatchHeaders {
+			c.Next()
+			return
+		}
+
+		// The match is only known once the handler responds, so the headers
+		// are added right before the status line is written
+		c.Writer = &matchHeaderWriter{ResponseWriter: c.Writer, c: c}
+		c.Next()
+	}
+}
+
+// matchHeaderWriter adds the match headers on the first write
+type matchHeaderWriter struct {
+	gin.ResponseWriter
+	c   *gin.Context
+	set bool
+}
+
+func (w *matchHeaderWriter) setHeaders() {
+	if w.set {
+		return
+	}
+	w.set = true
+	header := w.ResponseWriter.Header()
+	for name, key := range map[string]string{
+		"X-Mock-Matched-Rule":  "matched_rule",
+		"X-Mock-Endpoint":      "endpoint_id",
+		"X-Mock-Response-File": "response_file",
+	} {
+		if value := w.c.GetString(key); value != "" {
+			header.Set(name, value)
+		}
+	}
+}
+
+func (w *matchHeaderWriter) WriteHeader(code int) {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *matchHeaderWriter) WriteHeaderNow() {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *matchHeaderWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *matchHeaderWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
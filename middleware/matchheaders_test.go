@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatchHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, enabled := range []bool{true, false} {
+		cm := config.NewConfigManager("")
+		cm.SetConfig(&config.Config{Server: config.ServerConfig{MatchHeaders: enabled}})
+
+		router := gin.New()
+		router.Use(MatchHeaders(cm, "/admin"))
+		router.GET("/users", func(c *gin.Context) {
+			c.Set("endpoint_id", "list-users")
+			c.Set("matched_rule", "admins")
+			c.Set("response_file", "mocks/admins.json")
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		want := map[string]string{
+			"X-Mock-Matched-Rule":  "admins",
+			"X-Mock-Endpoint":      "list-users",
+			"X-Mock-Response-File": "mocks/admins.json",
+		}
+		for name, value := range want {
+			if !enabled {
+				value = ""
+			}
+			if got := w.Header().Get(name); got != value {
+				t.Errorf("enabled=%v: %s = %q, want %q", enabled, name, got, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import "strings"
+
+// excludedPath reports whether path is one of the prefixes or lies below
+// one. Whole segments are compared, so /admin covers /admin/config but not
+// /administrator.
+func excludedPath(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
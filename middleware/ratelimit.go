@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit returns a gin middleware enforcing server.rate_limit on every
+// request except paths under the given prefixes. The limit is read per
+// request, so hot reloads take effect immediately.
+func RateLimit(cfgManager *config.ConfigManager, limiter *ratelimit.Limiter, excludePrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if excludedPath(c.Request.URL.Path, excludePrefixes) {
+			c.Next()
+			return
+		}
+
+		cfg := cfgManager.GetConfig()
+		if cfg == nil || !CheckRateLimit(c, cfg, limiter, cfg.Server.RateLimit, "global") {
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckRateLimit takes a token for the request from the bucket selected by rl
+// within scope. When the bucket is empty it aborts with 429 and Retry-After.
+func CheckRateLimit(c *gin.Context, cfg *config.Config, limiter *ratelimit.Limiter, rl *config.RateLimit, scope string) bool {
+	if rl == nil || rl.RequestsPerSecond <= 0 {
+		return true
+	}
+
+	allowed, wait := limiter.Allow(scope+"|"+rateLimitKey(c, cfg, rl), rl.RequestsPerSecond, rl.Burst)
+	if allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.Set("matched_rule", "rate_limit")
+	AbortWithError(c, cfg, http.StatusTooManyRequests, gin.H{
+		"code":    "RATE_LIMITED",
+		"message": "Rate limit exceeded, retry after " + strconv.Itoa(retryAfter) + "s",
+	})
+	return false
+}
+
+// CheckQuota counts the request against the endpoint quota q within scope
+// and sets the X-RateLimit-* headers. Once the quota is used up it aborts
+// with 429 and Retry-After, or with q.Exceeded when set.
+func CheckQuota(c *gin.Context, cfg *config.Config, limiter *ratelimit.WindowLimiter, q *config.Quota, scope string) bool {
+	if q == nil || q.Limit <= 0 {
+		return true
+	}
+	window := time.Duration(q.WindowSec) * time.Second
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	key := rateLimitKey(c, cfg, &config.RateLimit{Key: q.Key, KeyHeader: q.KeyHeader})
+	result := limiter.Hit(scope+"|"+key, q.Limit, window)
+	reset := int(math.Ceil(result.Reset.Seconds()))
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(reset))
+	if result.Allowed {
+		return true
+	}
+
+	c.Header("Retry-After", strconv.Itoa(reset))
+	c.Set("matched_rule", "quota")
+	AbortWithResponse(c, cfg, q.Exceeded, http.StatusTooManyRequests, "QUOTA_EXCEEDED",
+		"Quota of "+strconv.Itoa(q.Limit)+" requests exceeded, retry after "+strconv.Itoa(reset)+"s")
+	return false
+}
+
+// rateLimitKey identifies the client a bucket belongs to
+func rateLimitKey(c *gin.Context, cfg *config.Config, rl *config.RateLimit) string {
+	switch rl.Key {
+	case "api_key":
+		header := rl.KeyHeader
+		if header == "" {
+			header = "X-API-Key"
+		}
+		if key := c.GetHeader(header); key != "" {
+			return "key:" + key
+		}
+		// Requests without a key share the limit of their client IP
+		return "ip:" + limitedIP(c, cfg)
+	case "endpoint":
+		return "endpoint:" + c.Request.Method + " " + c.Request.URL.Path
+	default:
+		return "ip:" + limitedIP(c, cfg)
+	}
+}
+
+// limitedIP returns the client IP requests are limited by. Forwarding
+// headers count only with server.trusted_proxies configured, so a client
+// cannot get a fresh bucket by sending another X-Forwarded-For.
+func limitedIP(c *gin.Context, cfg *config.Config) string {
+	if len(cfg.Server.TrustedProxies) == 0 {
+		return c.RemoteIP()
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	quota := &config.Quota{Limit: 2, WindowSec: 30}
+	limiter := ratelimit.NewWindowLimiter()
+
+	router := gin.New()
+	router.GET("/items", func(c *gin.Context) {
+		if !CheckQuota(c, cfg, limiter, quota, "endpoint:items") {
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for i, want := range []struct {
+		status    int
+		remaining string
+	}{
+		{http.StatusOK, "1"},
+		{http.StatusOK, "0"},
+		{http.StatusTooManyRequests, "0"},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+		if w.Code != want.status || w.Header().Get("X-RateLimit-Remaining") != want.remaining {
+			t.Errorf("call %d: status %d remaining %q, want %d %q", i, w.Code, w.Header().Get("X-RateLimit-Remaining"), want.status, want.remaining)
+		}
+		if w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Reset") != "30" {
+			t.Errorf("call %d: unexpected headers %v", i, w.Header())
+		}
+		if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "30" {
+			t.Errorf("expected Retry-After 30, got %q", w.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestRateLimitIgnoresForgedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("config.yaml")
+	cm.SetConfig(&config.Config{Server: config.ServerConfig{
+		RateLimit: &config.RateLimit{RequestsPerSecond: 1, Burst: 1},
+	}})
+
+	// gin trusts every proxy unless told otherwise
+	router := gin.New()
+	router.Use(RateLimit(cm, ratelimit.NewLimiter()))
+	router.GET("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113."+strconv.Itoa(i+1))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("request %d: status %d, want %d", i, w.Code, want)
+		}
+	}
+}
@@ -17,6 +17,7 @@ func Recovery(logger *zap.Logger, showDetails bool) gin.HandlerFunc {
 					zap.Any("error", err),
 					zap.String("path", c.Request.URL.Path),
 					zap.String("method", c.Request.Method),
+					zap.String("request_id", c.GetString("request_id")),
 				)
 
 				// Build response
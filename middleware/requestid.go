@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the correlation ID of a request
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID returns a gin middleware that keeps the caller's X-Request-ID or
+// generates one, stores it as "request_id" in the context and echoes it back.
+// The header is also set on the incoming request so it is forwarded upstream.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+			c.Request.Header.Set(RequestIDHeader, id)
+		}
+
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+var traceparentPattern = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// TraceContext returns a gin middleware that preserves W3C traceparent and
+// tracestate headers by echoing them on the response and storing the trace ID
+// as "trace_id". With server.trace_context.generate, requests without a valid
+// traceparent get a new one, set on the request so it is forwarded upstream.
+func TraceContext(cfgManager *config.ConfigManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceparent := c.GetHeader("traceparent")
+		traceID, valid := parseTraceparent(traceparent)
+
+		if !valid {
+			cfg := cfgManager.GetConfig()
+			if cfg == nil || !cfg.Server.TraceContext.Generate {
+				c.Next()
+				return
+			}
+			traceID = randomHex(16)
+			traceparent = "00-" + traceID + "-" + randomHex(8) + "-01"
+			c.Request.Header.Set("traceparent", traceparent)
+			// tracestate is meaningless without the traceparent it belonged to
+			c.Request.Header.Del("tracestate")
+		}
+
+		c.Set("trace_id", traceID)
+		c.Header("traceparent", traceparent)
+		if tracestate := c.GetHeader("tracestate"); tracestate != "" {
+			c.Header("tracestate", tracestate)
+		}
+		c.Next()
+	}
+}
+
+// parseTraceparent returns the trace ID of a valid version-00 style traceparent
+func parseTraceparent(value string) (string, bool) {
+	m := traceparentPattern.FindStringSubmatch(value)
+	if m == nil || m[1] == "00000000000000000000000000000000" || m[2] == "0000000000000000" {
+		return "", false
+	}
+	return m[1], true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
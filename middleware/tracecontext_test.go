@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTraceContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const incoming = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name        string
+		generate    bool
+		traceparent string
+		wantEcho    string // expected response traceparent, "generated" for a new one
+	}{
+		{"valid is preserved", false, incoming, incoming},
+		{"missing without generate", false, "", ""},
+		{"invalid without generate", false, "garbage", ""},
+		{"missing with generate", true, "", "generated"},
+		{"all-zero trace id is replaced", true, "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "generated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := config.NewConfigManager("")
+			cm.SetConfig(&config.Config{Server: config.ServerConfig{TraceContext: config.TraceContext{Generate: tt.generate}}})
+
+			var seen string
+			router := gin.New()
+			router.Use(TraceContext(cm))
+			router.GET("/x", func(c *gin.Context) {
+				seen = c.GetHeader("traceparent")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get("traceparent")
+			switch tt.wantEcho {
+			case "generated":
+				if _, ok := parseTraceparent(got); !ok || got == tt.traceparent {
+					t.Fatalf("expected a newly generated traceparent, got %q", got)
+				}
+				if seen != got {
+					t.Errorf("expected generated traceparent on the request, got %q", seen)
+				}
+			default:
+				if got != tt.wantEcho {
+					t.Errorf("traceparent = %q, want %q", got, tt.wantEcho)
+				}
+			}
+			if got != "" && !strings.HasPrefix(got, "00-") {
+				t.Errorf("unexpected version in %q", got)
+			}
+		})
+	}
+}
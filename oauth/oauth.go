@@ -0,0 +1,456 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	codeTTL         = 5 * time.Minute
+	refreshTokenTTL = 24 * time.Hour
+	defaultTokenTTL = 3600
+	defaultSubject  = "mock-user"
+)
+
+// grant is what an authorization code or refresh token was issued for
+type grant struct {
+	clientID            string
+	redirectURI         string
+	scope               string
+	nonce               string
+	codeChallenge       string
+	codeChallengeMethod string
+	expiresAt           time.Time
+}
+
+// Server is a simulated OAuth2/OIDC provider. It approves every authorization
+// request without a login page, so clients can run the full code flow unattended.
+type Server struct {
+	configManager *config.ConfigManager
+	signer        *signer
+
+	mu            sync.Mutex
+	codes         map[string]*grant
+	refreshTokens map[string]*grant
+}
+
+// NewServer creates a provider with a freshly generated signing key
+func NewServer(cfgManager *config.ConfigManager) (*Server, error) {
+	s, err := newSigner()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		configManager: cfgManager,
+		signer:        s,
+		codes:         make(map[string]*grant),
+		refreshTokens: make(map[string]*grant),
+	}, nil
+}
+
+// RegisterRoutes registers the provider endpoints under the configured path prefix
+func (s *Server) RegisterRoutes(r *gin.Engine) {
+	group := r.Group(s.settings().PathPrefix)
+
+	group.GET("/.well-known/openid-configuration", s.handleDiscovery)
+	group.GET("/jwks", s.handleJWKS)
+	group.GET("/authorize", s.handleAuthorize)
+	group.POST("/token", s.handleToken)
+	group.GET("/userinfo", s.handleUserInfo)
+}
+
+// settings returns the current OAuth config with defaults applied
+func (s *Server) settings() config.OAuthConfig {
+	var settings config.OAuthConfig
+	if cfg := s.configManager.GetConfig(); cfg != nil {
+		settings = cfg.OAuth
+	}
+	if settings.PathPrefix == "" {
+		settings.PathPrefix = "/oauth"
+	}
+	if settings.TokenTTLSec <= 0 {
+		settings.TokenTTLSec = defaultTokenTTL
+	}
+	if settings.Subject == "" {
+		settings.Subject = defaultSubject
+	}
+	return settings
+}
+
+// issuer returns the configured issuer, or one derived from the request
+func (s *Server) issuer(c *gin.Context, settings config.OAuthConfig) string {
+	if settings.Issuer != "" {
+		return strings.TrimSuffix(settings.Issuer, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host + strings.TrimSuffix(settings.PathPrefix, "/")
+}
+
+// handleDiscovery serves the OpenID Connect discovery document
+func (s *Server) handleDiscovery(c *gin.Context) {
+	issuer := s.issuer(c, s.settings())
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"userinfo_endpoint":                     issuer + "/userinfo",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "client_credentials", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256", "plain"},
+		"scopes_supported":                      []string{"openid", "profile", "email", "offline_access"},
+	})
+}
+
+// handleJWKS serves the public signing key
+func (s *Server) handleJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, s.signer.jwks())
+}
+
+// handleAuthorize approves the request immediately and redirects back with a code
+func (s *Server) handleAuthorize(c *gin.Context) {
+	settings := s.settings()
+	clientID := c.Query("client_id")
+	redirectURI := c.Query("redirect_uri")
+
+	client, known := findClient(settings.Clients, clientID)
+	if clientID == "" || (len(settings.Clients) > 0 && !known) {
+		oauthError(c, http.StatusBadRequest, "invalid_client", "unknown client_id")
+		return
+	}
+	if redirectURI == "" || (client != nil && len(client.RedirectURIs) > 0 && !contains(client.RedirectURIs, redirectURI)) {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "redirect_uri is missing or not registered")
+		return
+	}
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "redirect_uri is not a valid URL")
+		return
+	}
+
+	// From here on errors are reported to the client through the redirect
+	query := target.Query()
+	if state := c.Query("state"); state != "" {
+		query.Set("state", state)
+	}
+	redirect := func() {
+		target.RawQuery = query.Encode()
+		c.Redirect(http.StatusFound, target.String())
+	}
+
+	if c.Query("response_type") != "code" {
+		query.Set("error", "unsupported_response_type")
+		redirect()
+		return
+	}
+	scope := c.Query("scope")
+	if client != nil && !scopesAllowed(client.Scopes, scope) {
+		query.Set("error", "invalid_scope")
+		redirect()
+		return
+	}
+
+	code := randomToken()
+	s.storeGrant(s.codes, code, &grant{
+		clientID:            clientID,
+		redirectURI:         redirectURI,
+		scope:               scope,
+		nonce:               c.Query("nonce"),
+		codeChallenge:       c.Query("code_challenge"),
+		codeChallengeMethod: c.Query("code_challenge_method"),
+		expiresAt:           time.Now().Add(codeTTL),
+	})
+
+	query.Set("code", code)
+	redirect()
+}
+
+// handleToken issues tokens for the client_credentials, authorization_code
+// and refresh_token grants
+func (s *Server) handleToken(c *gin.Context) {
+	settings := s.settings()
+
+	clientID, clientSecret, hasBasic := c.Request.BasicAuth()
+	if !hasBasic {
+		clientID = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+	client, ok := authenticateClient(settings.Clients, clientID, clientSecret)
+	if !ok {
+		oauthError(c, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	switch c.PostForm("grant_type") {
+	case "client_credentials":
+		if client != nil && client.ClientSecret == "" {
+			oauthError(c, http.StatusBadRequest, "unauthorized_client", "public clients cannot use client_credentials")
+			return
+		}
+		scope := c.PostForm("scope")
+		if client != nil && !scopesAllowed(client.Scopes, scope) {
+			oauthError(c, http.StatusBadRequest, "invalid_scope", "requested scope is not allowed")
+			return
+		}
+		s.respondTokens(c, settings, &grant{clientID: clientID, scope: scope}, clientID, false)
+
+	case "authorization_code":
+		g := s.takeGrant(s.codes, c.PostForm("code"))
+		if g == nil || g.clientID != clientID {
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "authorization code is invalid or expired")
+			return
+		}
+		if redirectURI := c.PostForm("redirect_uri"); redirectURI != "" && redirectURI != g.redirectURI {
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request")
+			return
+		}
+		if !verifyPKCE(g, c.PostForm("code_verifier")) {
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "code_verifier does not match code_challenge")
+			return
+		}
+		s.respondTokens(c, settings, g, settings.Subject, true)
+
+	case "refresh_token":
+		g := s.takeGrant(s.refreshTokens, c.PostForm("refresh_token"))
+		if g == nil || g.clientID != clientID {
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "refresh token is invalid or expired")
+			return
+		}
+		s.respondTokens(c, settings, g, settings.Subject, true)
+
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be authorization_code, client_credentials or refresh_token")
+	}
+}
+
+// respondTokens signs an access token, plus an ID token for openid scopes and
+// a rotated refresh token for user grants
+func (s *Server) respondTokens(c *gin.Context, settings config.OAuthConfig, g *grant, subject string, user bool) {
+	issuer := s.issuer(c, settings)
+	now := time.Now()
+
+	claims := map[string]interface{}{
+		"iss":       issuer,
+		"sub":       subject,
+		"aud":       g.clientID,
+		"client_id": g.clientID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Duration(settings.TokenTTLSec) * time.Second).Unix(),
+		"jti":       randomToken(),
+	}
+	if g.scope != "" {
+		claims["scope"] = g.scope
+	}
+	if user {
+		for k, v := range settings.Claims {
+			if _, reserved := claims[k]; !reserved {
+				claims[k] = v
+			}
+		}
+	}
+
+	accessToken, err := s.signer.sign(claims)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	response := gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   settings.TokenTTLSec,
+	}
+	if g.scope != "" {
+		response["scope"] = g.scope
+	}
+
+	if user {
+		if hasScope(g.scope, "openid") {
+			idClaims := map[string]interface{}{
+				"iss": issuer,
+				"sub": subject,
+				"aud": g.clientID,
+				"iat": now.Unix(),
+				"exp": claims["exp"],
+			}
+			if g.nonce != "" {
+				idClaims["nonce"] = g.nonce
+			}
+			for k, v := range settings.Claims {
+				if _, reserved := idClaims[k]; !reserved {
+					idClaims[k] = v
+				}
+			}
+			idToken, err := s.signer.sign(idClaims)
+			if err != nil {
+				oauthError(c, http.StatusInternalServerError, "server_error", err.Error())
+				return
+			}
+			response["id_token"] = idToken
+		}
+
+		refreshToken := randomToken()
+		refreshed := *g
+		refreshed.expiresAt = now.Add(refreshTokenTTL)
+		s.storeGrant(s.refreshTokens, refreshToken, &refreshed)
+		response["refresh_token"] = refreshToken
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// handleUserInfo returns the claims of the user an access token was issued to
+func (s *Server) handleUserInfo(c *gin.Context) {
+	auth := c.GetHeader("Authorization")
+	if len(auth) <= 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		c.Header("WWW-Authenticate", `Bearer`)
+		oauthError(c, http.StatusUnauthorized, "invalid_token", "missing Bearer token")
+		return
+	}
+
+	claims, err := s.signer.verify(strings.TrimSpace(auth[7:]))
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oauthError(c, http.StatusUnauthorized, "invalid_token", err.Error())
+		return
+	}
+
+	info := gin.H{"sub": claims["sub"]}
+	for k, v := range s.settings().Claims {
+		info[k] = v
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// storeGrant saves g under key and drops expired entries
+func (s *Server) storeGrant(store map[string]*grant, key string, g *grant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, existing := range store {
+		if now.After(existing.expiresAt) {
+			delete(store, k)
+		}
+	}
+	store[key] = g
+}
+
+// takeGrant removes and returns the grant for key; codes and refresh tokens are single use
+func (s *Server) takeGrant(store map[string]*grant, key string) *grant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := store[key]
+	if !ok {
+		return nil
+	}
+	delete(store, key)
+	if time.Now().After(g.expiresAt) {
+		return nil
+	}
+	return g
+}
+
+// authenticateClient checks client credentials. With no clients configured any
+// non-empty client_id is accepted; public clients need no secret.
+func authenticateClient(clients []config.OAuthClient, clientID, secret string) (*config.OAuthClient, bool) {
+	if clientID == "" {
+		return nil, false
+	}
+	if len(clients) == 0 {
+		return nil, true
+	}
+
+	client, ok := findClient(clients, clientID)
+	if !ok {
+		return nil, false
+	}
+	if client.ClientSecret != "" && subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(secret)) != 1 {
+		return nil, false
+	}
+	return client, true
+}
+
+func findClient(clients []config.OAuthClient, clientID string) (*config.OAuthClient, bool) {
+	for i := range clients {
+		if clients[i].ClientID == clientID {
+			return &clients[i], true
+		}
+	}
+	return nil, false
+}
+
+// verifyPKCE checks the code_verifier against the challenge from /authorize
+func verifyPKCE(g *grant, verifier string) bool {
+	if g.codeChallenge == "" {
+		return true
+	}
+	if strings.EqualFold(g.codeChallengeMethod, "S256") {
+		sum := sha256.Sum256([]byte(verifier))
+		return base64.RawURLEncoding.EncodeToString(sum[:]) == g.codeChallenge
+	}
+	return verifier == g.codeChallenge
+}
+
+// scopesAllowed reports whether every requested scope is in allowed
+func scopesAllowed(allowed []string, scope string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, requested := range strings.Fields(scope) {
+		if !contains(allowed, requested) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasScope(scope, target string) bool {
+	return contains(strings.Fields(scope), target)
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// oauthError writes an RFC 6749 error response
+func oauthError(c *gin.Context, status int, code, description string) {
+	c.JSON(status, gin.H{
+		"error":             code,
+		"error_description": description,
+	})
+}
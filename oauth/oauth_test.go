@@ -0,0 +1,129 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T, oauthCfg config.OAuthConfig) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cm := config.NewConfigManager("")
+	oauthCfg.Enabled = true
+	cm.SetConfig(&config.Config{OAuth: oauthCfg})
+
+	s, err := NewServer(cm)
+	if err != nil {
+		t.Fatalf("NewServer returned error: %v", err)
+	}
+	router := gin.New()
+	s.RegisterRoutes(router)
+	return router
+}
+
+func postToken(router *gin.Engine, form url.Values) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	router := newTestRouter(t, config.OAuthConfig{
+		Claims:  map[string]interface{}{"email": "user@example.com"},
+		Clients: []config.OAuthClient{{ClientID: "spa", RedirectURIs: []string{"http://app/cb"}}},
+	})
+
+	verifier := "a-long-random-code-verifier"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oauth/authorize?response_type=code&client_id=spa&redirect_uri=http://app/cb&scope=openid&state=xyz&nonce=n1&code_challenge_method=S256&code_challenge="+challenge, nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected redirect, got %d: %s", w.Code, w.Body.String())
+	}
+	location, _ := url.Parse(w.Header().Get("Location"))
+	if location.Query().Get("state") != "xyz" {
+		t.Fatalf("expected state to be returned, got %s", location)
+	}
+	code := location.Query().Get("code")
+
+	w, body := postToken(router, url.Values{"grant_type": {"authorization_code"}, "client_id": {"spa"}, "code": {code}, "code_verifier": {"wrong"}})
+	if w.Code != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Fatalf("expected invalid_grant for wrong verifier, got %d %v", w.Code, body)
+	}
+
+	// The failed attempt consumed the code, so start over
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oauth/authorize?response_type=code&client_id=spa&redirect_uri=http://app/cb&scope=openid&code_challenge_method=S256&code_challenge="+challenge, nil))
+	location, _ = url.Parse(w.Header().Get("Location"))
+	code = location.Query().Get("code")
+
+	w, body = postToken(router, url.Values{"grant_type": {"authorization_code"}, "client_id": {"spa"}, "code": {code}, "code_verifier": {verifier}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected tokens, got %d %v", w.Code, body)
+	}
+	if body["id_token"] == nil || body["refresh_token"] == nil {
+		t.Fatalf("expected id_token and refresh_token, got %v", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+body["access_token"].(string))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "user@example.com") {
+		t.Fatalf("unexpected userinfo response %d: %s", w.Code, w.Body.String())
+	}
+
+	w, body = postToken(router, url.Values{"grant_type": {"refresh_token"}, "client_id": {"spa"}, "refresh_token": {body["refresh_token"].(string)}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected refresh to succeed, got %d %v", w.Code, body)
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	router := newTestRouter(t, config.OAuthConfig{
+		Clients: []config.OAuthClient{{ClientID: "svc", ClientSecret: "s3cret", Scopes: []string{"read"}}},
+	})
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		wantStatus int
+		wantError  string
+	}{
+		{"valid", url.Values{"grant_type": {"client_credentials"}, "client_id": {"svc"}, "client_secret": {"s3cret"}, "scope": {"read"}}, http.StatusOK, ""},
+		{"wrong secret", url.Values{"grant_type": {"client_credentials"}, "client_id": {"svc"}, "client_secret": {"nope"}}, http.StatusUnauthorized, "invalid_client"},
+		{"unknown client", url.Values{"grant_type": {"client_credentials"}, "client_id": {"other"}}, http.StatusUnauthorized, "invalid_client"},
+		{"scope not allowed", url.Values{"grant_type": {"client_credentials"}, "client_id": {"svc"}, "client_secret": {"s3cret"}, "scope": {"write"}}, http.StatusBadRequest, "invalid_scope"},
+		{"unsupported grant", url.Values{"grant_type": {"password"}, "client_id": {"svc"}, "client_secret": {"s3cret"}}, http.StatusBadRequest, "unsupported_grant_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, body := postToken(router, tt.form)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", w.Code, tt.wantStatus, body)
+			}
+			if tt.wantError != "" && body["error"] != tt.wantError {
+				t.Errorf("error = %v, want %s", body["error"], tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package bundle packs a config and every file it refers to into a single
+// gzipped tar archive, so a complete mock definition can be shared as one
+// artifact. The archive holds config.yaml, whose file references point into
+// files/, next to the files themselves.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mock-api-server/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigName is the config document inside a bundle
+const ConfigName = "config.yaml"
+
+const (
+	filesDir    = "files"
+	maxFileSize = 64 << 20 // per archive entry
+)
+
+// Export writes cfg and the files it refers to as a bundle. Admin
+// credentials are left out. Files that cannot be read keep their original
+// path, so validating the imported config reports them.
+func Export(w io.Writer, cfg *config.Config) error {
+	bundled, err := clone(cfg)
+	if err != nil {
+		return err
+	}
+	bundled.Admin.Auth = config.AdminAuth{}
+
+	files := make(map[string]string) // name in bundle -> path on disk
+	names := make(map[string]string) // path on disk -> name in bundle
+	bundled.MapFilePaths(func(p string) string {
+		if name, ok := names[p]; ok {
+			return name
+		}
+		if info, err := os.Stat(p); err != nil || !info.Mode().IsRegular() {
+			return p
+		}
+		name := bundleName(p, files)
+		files[name] = p
+		names[p] = name
+		return name
+	})
+
+	doc, err := yaml.Marshal(bundled)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, ConfigName, doc); err != nil {
+		return err
+	}
+	ordered := make([]string, 0, len(files))
+	for name := range files {
+		ordered = append(ordered, name)
+	}
+	sort.Strings(ordered)
+	for _, name := range ordered {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", files[name], err)
+		}
+		if err := writeEntry(tw, name, data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Import extracts a bundle into dir and returns its config, with file
+// references pointing at the extracted files. The rewritten config is also
+// saved as dir/config.yaml, so it can be served with -config.
+func Import(r io.Reader, dir string) (*config.Config, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	defer gz.Close()
+
+	var doc []byte
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, ok := cleanName(hdr.Name)
+		if !ok {
+			return nil, fmt.Errorf("invalid bundle entry '%s'", hdr.Name)
+		}
+		if hdr.Size > maxFileSize {
+			return nil, fmt.Errorf("bundle entry '%s' is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if name == ConfigName {
+			doc = data
+			continue
+		}
+		if !strings.HasPrefix(name, filesDir+"/") {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if doc == nil {
+		return nil, errors.New("invalid bundle: no " + ConfigName)
+	}
+
+	cfg, err := config.ParseConfig(doc, filepath.Join(dir, ConfigName))
+	if err != nil {
+		return nil, err
+	}
+	cfg.MapFilePaths(func(p string) string {
+		if name, ok := cleanName(p); ok && strings.HasPrefix(name, filesDir+"/") {
+			return filepath.Join(dir, filepath.FromSlash(name))
+		}
+		return p
+	})
+
+	rewritten, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	configPath := filepath.Join(dir, ConfigName)
+	if err := os.WriteFile(configPath, rewritten, 0o644); err != nil {
+		return nil, err
+	}
+	// Parse the saved document so issues are reported against it
+	return config.ParseConfig(rewritten, configPath)
+}
+
+// clone deep-copies cfg through YAML, which is also how it is bundled
+func clone(cfg *config.Config) (*config.Config, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return config.ParseConfig(data, "")
+}
+
+// bundleName places a file under files/, keeping its relative path when it
+// stays below the working directory
+func bundleName(p string, taken map[string]string) string {
+	rel := filepath.ToSlash(filepath.Clean(p))
+	if filepath.IsAbs(p) || rel == ".." || strings.HasPrefix(rel, "../") {
+		rel = "external/" + path.Base(rel)
+	}
+	name := path.Join(filesDir, rel)
+	for i := 2; taken[name] != ""; i++ {
+		ext := path.Ext(rel)
+		name = path.Join(filesDir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(rel, ext), i, ext))
+	}
+	return name
+}
+
+// cleanName validates an archive entry name, rejecting absolute paths and
+// paths escaping the bundle
+func cleanName(name string) (string, bool) {
+	cleaned := path.Clean(filepath.ToSlash(name))
+	if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+	return cleaned, true
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,106 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	user := write("user.json", `{"name":"alice"}`)
+	otherUser := write("other/user.json", `{"name":"bob"}`)
+	notFound := write("not_found.json", `{"error":"missing"}`)
+
+	cfg := &config.Config{
+		Admin: config.AdminConfig{Enabled: true, Auth: config.AdminAuth{Tokens: []string{"secret"}}},
+		Endpoints: []config.Endpoint{{
+			Path:    "/users",
+			Method:  config.MethodAny,
+			Default: config.ResponseConfig{ResponseFile: user, StatusCode: 200},
+			Rules: []config.Rule{{
+				ResponseConfig: config.ResponseConfig{ResponseFile: otherUser, StatusCode: 200},
+			}},
+			MethodDefaults: map[string]config.ResponseConfig{"POST": {ResponseFile: user}},
+		}},
+	}
+	cfg.Server.ErrorHandling.CustomErrorResponses = map[int]string{404: notFound}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, cfg); err != nil {
+		t.Fatalf("Export returned error: %v", err)
+	}
+	if cfg.Endpoints[0].Default.ResponseFile != user {
+		t.Errorf("Export changed the exported config")
+	}
+
+	dir := t.TempDir()
+	imported, err := Import(bytes.NewReader(buf.Bytes()), dir)
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if issues := config.Validate(imported); len(issues) > 0 {
+		t.Errorf("imported config has issues: %v", issues)
+	}
+	if len(imported.Admin.Auth.Tokens) != 0 {
+		t.Errorf("admin credentials were bundled")
+	}
+
+	ep := imported.Endpoints[0]
+	for _, tt := range []struct {
+		file, want string
+	}{
+		{ep.Default.ResponseFile, `{"name":"alice"}`},
+		{ep.Rules[0].ResponseFile, `{"name":"bob"}`},
+		{ep.MethodDefaults["POST"].ResponseFile, `{"name":"alice"}`},
+		{imported.Server.ErrorHandling.CustomErrorResponses[404], `{"error":"missing"}`},
+	} {
+		if !strings.HasPrefix(tt.file, dir) {
+			t.Errorf("%s is not inside %s", tt.file, dir)
+			continue
+		}
+		data, err := os.ReadFile(tt.file)
+		if err != nil || string(data) != tt.want {
+			t.Errorf("%s = %q, %v; want %q", tt.file, data, err, tt.want)
+		}
+	}
+	if ep.Default.ResponseFile == ep.Rules[0].ResponseFile {
+		t.Errorf("files with the same name were bundled as one")
+	}
+
+	saved, err := config.LoadConfig(filepath.Join(dir, ConfigName))
+	if err != nil || saved.Endpoints[0].Default.ResponseFile != ep.Default.ResponseFile {
+		t.Errorf("saved config does not match: %v", err)
+	}
+}
+
+func TestImportRejectsEscapingEntries(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, "../evil.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gz.Close()
+
+	if _, err := Import(&buf, t.TempDir()); err == nil || !strings.Contains(err.Error(), "invalid bundle entry") {
+		t.Errorf("expected invalid bundle entry error, got %v", err)
+	}
+}
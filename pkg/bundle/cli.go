@@ -0,0 +1,95 @@
+package bundle
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"mock-api-server/config"
+)
+
+const bundleUsage = `Usage:
+  mock-api-server bundle export [-config config.yaml] [-o mock-bundle.tar.gz]
+  mock-api-server bundle import [-dir ./mock-bundle] mock-bundle.tar.gz
+`
+
+// Run implements the bundle subcommand on its arguments and returns the
+// exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, bundleUsage)
+		return 2
+	}
+	switch args[0] {
+	case "export":
+		return runExport(args[1:], stdout, stderr)
+	case "import":
+		return runImport(args[1:], stdout, stderr)
+	}
+	fmt.Fprint(stderr, bundleUsage)
+	return 2
+}
+
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "config.yaml", "Path to configuration file")
+	out := fs.String("o", "mock-bundle.tar.gz", "Bundle to write")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "bundle export: %v\n", err)
+		return 1
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(stderr, "bundle export: %v\n", err)
+		return 1
+	}
+	err = Export(f, cfg)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		fmt.Fprintf(stderr, "bundle export: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Wrote %s\n", *out)
+	return 0
+}
+
+func runImport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bundle import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", "mock-bundle", "Directory to extract into")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(stderr, bundleUsage)
+		return 2
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "bundle import: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+	cfg, err := Import(f, *dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "bundle import: %v\n", err)
+		return 1
+	}
+	for _, issue := range config.Validate(cfg) {
+		fmt.Fprintf(stderr, "[WARN] %s\n", issue)
+	}
+	fmt.Fprintf(stdout, "Extracted %d endpoints to %s; serve with -config %s\n", len(cfg.Endpoints), *dir, filepath.Join(*dir, ConfigName))
+	return 0
+}
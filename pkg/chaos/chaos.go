@@ -0,0 +1,154 @@
+package chaos
+
+import (
+	"math/rand"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Settings are global fault injection overrides applied to mock endpoints
+type Settings struct {
+	LatencyMs       int         `json:"latency_ms"`                  // extra latency added to every affected request
+	LatencyJitterMs int         `json:"latency_jitter_ms,omitempty"` // random extra latency up to this many ms
+	LatencyRate     float64     `json:"latency_rate,omitempty"`      // percentage (0-100) of requests delayed, 0 means all
+	ErrorRate       float64     `json:"error_rate"`                  // percentage (0-100) of affected requests that fail
+	ErrorStatus     int         `json:"error_status"`                // status for injected errors, default 500
+	ErrorStatuses   map[int]int `json:"error_statuses,omitempty"`    // status -> weight, overrides error_status
+	Paths           []string    `json:"paths,omitempty"`             // path prefixes or glob patterns, empty means all
+	ExpiresAt       *time.Time  `json:"expires_at,omitempty"`        // settings are ignored after this time
+}
+
+// Decision is the fault to apply to a single request
+type Decision struct {
+	Delay       time.Duration
+	ErrorStatus int // 0 means no error
+}
+
+// Controller holds the active chaos settings
+type Controller struct {
+	mu       sync.RWMutex
+	settings *Settings
+}
+
+// NewController creates a Controller with chaos disabled
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// Set replaces the active settings
+func (c *Controller) Set(settings Settings) {
+	if settings.ErrorStatus == 0 {
+		settings.ErrorStatus = http.StatusInternalServerError
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settings = &settings
+}
+
+// Get returns the active settings, or false when chaos is off or expired
+func (c *Controller) Get() (Settings, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.settings == nil || c.settings.expired(time.Now()) {
+		return Settings{}, false
+	}
+	return *c.settings, true
+}
+
+// Clear disables chaos
+func (c *Controller) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settings = nil
+}
+
+// Decide returns the fault to inject for a request path
+func (c *Controller) Decide(requestPath string) Decision {
+	settings, ok := c.Get()
+	if !ok {
+		return Decision{}
+	}
+	return settings.Decide(requestPath)
+}
+
+// Decide returns the fault these settings inject for a request path
+func (s Settings) Decide(requestPath string) Decision {
+	if s.expired(time.Now()) || !s.affects(requestPath) {
+		return Decision{}
+	}
+
+	var decision Decision
+	if s.LatencyRate <= 0 || rand.Float64()*100 < s.LatencyRate {
+		delayMs := s.LatencyMs
+		if s.LatencyJitterMs > 0 {
+			delayMs += rand.Intn(s.LatencyJitterMs + 1)
+		}
+		decision.Delay = time.Duration(delayMs) * time.Millisecond
+	}
+	if s.ErrorRate > 0 && rand.Float64()*100 < s.ErrorRate {
+		decision.ErrorStatus = s.pickErrorStatus()
+	}
+	return decision
+}
+
+// pickErrorStatus selects a status from ErrorStatuses by weight
+func (s *Settings) pickErrorStatus() int {
+	total := 0
+	statuses := make([]int, 0, len(s.ErrorStatuses))
+	for status, weight := range s.ErrorStatuses {
+		if weight > 0 {
+			statuses = append(statuses, status)
+			total += weight
+		}
+	}
+	if total == 0 {
+		if s.ErrorStatus == 0 {
+			return http.StatusInternalServerError
+		}
+		return s.ErrorStatus
+	}
+
+	// Sort so the same random draw always maps to the same status
+	sort.Ints(statuses)
+	n := rand.Intn(total)
+	for _, status := range statuses {
+		n -= s.ErrorStatuses[status]
+		if n < 0 {
+			return status
+		}
+	}
+	return statuses[len(statuses)-1]
+}
+
+func (s *Settings) expired(now time.Time) bool {
+	return s.ExpiresAt != nil && now.After(*s.ExpiresAt)
+}
+
+// affects reports whether the settings apply to a request path
+func (s *Settings) affects(requestPath string) bool {
+	if len(s.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range s.Paths {
+		if MatchPath(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath matches a request path against a glob pattern (when it contains
+// *, ? or [) or otherwise a plain prefix
+func MatchPath(pattern, requestPath string) bool {
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, requestPath)
+		return err == nil && matched
+	}
+	return strings.HasPrefix(requestPath, pattern)
+}
@@ -0,0 +1,79 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestControllerDecide(t *testing.T) {
+	c := NewController()
+	if d := c.Decide("/api"); d.Delay != 0 || d.ErrorStatus != 0 {
+		t.Fatalf("expected no fault when chaos is off, got %+v", d)
+	}
+
+	c.Set(Settings{LatencyMs: 50, ErrorRate: 100, Paths: []string{"/api/"}})
+	d := c.Decide("/api/users")
+	if d.Delay != 50*time.Millisecond || d.ErrorStatus != 500 {
+		t.Fatalf("expected 50ms delay and default 500 error, got %+v", d)
+	}
+	if d := c.Decide("/health"); d.Delay != 0 || d.ErrorStatus != 0 {
+		t.Fatalf("expected unaffected path to pass through, got %+v", d)
+	}
+
+	c.Clear()
+	if _, ok := c.Get(); ok {
+		t.Fatal("expected chaos to be off after Clear")
+	}
+}
+
+func TestControllerExpiry(t *testing.T) {
+	c := NewController()
+	past := time.Now().Add(-time.Second)
+	c.Set(Settings{LatencyMs: 10, ExpiresAt: &past})
+
+	if _, ok := c.Get(); ok {
+		t.Fatal("expected expired settings to be inactive")
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/api", "/api/v1/users", true},
+		{"/api/*/users", "/api/v1/users", true},
+		{"/api/*/users", "/api/v1/orders", false},
+		{"/health", "/api", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSettingsErrorStatusDistribution(t *testing.T) {
+	s := Settings{ErrorRate: 100, ErrorStatuses: map[int]int{502: 1, 503: 1}}
+
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		d := s.Decide("/any")
+		if d.ErrorStatus != 502 && d.ErrorStatus != 503 {
+			t.Fatalf("unexpected status %d", d.ErrorStatus)
+		}
+		seen[d.ErrorStatus] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected both weighted statuses to be chosen, got %v", seen)
+	}
+
+	s = Settings{LatencyMs: 10, LatencyJitterMs: 5}
+	for i := 0; i < 50; i++ {
+		d := s.Decide("/any")
+		if d.Delay < 10*time.Millisecond || d.Delay > 15*time.Millisecond {
+			t.Fatalf("delay %v outside latency_ms + jitter", d.Delay)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types published on the bus
+const (
+	TypeRequest            = "request"
+	TypeConfigReloaded     = "config_reloaded"
+	TypeEndpointAdded      = "endpoint_added"
+	TypeEndpointUpdated    = "endpoint_updated"
+	TypeEndpointDeleted    = "endpoint_deleted"
+	TypeScenarioTransition = "scenario_transition"
+	TypeReset              = "reset"
+	TypeMetrics            = "metrics" // periodic /admin/metrics snapshot while someone is subscribed
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before further events are dropped for it
+const subscriberBuffer = 64
+
+// Event is a single notification about server activity
+type Event struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Bus fans out events to all current subscribers without blocking publishers
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus creates an empty Bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving future events and a function to unsubscribe
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends an event to every subscriber. Subscribers whose buffer is
+// full miss the event rather than slowing down request handling.
+func (b *Bus) Publish(eventType string, data interface{}) {
+	if b == nil {
+		return
+	}
+
+	event := Event{Type: eventType, Time: time.Now(), Data: data}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (b *Bus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
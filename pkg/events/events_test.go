@@ -0,0 +1,45 @@
+package events
+
+import "testing"
+
+func TestBusPublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	ch, unsubscribe := bus.Subscribe()
+
+	bus.Publish(TypeConfigReloaded, map[string]int{"endpoints_count": 3})
+
+	event := <-ch
+	if event.Type != TypeConfigReloaded {
+		t.Fatalf("expected %s event, got %s", TypeConfigReloaded, event.Type)
+	}
+	if event.Time.IsZero() {
+		t.Fatal("expected event time to be set")
+	}
+
+	unsubscribe()
+	unsubscribe() // must be safe to call twice
+	if bus.SubscriberCount() != 0 {
+		t.Fatalf("expected no subscribers, got %d", bus.SubscriberCount())
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel to be closed after unsubscribe")
+	}
+}
+
+func TestBusDropsWhenSubscriberIsFull(t *testing.T) {
+	bus := NewBus()
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.Publish(TypeRequest, i)
+	}
+	if len(ch) != subscriberBuffer {
+		t.Fatalf("expected %d buffered events, got %d", subscriberBuffer, len(ch))
+	}
+}
+
+func TestNilBusPublish(t *testing.T) {
+	var bus *Bus
+	bus.Publish(TypeRequest, nil) // must not panic
+}
@@ -0,0 +1,215 @@
+// Package grpcweb converts between gRPC-Web / Connect streaming framing and
+// the plain JSON bodies the mock handler works with. Only the JSON codec is
+// supported: protobuf payloads cannot be decoded without the service schema.
+package grpcweb
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Protocols recognised by Detect
+const (
+	ProtocolGRPCWeb = "grpc-web"
+	ProtocolConnect = "connect"
+)
+
+// CodecJSON is the only message codec the server can translate
+const CodecJSON = "json"
+
+const (
+	headerLen      = 5
+	flagCompressed = 0x01
+	flagEndStream  = 0x02 // Connect end-of-stream envelope
+	flagTrailer    = 0x80 // gRPC-Web trailer frame
+)
+
+// gRPC status codes used when mapping HTTP statuses
+const (
+	CodeOK                 = 0
+	CodeUnknown            = 2
+	CodeInvalidArgument    = 3
+	CodeDeadlineExceeded   = 4
+	CodeNotFound           = 5
+	CodeAlreadyExists      = 6
+	CodePermissionDenied   = 7
+	CodeResourceExhausted  = 8
+	CodeFailedPrecondition = 9
+	CodeUnimplemented      = 12
+	CodeInternal           = 13
+	CodeUnavailable        = 14
+	CodeUnauthenticated    = 16
+)
+
+// connectCodes are the Connect protocol names of the gRPC status codes
+var connectCodes = map[int]string{
+	1: "canceled", 2: "unknown", 3: "invalid_argument", 4: "deadline_exceeded",
+	5: "not_found", 6: "already_exists", 7: "permission_denied", 8: "resource_exhausted",
+	9: "failed_precondition", 10: "aborted", 11: "out_of_range", 12: "unimplemented",
+	13: "internal", 14: "unavailable", 15: "data_loss", 16: "unauthenticated",
+}
+
+// Format describes the framing of a request
+type Format struct {
+	Protocol    string
+	Codec       string // json or proto
+	Text        bool   // grpc-web-text: frames are base64 encoded
+	ContentType string // echoed on the response
+}
+
+// Detect recognises gRPC-Web (binary and text) and Connect streaming
+// requests by content type. Connect unary calls are plain JSON and need no
+// translation.
+func Detect(contentType string) (Format, bool) {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case strings.HasPrefix(mediaType, "application/grpc-web-text"):
+		return Format{Protocol: ProtocolGRPCWeb, Codec: codecOf(mediaType), Text: true, ContentType: mediaType}, true
+	case strings.HasPrefix(mediaType, "application/grpc-web"):
+		return Format{Protocol: ProtocolGRPCWeb, Codec: codecOf(mediaType), ContentType: mediaType}, true
+	case strings.HasPrefix(mediaType, "application/connect+"):
+		return Format{Protocol: ProtocolConnect, Codec: codecOf(mediaType), ContentType: mediaType}, true
+	}
+	return Format{}, false
+}
+
+// codecOf returns the "+codec" suffix of a media type; gRPC defaults to proto
+func codecOf(mediaType string) string {
+	if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+		return mediaType[i+1:]
+	}
+	return "proto"
+}
+
+// Decode extracts the first message of a framed request body. An empty body
+// decodes to an empty message.
+func Decode(f Format, body []byte) ([]byte, error) {
+	if f.Text {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(body)))
+		if err != nil {
+			return nil, errors.New("invalid base64 in grpc-web-text body")
+		}
+		body = decoded
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if len(body) < headerLen {
+		return nil, errors.New("truncated frame header")
+	}
+	if body[0]&flagCompressed != 0 {
+		return nil, errors.New("compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(body[1:headerLen])
+	if uint64(len(body)-headerLen) < uint64(size) {
+		return nil, errors.New("truncated frame")
+	}
+	return body[headerLen : headerLen+int(size)], nil
+}
+
+// Encode frames a mock response. Successful responses carry body as the
+// single message; errors carry no message and report the status mapped from
+// the HTTP status, with a message taken from the error body.
+func Encode(f Format, status int, body []byte) []byte {
+	code := CodeFromHTTP(status)
+
+	var out []byte
+	if code == CodeOK {
+		out = appendFrame(out, 0, body)
+	}
+
+	message := ""
+	if code != CodeOK {
+		message = errorMessage(status, body)
+	}
+
+	if f.Protocol == ProtocolConnect {
+		end := map[string]interface{}{}
+		if code != CodeOK {
+			end["error"] = map[string]string{"code": connectCodes[code], "message": message}
+		}
+		data, _ := json.Marshal(end)
+		return appendFrame(out, flagEndStream, data)
+	}
+
+	trailers := "grpc-status: " + strconv.Itoa(code) + "\r\n"
+	if message != "" {
+		trailers += "grpc-message: " + percentEncode(message) + "\r\n"
+	}
+	out = appendFrame(out, flagTrailer, []byte(trailers))
+	if f.Text {
+		return []byte(base64.StdEncoding.EncodeToString(out))
+	}
+	return out
+}
+
+func appendFrame(out []byte, flag byte, payload []byte) []byte {
+	var header [headerLen]byte
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	out = append(out, header[:]...)
+	return append(out, payload...)
+}
+
+// CodeFromHTTP maps an HTTP status to the closest gRPC status code
+func CodeFromHTTP(status int) int {
+	switch {
+	case status >= 200 && status < 300:
+		return CodeOK
+	case status == http.StatusBadRequest:
+		return CodeInvalidArgument
+	case status == http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case status == http.StatusForbidden:
+		return CodePermissionDenied
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status == http.StatusConflict:
+		return CodeAlreadyExists
+	case status == http.StatusPreconditionFailed:
+		return CodeFailedPrecondition
+	case status == http.StatusRequestEntityTooLarge, status == http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case status == http.StatusNotImplemented, status == http.StatusMethodNotAllowed:
+		return CodeUnimplemented
+	case status == http.StatusServiceUnavailable, status == http.StatusBadGateway:
+		return CodeUnavailable
+	case status == http.StatusGatewayTimeout, status == http.StatusRequestTimeout:
+		return CodeDeadlineExceeded
+	case status >= 500:
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+// errorMessage uses error.message or message from a JSON error body and
+// falls back to the HTTP status text
+func errorMessage(status int, body []byte) string {
+	for _, path := range []string{"error.message", "message", "error"} {
+		if v := gjson.GetBytes(body, path); v.Type == gjson.String && v.Str != "" {
+			return v.Str
+		}
+	}
+	return http.StatusText(status)
+}
+
+// percentEncode escapes grpc-message as the gRPC spec requires
+func percentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= 0x20 && ch <= 0x7e && ch != '%' {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(strconv.FormatInt(int64(ch)|0x100, 16)[1:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package grpcweb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		contentType string
+		protocol    string
+		codec       string
+		text        bool
+		ok          bool
+	}{
+		{"application/grpc-web+json", ProtocolGRPCWeb, "json", false, true},
+		{"application/grpc-web-text+json; charset=utf-8", ProtocolGRPCWeb, "json", true, true},
+		{"application/grpc-web", ProtocolGRPCWeb, "proto", false, true},
+		{"application/connect+json", ProtocolConnect, "json", false, true},
+		{"application/json", "", "", false, false},
+	}
+	for _, tt := range tests {
+		f, ok := Detect(tt.contentType)
+		if ok != tt.ok || f.Protocol != tt.protocol || f.Codec != tt.codec || f.Text != tt.text {
+			t.Errorf("Detect(%q) = %+v, %v", tt.contentType, f, ok)
+		}
+	}
+}
+
+func TestDecodeEncode(t *testing.T) {
+	f, _ := Detect("application/grpc-web+json")
+	framed := appendFrame(nil, 0, []byte(`{"name":"a"}`))
+	msg, err := Decode(f, framed)
+	if err != nil || string(msg) != `{"name":"a"}` {
+		t.Fatalf("Decode = %q, %v", msg, err)
+	}
+	if _, err := Decode(f, framed[:7]); err == nil {
+		t.Error("expected an error for a truncated frame")
+	}
+
+	out := Encode(f, 200, []byte(`{"ok":true}`))
+	if !bytes.Equal(out[:5+11], appendFrame(nil, 0, []byte(`{"ok":true}`))) {
+		t.Errorf("expected data frame first, got %q", out)
+	}
+	if !strings.HasSuffix(string(out), "grpc-status: 0\r\n") {
+		t.Errorf("expected OK trailer, got %q", out)
+	}
+
+	out = Encode(f, 404, []byte(`{"error":{"code":"NOT_FOUND","message":"no such user"}}`))
+	if out[0] != flagTrailer || !strings.Contains(string(out), "grpc-status: 5\r\ngrpc-message: no such user\r\n") {
+		t.Errorf("unexpected error response %q", out)
+	}
+
+	text, _ := Detect("application/grpc-web-text+json")
+	encoded := base64.StdEncoding.EncodeToString(framed)
+	if msg, err := Decode(text, []byte(encoded)); err != nil || string(msg) != `{"name":"a"}` {
+		t.Errorf("Decode text = %q, %v", msg, err)
+	}
+	if _, err := base64.StdEncoding.DecodeString(string(Encode(text, 200, []byte(`{}`)))); err != nil {
+		t.Errorf("expected base64 response: %v", err)
+	}
+
+	connect, _ := Detect("application/connect+json")
+	out = Encode(connect, 503, nil)
+	if out[0] != flagEndStream || !strings.Contains(string(out), `"code":"unavailable"`) {
+		t.Errorf("unexpected connect error %q", out)
+	}
+}
@@ -0,0 +1,80 @@
+package lru
+
+import (
+	"container/list"
+	"time"
+)
+
+// Cache maps keys to values that expire, holding at most a fixed number of
+// them. When full, storing a new key drops the least recently used one.
+// Cache is not safe for concurrent use; callers guard it with their own lock.
+type Cache[V any] struct {
+	max   int
+	order *list.List // most recently used first
+	items map[string]*list.Element
+}
+
+type entry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+// New creates an empty Cache holding at most max entries
+func New[V any](max int) *Cache[V] {
+	return &Cache[V]{max: max, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get returns the value of key unless it has expired by now, marking it
+// recently used. Expired entries are dropped.
+func (c *Cache[V]) Get(key string, now time.Time) (V, bool) {
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[V])
+	if !now.Before(e.expires) {
+		c.remove(el)
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Put stores value for key until expires, replacing any value it had
+func (c *Cache[V]) Put(key string, value V, expires time.Time) {
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	for c.max > 0 && len(c.items) >= c.max {
+		c.remove(c.order.Back())
+	}
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expires: expires})
+}
+
+// Delete drops key
+func (c *Cache[V]) Delete(key string) {
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet dropped
+func (c *Cache[V]) Len() int {
+	return len(c.items)
+}
+
+// Reset drops every entry
+func (c *Cache[V]) Reset() {
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *Cache[V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+}
@@ -0,0 +1,44 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New[int](2)
+
+	c.Put("a", 1, now.Add(time.Minute))
+	c.Put("b", 2, now.Add(time.Minute))
+	if v, ok := c.Get("a", now); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %d %v", v, ok)
+	}
+
+	// b is now the least recently used
+	c.Put("c", 3, now.Add(time.Minute))
+	if _, ok := c.Get("b", now); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+
+	if _, ok := c.Get("a", now.Add(time.Minute)); ok {
+		t.Errorf("expected a to have expired")
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected the expired entry to be dropped, got %d entries", c.Len())
+	}
+
+	c.Put("c", 4, now.Add(time.Hour))
+	if v, _ := c.Get("c", now.Add(time.Minute)); v != 4 {
+		t.Errorf("expected c to be replaced, got %d", v)
+	}
+	c.Delete("c")
+	c.Put("d", 5, now.Add(time.Minute))
+	c.Reset()
+	if c.Len() != 0 {
+		t.Errorf("expected no entries after reset, got %d", c.Len())
+	}
+}
@@ -0,0 +1,48 @@
+package openapi
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Cache holds parsed spec files and re-parses a file once it changes on disk
+type Cache struct {
+	mu    sync.Mutex
+	specs map[string]cachedSpec
+}
+
+type cachedSpec struct {
+	modTime time.Time
+	doc     *Document
+}
+
+// NewCache creates an empty Cache
+func NewCache() *Cache {
+	return &Cache{specs: make(map[string]cachedSpec)}
+}
+
+// Load returns the parsed document at path
+func (c *Cache) Load(path string) (*Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if spec, ok := c.specs[path]; ok && spec.modTime.Equal(info.ModTime()) {
+		return spec.doc, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	c.specs[path] = cachedSpec{modTime: info.ModTime(), doc: doc}
+	return doc, nil
+}
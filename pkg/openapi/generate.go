@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const generateAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// MatchOperation returns the operation whose path template matches a
+// concrete request path, preferring templates with fewer parameters
+func (d *Document) MatchOperation(method, requestPath string) (Operation, bool) {
+	want := strings.Split(strings.TrimSuffix(requestPath, "/"), "/")
+
+	var best Operation
+	bestParams := -1
+	for _, op := range d.Operations() {
+		if !strings.EqualFold(op.Method, method) {
+			continue
+		}
+		segments := strings.Split(strings.TrimSuffix(op.Path, "/"), "/")
+		if len(segments) != len(want) {
+			continue
+		}
+		params := 0
+		for i, seg := range segments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				params++
+			} else if seg != want[i] {
+				params = -1
+				break
+			}
+		}
+		if params >= 0 && (bestParams < 0 || params < bestParams) {
+			best, bestParams = op, params
+		}
+	}
+	return best, bestParams >= 0
+}
+
+// Generate builds a random value conforming to schema: enums, formats,
+// numeric bounds and array sizes are respected, every property is filled in
+// and nullable values are never null
+func (d *Document) Generate(schema map[string]interface{}, rnd *rand.Rand) interface{} {
+	return d.generate(schema, rnd, 0)
+}
+
+func (d *Document) generate(schema map[string]interface{}, rnd *rand.Rand, depth int) interface{} {
+	schema = d.resolve(schema, 0)
+	if schema == nil || depth > maxRefDepth {
+		return nil
+	}
+	if value, ok := schema["const"]; ok {
+		return value
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[rnd.Intn(len(enum))]
+	}
+	if variants, ok := schema["allOf"].([]interface{}); ok && len(variants) > 0 {
+		merged := map[string]interface{}{}
+		for _, variant := range variants {
+			if obj, ok := d.generate(asMap(variant), rnd, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants, ok := schema[key].([]interface{}); ok && len(variants) > 0 {
+			return d.generate(asMap(variants[rnd.Intn(len(variants))]), rnd, depth+1)
+		}
+	}
+
+	switch SchemaType(schema) {
+	case "object":
+		obj := map[string]interface{}{}
+		for _, name := range sortedKeys(asMap(schema["properties"])) {
+			obj[name] = d.generate(asMap(asMap(schema["properties"])[name]), rnd, depth+1)
+		}
+		return obj
+	case "array":
+		lo, hi := 1, 3
+		if n, ok := number(schema["minItems"]); ok {
+			lo = int(n)
+			hi = lo + 2
+		}
+		if n, ok := number(schema["maxItems"]); ok && int(n) < hi {
+			hi = int(n)
+		}
+		if hi < lo {
+			hi = lo
+		}
+		items := asMap(schema["items"])
+		arr := make([]interface{}, lo+rnd.Intn(hi-lo+1))
+		for i := range arr {
+			arr[i] = d.generate(items, rnd, depth+1)
+		}
+		return arr
+	case "integer":
+		lo, hi := bounds(schema, 1)
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+		if hi <= lo {
+			return int64(lo)
+		}
+		return int64(lo) + rnd.Int63n(int64(hi-lo)+1)
+	case "number":
+		lo, hi := bounds(schema, 0.01)
+		return math.Round((lo+rnd.Float64()*(hi-lo))*100) / 100
+	case "boolean":
+		return rnd.Intn(2) == 1
+	case "string":
+		return generateString(schema, rnd)
+	}
+	return nil
+}
+
+// bounds returns the inclusive range of a numeric schema; step is how far
+// an exclusive bound is moved inwards
+func bounds(schema map[string]interface{}, step float64) (float64, float64) {
+	lo, hasLo := number(schema["minimum"])
+	hi, hasHi := number(schema["maximum"])
+	if n, ok := number(schema["exclusiveMinimum"]); ok {
+		lo, hasLo = n+step, true
+	} else if hasLo && schema["exclusiveMinimum"] == true {
+		lo += step
+	}
+	if n, ok := number(schema["exclusiveMaximum"]); ok {
+		hi, hasHi = n-step, true
+	} else if hasHi && schema["exclusiveMaximum"] == true {
+		hi -= step
+	}
+	switch {
+	case !hasLo && !hasHi:
+		lo, hi = 1, 1000
+	case !hasLo:
+		lo = hi - 1000
+	case !hasHi:
+		hi = lo + 1000
+	}
+	return lo, hi
+}
+
+func generateString(schema map[string]interface{}, rnd *rand.Rand) string {
+	switch stringValue(schema["format"]) {
+	case "date-time":
+		return randomTime(rnd).Format(time.RFC3339)
+	case "date":
+		return randomTime(rnd).Format("2006-01-02")
+	case "uuid":
+		id, _ := uuid.NewRandomFromReader(rnd)
+		return id.String()
+	case "email":
+		return fmt.Sprintf("%s@example.com", randomWord(rnd, 6))
+	case "uri", "url":
+		return "https://example.com/" + randomWord(rnd, 8)
+	case "hostname":
+		return randomWord(rnd, 8) + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("10.%d.%d.%d", rnd.Intn(256), rnd.Intn(256), 1+rnd.Intn(254))
+	}
+
+	lo, hi := 6, 12
+	if n, ok := number(schema["minLength"]); ok {
+		lo = int(n)
+		if hi < lo {
+			hi = lo
+		}
+	}
+	if n, ok := number(schema["maxLength"]); ok && int(n) < hi {
+		hi = int(n)
+		if lo > hi {
+			lo = hi
+		}
+	}
+	return randomWord(rnd, lo+rnd.Intn(hi-lo+1))
+}
+
+// randomTime returns a time within the past year, to the second
+func randomTime(rnd *rand.Rand) time.Time {
+	return time.Now().UTC().Add(-time.Duration(rnd.Int63n(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+func randomWord(rnd *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = generateAlphabet[rnd.Intn(len(generateAlphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateConformsToSchema(t *testing.T) {
+	doc, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"user", "score"},
+		"properties": map[string]interface{}{
+			"user":  map[string]interface{}{"$ref": "#/components/schemas/User"},
+			"score": map[string]interface{}{"type": "number", "minimum": 0, "exclusiveMaximum": 1},
+			"code":  map[string]interface{}{"type": "string", "minLength": 3, "maxLength": 3},
+			"at":    map[string]interface{}{"type": "string", "format": "date-time"},
+			"id":    map[string]interface{}{"type": "string", "format": "uuid"},
+			"items": map[string]interface{}{"type": "array", "minItems": 2, "maxItems": 2, "items": map[string]interface{}{"type": "integer", "minimum": 5, "maximum": 6}},
+		},
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		value := doc.Generate(schema, rnd)
+		if errs := doc.Validate(schema, encodeDecode(t, value)); len(errs) > 0 {
+			t.Fatalf("generated %v violates the schema: %v", value, errs)
+		}
+	}
+}
+
+func TestMatchOperation(t *testing.T) {
+	doc, _ := Parse([]byte(testSpec))
+
+	if op, ok := doc.MatchOperation("GET", "/users/42"); !ok || op.OperationID != "getUser" {
+		t.Errorf("expected getUser for GET /users/42, got %+v", op)
+	}
+	if op, ok := doc.MatchOperation("POST", "/users/"); !ok || op.OperationID != "createUser" {
+		t.Errorf("expected createUser for POST /users/, got %+v", op)
+	}
+	if _, ok := doc.MatchOperation("GET", "/users/42/posts"); ok {
+		t.Error("expected no operation for an undocumented path")
+	}
+}
@@ -0,0 +1,375 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRefDepth bounds $ref resolution so recursive schemas terminate
+const maxRefDepth = 8
+
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is a parsed OpenAPI 3 document
+type Document struct {
+	raw map[string]interface{}
+}
+
+// Operation is a single path + method of the document
+type Operation struct {
+	Path        string // OpenAPI path, e.g. /users/{id}
+	Method      string // upper case
+	OperationID string
+	Summary     string
+	Tags        []string
+	Responses   []Response // sorted by status, "default" last
+}
+
+// Response is one documented response of an operation
+type Response struct {
+	Status     int // 0 for the "default" response
+	Example    interface{}
+	HasExample bool
+	Examples   map[string]interface{} // values of the named examples
+	Schema     map[string]interface{}
+}
+
+// Parse decodes an OpenAPI document from YAML or JSON
+func Parse(data []byte) (*Document, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("OpenAPI document is empty")
+	}
+	if _, ok := raw["openapi"]; !ok {
+		return nil, fmt.Errorf("not an OpenAPI 3 document: missing 'openapi' field")
+	}
+	return &Document{raw: raw}, nil
+}
+
+// Operations returns all operations sorted by path and method
+func (d *Document) Operations() []Operation {
+	paths, _ := d.raw["paths"].(map[string]interface{})
+
+	pathKeys := make([]string, 0, len(paths))
+	for p := range paths {
+		pathKeys = append(pathKeys, p)
+	}
+	sort.Strings(pathKeys)
+
+	var ops []Operation
+	for _, p := range pathKeys {
+		item, _ := paths[p].(map[string]interface{})
+		for _, method := range operationMethods {
+			rawOp, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := Operation{
+				Path:        p,
+				Method:      strings.ToUpper(method),
+				OperationID: stringValue(rawOp["operationId"]),
+				Summary:     stringValue(rawOp["summary"]),
+			}
+			if op.Summary == "" {
+				op.Summary = stringValue(rawOp["description"])
+			}
+			if tags, ok := rawOp["tags"].([]interface{}); ok {
+				for _, tag := range tags {
+					if s := stringValue(tag); s != "" {
+						op.Tags = append(op.Tags, s)
+					}
+				}
+			}
+			op.Responses = d.responses(rawOp)
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// FindOperation returns the operation with the given operationId
+func (d *Document) FindOperation(operationID string) (Operation, bool) {
+	for _, op := range d.Operations() {
+		if op.OperationID == operationID {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// Response returns the documented response for status, falling back to the
+// "default" response
+func (op Operation) Response(status int) (Response, bool) {
+	for _, resp := range op.Responses {
+		if resp.Status == status {
+			return resp, true
+		}
+	}
+	for _, resp := range op.Responses {
+		if resp.Status == 0 {
+			return resp, true
+		}
+	}
+	return Response{}, false
+}
+
+// FindOperationByPath returns the operation for method and path. Path
+// parameters may be written {id} or :id on either side.
+func (d *Document) FindOperationByPath(method, path string) (Operation, bool) {
+	want := normalizePath(path)
+	for _, op := range d.Operations() {
+		if strings.EqualFold(op.Method, method) && normalizePath(op.Path) == want {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+func normalizePath(path string) string {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || (strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// PrimaryResponse picks the lowest 2xx response, falling back to the first
+// documented one
+func (op Operation) PrimaryResponse() (Response, bool) {
+	for _, resp := range op.Responses {
+		if resp.Status >= 200 && resp.Status < 300 {
+			return resp, true
+		}
+	}
+	if len(op.Responses) > 0 {
+		return op.Responses[0], true
+	}
+	return Response{}, false
+}
+
+// ResponseWithExample returns the response that has a named example
+func (op Operation) ResponseWithExample(name string) (Response, bool) {
+	for _, resp := range op.Responses {
+		if _, ok := resp.Examples[name]; ok {
+			return resp, true
+		}
+	}
+	return Response{}, false
+}
+
+// responses extracts the documented JSON responses of an operation
+func (d *Document) responses(rawOp map[string]interface{}) []Response {
+	rawResponses, _ := rawOp["responses"].(map[string]interface{})
+
+	var result []Response
+	for code, rawResp := range rawResponses {
+		status := 0
+		if code != "default" {
+			n, err := strconv.Atoi(code)
+			if err != nil {
+				continue
+			}
+			status = n
+		}
+
+		resp := Response{Status: status}
+		respMap := d.resolve(asMap(rawResp), 0)
+		content := asMap(respMap["content"])
+		media := asMap(content["application/json"])
+		if media == nil {
+			for key, value := range content {
+				if strings.Contains(key, "json") {
+					media = asMap(value)
+					break
+				}
+			}
+		}
+		if media != nil {
+			resp.Schema = d.resolve(asMap(media["schema"]), 0)
+			if example, ok := media["example"]; ok {
+				resp.Example, resp.HasExample = example, true
+			}
+			if examples := asMap(media["examples"]); len(examples) > 0 {
+				resp.Examples = make(map[string]interface{}, len(examples))
+				for name, example := range examples {
+					if value, ok := d.resolve(asMap(example), 0)["value"]; ok {
+						resp.Examples[name] = value
+					}
+				}
+				if !resp.HasExample {
+					for _, name := range sortedKeys(examples) {
+						if value, ok := resp.Examples[name]; ok {
+							resp.Example, resp.HasExample = value, true
+							break
+						}
+					}
+				}
+			}
+		}
+		result = append(result, resp)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Status == 0 || result[j].Status == 0 {
+			return result[j].Status == 0 && result[i].Status != 0
+		}
+		return result[i].Status < result[j].Status
+	})
+	return result
+}
+
+// Example returns the documented example, or one synthesized from the schema
+func (d *Document) Example(resp Response) interface{} {
+	if resp.HasExample {
+		return resp.Example
+	}
+	return d.ExampleFromSchema(resp.Schema)
+}
+
+// ExampleFromSchema builds a deterministic value conforming to schema
+func (d *Document) ExampleFromSchema(schema map[string]interface{}) interface{} {
+	return d.exampleFromSchema(schema, 0)
+}
+
+func (d *Document) exampleFromSchema(schema map[string]interface{}, depth int) interface{} {
+	schema = d.resolve(schema, 0)
+	if schema == nil || depth > maxRefDepth {
+		return nil
+	}
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	if def, ok := schema["default"]; ok {
+		return def
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		if variants, ok := schema[key].([]interface{}); ok && len(variants) > 0 {
+			if key != "allOf" {
+				return d.exampleFromSchema(asMap(variants[0]), depth+1)
+			}
+			merged := map[string]interface{}{}
+			for _, variant := range variants {
+				if obj, ok := d.exampleFromSchema(asMap(variant), depth+1).(map[string]interface{}); ok {
+					for k, v := range obj {
+						merged[k] = v
+					}
+				}
+			}
+			return merged
+		}
+	}
+
+	switch SchemaType(schema) {
+	case "object":
+		obj := map[string]interface{}{}
+		for name, prop := range asMap(schema["properties"]) {
+			obj[name] = d.exampleFromSchema(asMap(prop), depth+1)
+		}
+		return obj
+	case "array":
+		return []interface{}{d.exampleFromSchema(asMap(schema["items"]), depth+1)}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	case "string":
+		return exampleString(stringValue(schema["format"]))
+	default:
+		return nil
+	}
+}
+
+// Resolve follows local "#/..." $ref pointers
+func (d *Document) Resolve(schema map[string]interface{}) map[string]interface{} {
+	return d.resolve(schema, 0)
+}
+
+func (d *Document) resolve(node map[string]interface{}, depth int) map[string]interface{} {
+	ref, ok := node["$ref"].(string)
+	if !ok || depth > maxRefDepth {
+		return node
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+
+	var current interface{} = d.raw
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		current = asMap(current)[part]
+	}
+	return d.resolve(asMap(current), depth+1)
+}
+
+// SchemaType returns the schema type, inferring object/array when omitted
+func SchemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		// OpenAPI 3.1 allows type lists; use the first non-null type
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// exampleString returns a placeholder string for a string format
+func exampleString(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testSpec = `
+openapi: 3.0.3
+info: {title: Users, version: "1"}
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      summary: Get a user
+      responses:
+        "404":
+          description: not found
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+    delete:
+      responses:
+        "204": {description: deleted}
+  /users:
+    post:
+      operationId: createUser
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              example: {id: 7, name: Ann}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id: {type: integer}
+        email: {type: string, format: email}
+        role: {type: string, enum: [admin, guest]}
+        tags:
+          type: array
+          items: {type: string}
+`
+
+func TestDocumentOperations(t *testing.T) {
+	doc, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	ops := doc.Operations()
+	if len(ops) != 3 {
+		t.Fatalf("expected 3 operations, got %d", len(ops))
+	}
+	if ops[0].Path != "/users" || ops[0].Method != "POST" {
+		t.Errorf("expected operations sorted by path, got %s %s first", ops[0].Method, ops[0].Path)
+	}
+
+	getUser, ok := doc.FindOperation("getUser")
+	if !ok {
+		t.Fatal("expected to find getUser")
+	}
+	if len(getUser.Responses) != 2 || getUser.Responses[0].Status != 200 {
+		t.Fatalf("expected responses sorted by status, got %+v", getUser.Responses)
+	}
+
+	example := doc.Example(getUser.Responses[0])
+	want := map[string]interface{}{
+		"id":    0,
+		"email": "user@example.com",
+		"role":  "admin",
+		"tags":  []interface{}{"string"},
+	}
+	if !reflect.DeepEqual(example, want) {
+		t.Errorf("Example() = %#v, want %#v", example, want)
+	}
+
+	createUser, _ := doc.FindOperation("createUser")
+	if !createUser.Responses[0].HasExample {
+		t.Errorf("expected documented example to be used")
+	}
+}
+
+func TestParseRejectsNonOpenAPI(t *testing.T) {
+	if _, err := Parse([]byte("foo: bar")); err == nil {
+		t.Fatal("expected error for document without openapi field")
+	}
+}
+
+func TestNamedExamples(t *testing.T) {
+	doc, err := Parse([]byte(`
+openapi: 3.1.0
+info: {title: Orders, version: "1"}
+paths:
+  /orders/{id}:
+    get:
+      operationId: getOrder
+      responses:
+        "404":
+          description: missing
+          content:
+            application/json:
+              examples:
+                gone: {value: {error: gone}}
+        "200":
+          description: ok
+          content:
+            application/json:
+              examples:
+                paid: {$ref: "#/components/examples/Paid"}
+                open: {value: {status: open}}
+components:
+  examples:
+    Paid: {value: {status: paid}}
+`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	op, _ := doc.FindOperation("getOrder")
+
+	primary, ok := op.PrimaryResponse()
+	if !ok || primary.Status != 200 {
+		t.Fatalf("expected the 200 response as primary, got %+v", primary)
+	}
+	if !reflect.DeepEqual(doc.Example(primary), map[string]interface{}{"status": "open"}) {
+		t.Errorf("expected the first example by name, got %#v", doc.Example(primary))
+	}
+
+	resp, ok := op.ResponseWithExample("paid")
+	if !ok || resp.Status != 200 || !reflect.DeepEqual(resp.Examples["paid"], map[string]interface{}{"status": "paid"}) {
+		t.Errorf("expected resolved example paid on 200, got %+v", resp)
+	}
+	if resp, ok := op.ResponseWithExample("gone"); !ok || resp.Status != 404 {
+		t.Errorf("expected example gone on 404, got %+v", resp)
+	}
+	if _, ok := op.ResponseWithExample("nope"); ok {
+		t.Error("expected unknown example to be missing")
+	}
+}
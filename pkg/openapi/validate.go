@@ -0,0 +1,250 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Validate checks a decoded JSON value against schema and returns one
+// message per violation, each prefixed with the JSON path of the value
+func (d *Document) Validate(schema map[string]interface{}, value interface{}) []string {
+	return d.validate(schema, value, "$", 0)
+}
+
+func (d *Document) validate(schema map[string]interface{}, value interface{}, path string, depth int) []string {
+	schema = d.resolve(schema, 0)
+	if len(schema) == 0 || depth > maxRefDepth*4 {
+		return nil
+	}
+
+	var errs []string
+	if variants, ok := schema["allOf"].([]interface{}); ok {
+		for _, variant := range variants {
+			errs = append(errs, d.validate(asMap(variant), value, path, depth+1)...)
+		}
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		variants, ok := schema[key].([]interface{})
+		if !ok || len(variants) == 0 {
+			continue
+		}
+		matched := 0
+		for _, variant := range variants {
+			if len(d.validate(asMap(variant), value, path, depth+1)) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 || (key == "oneOf" && matched > 1) {
+			errs = append(errs, fmt.Sprintf("%s: matches %d of the %s schemas", path, matched, key))
+		}
+	}
+
+	if value == nil {
+		if t := SchemaType(schema); t != "" && schema["nullable"] != true && !allowsNull(schema) {
+			errs = append(errs, fmt.Sprintf("%s: expected %s, got null", path, t))
+		}
+		return errs
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		errs = append(errs, fmt.Sprintf("%s: value %s is not one of the allowed values", path, encode(value)))
+	}
+
+	switch t := SchemaType(schema); t {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected object, got %s", path, jsonType(value)))
+		}
+		errs = append(errs, d.validateObject(schema, obj, path, depth)...)
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected array, got %s", path, jsonType(value)))
+		}
+		if n, ok := number(schema["minItems"]); ok && float64(len(arr)) < n {
+			errs = append(errs, fmt.Sprintf("%s: expected at least %v items, got %d", path, n, len(arr)))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(arr)) > n {
+			errs = append(errs, fmt.Sprintf("%s: expected at most %v items, got %d", path, n, len(arr)))
+		}
+		if items := asMap(schema["items"]); items != nil {
+			for i, item := range arr {
+				errs = append(errs, d.validate(items, item, fmt.Sprintf("%s[%d]", path, i), depth+1)...)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected string, got %s", path, jsonType(value)))
+		}
+		errs = append(errs, validateString(schema, s, path)...)
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected %s, got %s", path, t, jsonType(value)))
+		}
+		if t == "integer" && n != math.Trunc(n) {
+			errs = append(errs, fmt.Sprintf("%s: expected integer, got %v", path, n))
+		}
+		errs = append(errs, validateNumber(schema, n, path)...)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Sprintf("%s: expected boolean, got %s", path, jsonType(value)))
+		}
+	}
+	return errs
+}
+
+func (d *Document) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string, depth int) []string {
+	var errs []string
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := obj[stringValue(name)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing required property '%s'", path, stringValue(name)))
+			}
+		}
+	}
+
+	properties := asMap(schema["properties"])
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if prop, ok := properties[k]; ok {
+			errs = append(errs, d.validate(asMap(prop), obj[k], path+"."+k, depth+1)...)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				errs = append(errs, fmt.Sprintf("%s: unexpected property '%s'", path, k))
+			}
+		case map[string]interface{}:
+			errs = append(errs, d.validate(extra, obj[k], path+"."+k, depth+1)...)
+		}
+	}
+	return errs
+}
+
+func validateString(schema map[string]interface{}, s, path string) []string {
+	var errs []string
+	length := float64(len([]rune(s)))
+	if n, ok := number(schema["minLength"]); ok && length < n {
+		errs = append(errs, fmt.Sprintf("%s: shorter than %v characters", path, n))
+	}
+	if n, ok := number(schema["maxLength"]); ok && length > n {
+		errs = append(errs, fmt.Sprintf("%s: longer than %v characters", path, n))
+	}
+	if pattern := stringValue(schema["pattern"]); pattern != "" {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(s) {
+			errs = append(errs, fmt.Sprintf("%s: does not match pattern %s", path, pattern))
+		}
+	}
+
+	valid := true
+	switch format := stringValue(schema["format"]); format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(s)
+	case "email":
+		at := strings.Index(s, "@")
+		valid = at > 0 && at < len(s)-1
+	}
+	if !valid {
+		errs = append(errs, fmt.Sprintf("%s: %q is not a valid %s", path, s, stringValue(schema["format"])))
+	}
+	return errs
+}
+
+func validateNumber(schema map[string]interface{}, n float64, path string) []string {
+	var errs []string
+	// OpenAPI 3.0 marks exclusive bounds with booleans, 3.1 with numbers
+	if min, ok := number(schema["minimum"]); ok {
+		if n < min || (schema["exclusiveMinimum"] == true && n == min) {
+			errs = append(errs, fmt.Sprintf("%s: %v is below the minimum %v", path, n, min))
+		}
+	}
+	if min, ok := number(schema["exclusiveMinimum"]); ok && n <= min {
+		errs = append(errs, fmt.Sprintf("%s: %v is not above %v", path, n, min))
+	}
+	if max, ok := number(schema["maximum"]); ok {
+		if n > max || (schema["exclusiveMaximum"] == true && n == max) {
+			errs = append(errs, fmt.Sprintf("%s: %v is above the maximum %v", path, n, max))
+		}
+	}
+	if max, ok := number(schema["exclusiveMaximum"]); ok && n >= max {
+		errs = append(errs, fmt.Sprintf("%s: %v is not below %v", path, n, max))
+	}
+	return errs
+}
+
+// allowsNull reports whether an OpenAPI 3.1 type list includes "null"
+func allowsNull(schema map[string]interface{}) bool {
+	types, _ := schema["type"].([]interface{})
+	for _, t := range types {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// inEnum compares by JSON encoding, since YAML decodes integers as int and
+// JSON as float64
+func inEnum(enum []interface{}, value interface{}) bool {
+	want := encode(value)
+	for _, e := range enum {
+		if encode(e) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func encode(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
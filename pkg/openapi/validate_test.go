@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	doc, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	op, ok := doc.FindOperationByPath("GET", "/users/:id")
+	if !ok {
+		t.Fatal("expected to find GET /users/:id")
+	}
+	resp, ok := op.Response(200)
+	if !ok {
+		t.Fatal("expected a 200 response")
+	}
+
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{`{"id": 1, "email": "a@b.c", "role": "guest", "tags": ["x"]}`, nil},
+		{`{"id": 1.5}`, []string{"$.id: expected integer"}},
+		{`{"role": "owner"}`, []string{"$.role: value \"owner\" is not one of"}},
+		{`{"tags": [1]}`, []string{"$.tags[0]: expected string, got number"}},
+		{`{"email": "nobody"}`, []string{"$.email: \"nobody\" is not a valid email"}},
+		{`[]`, []string{"$: expected object, got array"}},
+	}
+	for _, tt := range tests {
+		var value interface{}
+		if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+			t.Fatalf("invalid test body %s: %v", tt.body, err)
+		}
+		errs := doc.Validate(resp.Schema, value)
+		if len(errs) != len(tt.want) {
+			t.Errorf("Validate(%s) = %v, want %d errors", tt.body, errs, len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			if !strings.HasPrefix(errs[i], want) {
+				t.Errorf("Validate(%s)[%d] = %q, want prefix %q", tt.body, i, errs[i], want)
+			}
+		}
+	}
+
+	if _, ok := op.Response(500); ok {
+		t.Error("expected no response for an undocumented status without default")
+	}
+}
+
+func TestValidateRequiredAndAdditional(t *testing.T) {
+	doc, _ := Parse([]byte(testSpec))
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"required":             []interface{}{"id"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"id":   map[string]interface{}{"type": "integer", "minimum": 1},
+			"note": map[string]interface{}{"type": "string", "nullable": true},
+		},
+	}
+	errs := doc.Validate(schema, map[string]interface{}{"note": nil, "extra": true})
+	want := []string{"$: missing required property 'id'", "$: unexpected property 'extra'"}
+	if strings.Join(errs, "|") != strings.Join(want, "|") {
+		t.Errorf("Validate() = %v, want %v", errs, want)
+	}
+	if errs := doc.Validate(schema, map[string]interface{}{"id": 0.0}); len(errs) != 1 {
+		t.Errorf("expected minimum violation, got %v", errs)
+	}
+}
+
+// encodeDecode round-trips v through JSON, as a response body would be
+func encodeDecode(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimiter caps in-flight requests per key
+type ConcurrencyLimiter struct {
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// NewConcurrencyLimiter creates an empty ConcurrencyLimiter
+func NewConcurrencyLimiter() *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{slots: make(map[string]chan struct{})}
+}
+
+// Acquire takes one of limit slots for key, waiting up to wait for a slot to
+// free up. The returned release func must be called once the request is done.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, key string, limit int, wait time.Duration) (func(), bool) {
+	if limit <= 0 {
+		return func() {}, true
+	}
+
+	slots := l.slotsFor(key, limit)
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	default:
+	}
+	if wait <= 0 {
+		return nil, false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// InFlight returns the number of slots in use for key
+func (l *ConcurrencyLimiter) InFlight(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.slots[key])
+}
+
+// slotsFor returns the semaphore for key. A reload that changes the limit gets
+// a new semaphore; requests holding slots in the old one release them there.
+func (l *ConcurrencyLimiter) slotsFor(key string, limit int) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.slots[key]
+	if !ok || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		l.slots[key] = slots
+	}
+	return slots
+}
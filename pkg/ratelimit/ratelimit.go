@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"mock-api-server/pkg/lru"
+)
+
+// maxIdle is how long an unused bucket or window is kept; maxKeys caps how
+// many are kept, dropping the least recently used first
+const (
+	maxIdle = 10 * time.Minute
+	maxKeys = 10000
+)
+
+// Limiter holds one token bucket per key
+type Limiter struct {
+	mu      sync.Mutex
+	buckets *lru.Cache[*bucket]
+	now     func() time.Time
+}
+
+type bucket struct {
+	rate     float64 // tokens added per second
+	burst    int
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewLimiter creates an empty Limiter
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: lru.New[*bucket](maxKeys),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket for key, which refills at rate tokens per
+// second up to burst. When the bucket is empty it reports how long to wait.
+func (l *Limiter) Allow(key string, rate float64, burst int) (bool, time.Duration) {
+	if rate <= 0 {
+		return true, 0
+	}
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets.Get(key, now)
+	// A reload may change the limits; start such buckets over
+	if !ok || b.rate != rate || b.burst != burst {
+		b = &bucket{rate: rate, burst: burst, tokens: float64(burst), lastSeen: now}
+	}
+	l.buckets.Put(key, b, now.Add(maxIdle))
+
+	b.tokens = math.Min(float64(b.burst), b.tokens+now.Sub(b.lastSeen).Seconds()*b.rate)
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// Reset drops all buckets
+func (l *Limiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buckets.Reset()
+}
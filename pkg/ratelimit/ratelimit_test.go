@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("ip", 1, 2); !ok {
+			t.Fatalf("request %d should be allowed within burst", i)
+		}
+	}
+
+	ok, wait := l.Allow("ip", 1, 2)
+	if ok {
+		t.Fatalf("expected request beyond burst to be limited")
+	}
+	if wait != time.Second {
+		t.Errorf("expected wait of 1s, got %v", wait)
+	}
+
+	if ok, _ := l.Allow("other", 1, 2); !ok {
+		t.Errorf("expected separate key to have its own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("ip", 1, 2); !ok {
+		t.Errorf("expected a token to be refilled after 1s")
+	}
+}
+
+func TestLimiterDisabledAndChangedLimits(t *testing.T) {
+	l := NewLimiter()
+	if ok, _ := l.Allow("k", 0, 0); !ok {
+		t.Fatalf("rate 0 should disable limiting")
+	}
+
+	l.Allow("k", 1, 1)
+	if ok, _ := l.Allow("k", 1, 1); ok {
+		t.Fatalf("expected bucket to be empty")
+	}
+	if ok, _ := l.Allow("k", 5, 5); !ok {
+		t.Errorf("expected changed limits to start a new bucket")
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	ctx := context.Background()
+
+	release, ok := l.Acquire(ctx, "k", 1, 0)
+	if !ok {
+		t.Fatalf("expected first request to get a slot")
+	}
+	if _, ok := l.Acquire(ctx, "k", 1, 0); ok {
+		t.Fatalf("expected second request to be rejected without a queue")
+	}
+	if _, ok := l.Acquire(ctx, "k", 1, 10*time.Millisecond); ok {
+		t.Fatalf("expected queued request to time out while the slot is held")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	release2, ok := l.Acquire(ctx, "k", 1, time.Second)
+	if !ok {
+		t.Fatalf("expected queued request to get the released slot")
+	}
+	release2()
+
+	if n := l.InFlight("k"); n != 0 {
+		t.Errorf("expected no requests in flight, got %d", n)
+	}
+}
+
+func TestWindowLimiterHit(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewWindowLimiter()
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if r := l.Hit("ip", 2, time.Minute); !r.Allowed || r.Remaining != 1-i {
+			t.Fatalf("call %d: got %+v", i, r)
+		}
+	}
+
+	now = now.Add(20 * time.Second)
+	r := l.Hit("ip", 2, time.Minute)
+	if r.Allowed || r.Remaining != 0 || r.Reset != 40*time.Second {
+		t.Fatalf("expected the third call to be limited for 40s, got %+v", r)
+	}
+
+	now = now.Add(40 * time.Second)
+	if r := l.Hit("ip", 2, time.Minute); !r.Allowed || r.Remaining != 1 || r.Reset != time.Minute {
+		t.Errorf("expected a fresh window, got %+v", r)
+	}
+}
+
+func TestLimitersCapKeys(t *testing.T) {
+	l := NewLimiter()
+	w := NewWindowLimiter()
+	for i := 0; i < maxKeys+10; i++ {
+		key := strconv.Itoa(i)
+		l.Allow(key, 1, 1)
+		w.Hit(key, 1, time.Minute)
+	}
+	if n := l.buckets.Len(); n != maxKeys {
+		t.Errorf("kept %d buckets, want %d", n, maxKeys)
+	}
+	if n := w.windows.Len(); n != maxKeys {
+		t.Errorf("kept %d windows, want %d", n, maxKeys)
+	}
+}
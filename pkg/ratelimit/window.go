@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"mock-api-server/pkg/lru"
+)
+
+// WindowLimiter counts calls per key in fixed windows, the way many APIs
+// report quotas with X-RateLimit-* headers
+type WindowLimiter struct {
+	mu      sync.Mutex
+	windows *lru.Cache[*window]
+	now     func() time.Time
+}
+
+type window struct {
+	limit int
+	size  time.Duration
+	start time.Time
+	count int
+}
+
+// WindowResult describes the state of a key's window after a call
+type WindowResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration // until the window starts over
+}
+
+// NewWindowLimiter creates an empty WindowLimiter
+func NewWindowLimiter() *WindowLimiter {
+	return &WindowLimiter{
+		windows: lru.New[*window](maxKeys),
+		now:     time.Now,
+	}
+}
+
+// Hit counts a call for key against limit calls per size. Calls over the
+// limit are not allowed until the window resets.
+func (l *WindowLimiter) Hit(key string, limit int, size time.Duration) WindowResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	w, ok := l.windows.Get(key, now)
+	// A reload may change the limits; start such windows over
+	if !ok || w.limit != limit || w.size != size || now.Sub(w.start) >= size {
+		w = &window{limit: limit, size: size, start: now}
+		l.windows.Put(key, w, now.Add(size))
+	}
+
+	result := WindowResult{Limit: limit, Reset: w.start.Add(size).Sub(now)}
+	if w.count < limit {
+		w.count++
+		result.Allowed = true
+	}
+	result.Remaining = limit - w.count
+	return result
+}
+
+// Reset drops all windows
+func (l *WindowLimiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.windows.Reset()
+}
@@ -0,0 +1,146 @@
+// Package responder delegates building a response to code outside the
+// server. The request is sent as JSON to a program's stdin or POSTed to an
+// HTTP hook; the program's stdout or the hook's response body is JSON of the
+// form {"status": 200, "headers": {...}, "body": ...}. A string body is sent
+// as is, any other JSON value is sent encoded.
+package responder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+)
+
+const (
+	// maxOutput caps how much a responder may return
+	maxOutput = 10 << 20
+	// maxStderr caps how much of a program's stderr is kept for error messages
+	maxStderr = 4 << 10
+)
+
+// Request is the JSON document a responder receives
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   map[string]string `json:"query"`
+	Headers map[string]string `json:"headers"`
+	Params  map[string]string `json:"params"` // path parameters
+	Values  map[string]string `json:"values"` // selector, counter and scenario values
+	Body    string            `json:"body"`
+}
+
+// Response is the JSON document a responder returns
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// BodyBytes returns the body to send
+func (r *Response) BodyBytes() []byte {
+	var s string
+	if json.Unmarshal(r.Body, &s) == nil {
+		return []byte(s)
+	}
+	if len(r.Body) == 0 || string(r.Body) == "null" {
+		return nil
+	}
+	return r.Body
+}
+
+// Exec runs command with the request on stdin and parses its stdout
+func Exec(ctx context.Context, command []string, req Request) (*Response, error) {
+	if len(command) == 0 {
+		return nil, errors.New("responder command is empty")
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Output past the cap fails the write, which stops the copy and leaves
+	// the program with a closed pipe instead of growing the buffer
+	stdout := &cappedBuffer{limit: maxOutput}
+	stderr := &cappedBuffer{limit: maxStderr, discard: true}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if stdout.exceeded {
+		return nil, fmt.Errorf("responder %s output exceeds %d bytes", command[0], maxOutput)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("responder %s timed out", command[0])
+		}
+		return nil, fmt.Errorf("responder %s failed: %v: %s", command[0], err, bytes.TrimSpace(stderr.buf.Bytes()))
+	}
+	return decode(stdout.buf.Bytes())
+}
+
+var errOutputTooLarge = errors.New("responder output too large")
+
+// cappedBuffer keeps up to limit bytes. Further writes fail, or are
+// silently dropped when discard is set.
+type cappedBuffer struct {
+	buf      bytes.Buffer // not embedded, so io.Copy cannot bypass Write through ReadFrom
+	limit    int
+	discard  bool
+	exceeded bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.exceeded = true
+		b.buf.Write(p[:max(room, 0)])
+		if b.discard {
+			return len(p), nil
+		}
+		return max(room, 0), errOutputTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+// Call POSTs the request to url and parses the response body
+func Call(ctx context.Context, client *http.Client, url string, req Request) (*Response, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("responder hook %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	output, err := io.ReadAll(io.LimitReader(resp.Body, maxOutput+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("responder hook %s returned status %d", url, resp.StatusCode)
+	}
+	return decode(output)
+}
+
+func decode(output []byte) (*Response, error) {
+	if len(output) > maxOutput {
+		return nil, errOutputTooLarge
+	}
+	var resp Response
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return nil, fmt.Errorf("invalid responder output: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,56 @@
+package responder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExec(t *testing.T) {
+	// Echo the request document back as the response body
+	command := []string{"sh", "-c", `read -r req; printf '{"status":201,"headers":{"X-Via":"sh"},"body":%s}' "$req"`}
+	resp, err := Exec(context.Background(), command, Request{Method: "POST", Path: "/orders", Body: "hi"})
+	if err != nil {
+		t.Fatalf("Exec returned error: %v", err)
+	}
+	if resp.Status != 201 || resp.Headers["X-Via"] != "sh" {
+		t.Errorf("unexpected status/headers: %d %v", resp.Status, resp.Headers)
+	}
+	var echoed Request
+	if err := json.Unmarshal(resp.BodyBytes(), &echoed); err != nil || echoed.Path != "/orders" || echoed.Body != "hi" {
+		t.Errorf("unexpected echoed request %s: %v", resp.BodyBytes(), err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Exec(ctx, []string{"sleep", "5"}, Request{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if _, err := Exec(context.Background(), []string{"sh", "-c", "echo not json"}, Request{}); err == nil {
+		t.Error("expected an error for invalid output")
+	}
+	if _, err := Exec(context.Background(), []string{"sh", "-c", "yes"}, Request{}); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected unbounded output to fail, got %v", err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"status":200,"body":"plain ` + req.Params["id"] + `"}`))
+	}))
+	defer hook.Close()
+
+	resp, err := Call(context.Background(), hook.Client(), hook.URL, Request{Params: map[string]string{"id": "7"}})
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if string(resp.BodyBytes()) != "plain 7" {
+		t.Errorf("expected string body to be sent as is, got %q", resp.BodyBytes())
+	}
+}
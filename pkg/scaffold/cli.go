@@ -0,0 +1,57 @@
+package scaffold
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+const generateUsage = `Usage: mock-api-server generate endpoint --path /api/users/:id [--method GET] [flags]
+
+Writes a commented starter endpoint YAML and response file.
+`
+
+// Run implements the generate subcommand on its arguments and returns the
+// exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "endpoint" {
+		fmt.Fprint(stderr, generateUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("generate endpoint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, generateUsage+"\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var opts Options
+	fs.StringVar(&opts.Path, "path", "", "Route to mock, e.g. /api/users/:id (required)")
+	fs.StringVar(&opts.Method, "method", "GET", "HTTP method, or ANY")
+	fs.IntVar(&opts.Status, "status", 200, "Status code of the default response")
+	fs.StringVar(&opts.ConfigDir, "config-dir", DefaultConfigDir, "Directory for the endpoint YAML")
+	fs.StringVar(&opts.MocksDir, "mocks-dir", DefaultMocksDir, "Directory for the response file")
+	force := fs.Bool("force", false, "Overwrite existing files")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if opts.Path == "" {
+		fmt.Fprintln(stderr, "--path is required")
+		fs.Usage()
+		return 2
+	}
+
+	files, err := Endpoint(opts)
+	if err == nil {
+		err = Write(files, *force)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "generate endpoint: %v\n", err)
+		return 1
+	}
+	for _, f := range files {
+		fmt.Fprintf(stdout, "Created %s\n", f.Path)
+	}
+	fmt.Fprintf(stdout, "Add %s to endpoints.config_paths in your config to serve it.\n", files[0].Path)
+	return 0
+}
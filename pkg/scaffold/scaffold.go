@@ -0,0 +1,166 @@
+// Package scaffold writes starter endpoint configs and response files for
+// the `generate` command.
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Defaults for Options left empty
+const (
+	DefaultConfigDir = "config/endpoints"
+	DefaultMocksDir  = "mocks"
+)
+
+// Options describe the endpoint to scaffold
+type Options struct {
+	Path      string // route, e.g. /api/users/:id
+	Method    string // HTTP method or ANY
+	Status    int    // default response status, 200 when zero
+	ConfigDir string // where the endpoint YAML goes
+	MocksDir  string // where the response file goes
+}
+
+// File is one generated file
+type File struct {
+	Path    string
+	Content []byte
+}
+
+var (
+	validMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true, "ANY": true}
+	nonWord      = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Name derives a file name such as get_api_users_id from method and path
+func Name(method, path string) string {
+	name := nonWord.ReplaceAllString(strings.ToLower(method+"_"+path), "_")
+	return strings.Trim(name, "_")
+}
+
+// Endpoint returns the endpoint YAML and response file for opts
+func Endpoint(opts Options) ([]File, error) {
+	method := strings.ToUpper(strings.TrimSpace(opts.Method))
+	if !validMethods[method] {
+		return nil, fmt.Errorf("unsupported method '%s'", opts.Method)
+	}
+	if !strings.HasPrefix(opts.Path, "/") {
+		return nil, errors.New("path must start with '/'")
+	}
+	status := opts.Status
+	if status == 0 {
+		status = 200
+	}
+	if status < 100 || status > 599 {
+		return nil, fmt.Errorf("invalid status %d", status)
+	}
+	configDir, mocksDir := opts.ConfigDir, opts.MocksDir
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	if mocksDir == "" {
+		mocksDir = DefaultMocksDir
+	}
+
+	name := Name(method, opts.Path)
+	// Response files are resolved from the working directory, like the
+	// examples in config.yaml
+	responseFile := filepath.ToSlash(filepath.Join(mocksDir, name+".json"))
+	if !filepath.IsAbs(responseFile) {
+		responseFile = "./" + responseFile
+	}
+	params := pathParams(opts.Path)
+
+	return []File{
+		{Path: filepath.Join(configDir, name+".yaml"), Content: endpointYAML(method, opts.Path, status, responseFile, params)},
+		{Path: filepath.Join(mocksDir, name+".json"), Content: responseJSON(method, opts.Path, params)},
+	}, nil
+}
+
+// Write creates files and their directories. Existing files are only
+// replaced with overwrite, and then nothing is written if any exists.
+func Write(files []File, overwrite bool) error {
+	if !overwrite {
+		for _, f := range files {
+			if _, err := os.Stat(f.Path); err == nil {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", f.Path)
+			}
+		}
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(f.Path, f.Content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathParams returns the :name parameters of a route in order
+func pathParams(path string) []string {
+	var params []string
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, ":") && len(part) > 1 {
+			params = append(params, part[1:])
+		}
+	}
+	return params
+}
+
+func endpointYAML(method, path string, status int, responseFile string, params []string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s\n", method, path)
+	b.WriteString("# Add this file to endpoints.config_paths in config.yaml to serve it.\n")
+	fmt.Fprintf(&b, "path: %s\n", strconv.Quote(path))
+	fmt.Fprintf(&b, "method: %s\n", strconv.Quote(method))
+	b.WriteString("description: \"\"\n\n")
+
+	b.WriteString("# Selectors extract request values for rule conditions and response\n")
+	b.WriteString("# templates ({{.name}}). type is path, query, header or body (a gjson path).\n")
+	example := "id"
+	if len(params) == 0 {
+		b.WriteString("selectors: []\n")
+		b.WriteString("#  - name: \"id\"\n#    type: \"query\"\n#    key: \"id\"\n\n")
+	} else {
+		example = params[0]
+		b.WriteString("selectors:\n")
+		for _, p := range params {
+			fmt.Fprintf(&b, "  - name: %s\n    type: \"path\"\n    key: %s\n", strconv.Quote(p), strconv.Quote(p))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("# Rules are tried in order; the first whose conditions all match answers.\n")
+	b.WriteString("# match_type is exact, prefix, suffix, regex or range.\n")
+	b.WriteString("rules: []\n")
+	fmt.Fprintf(&b, "#  - conditions:\n#      - selector: %s\n#        match_type: \"exact\"\n#        value: \"42\"\n", strconv.Quote(example))
+	fmt.Fprintf(&b, "#    response_file: %s\n#    status_code: 404\n\n", strconv.Quote(responseFile))
+
+	b.WriteString("# Sent when no rule matches. Template variables in the response file\n")
+	b.WriteString("# are filled in from selectors and {{.timestamp}}, {{.uuid}}, {{.request_id}}.\n")
+	b.WriteString("default:\n")
+	fmt.Fprintf(&b, "  response_file: %s\n", strconv.Quote(responseFile))
+	fmt.Fprintf(&b, "  status_code: %d\n", status)
+	b.WriteString("  template:\n    enabled: true\n")
+	return []byte(b.String())
+}
+
+func responseJSON(method, path string, params []string) []byte {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, p := range params {
+		fmt.Fprintf(&b, "    %s: \"{{.%s}}\",\n", strconv.Quote(p), p)
+	}
+	fmt.Fprintf(&b, "    \"message\": %s,\n", strconv.Quote("Mock response for "+method+" "+path))
+	b.WriteString("    \"timestamp\": \"{{.timestamp}}\"\n")
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,79 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-api-server/config"
+)
+
+func TestName(t *testing.T) {
+	if got := Name("GET", "/api/users/:id"); got != "get_api_users_id" {
+		t.Errorf("Name = %q", got)
+	}
+	if got := Name("post", "/v1/order-items/"); got != "post_v1_order_items" {
+		t.Errorf("Name = %q", got)
+	}
+}
+
+func TestEndpointLoadsAndValidates(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Endpoint(Options{
+		Path:      "/api/users/:id/orders/:order_id",
+		Method:    "get",
+		ConfigDir: filepath.Join(dir, "endpoints"),
+		MocksDir:  filepath.Join(dir, "mocks"),
+	})
+	if err != nil {
+		t.Fatalf("Endpoint returned error: %v", err)
+	}
+	if err := Write(files, false); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	mainPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(mainPath, []byte("endpoints:\n  config_paths:\n    - "+files[0].Path+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig(mainPath)
+	if err != nil {
+		t.Fatalf("generated endpoint does not load: %v", err)
+	}
+	if issues := config.Validate(cfg); len(issues) > 0 {
+		t.Errorf("generated endpoint has issues: %v", issues)
+	}
+	ep := cfg.Endpoints[0]
+	if ep.Method != "GET" || len(ep.Selectors) != 2 || ep.Default.StatusCode != 200 || !ep.Default.Template.Enabled {
+		t.Errorf("unexpected endpoint %+v", ep)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(files[1].Content, &body); err != nil {
+		t.Fatalf("response file is not JSON: %v", err)
+	}
+	if body["order_id"] != "{{.order_id}}" {
+		t.Errorf("unexpected response %v", body)
+	}
+
+	if err := Write(files, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected already exists error, got %v", err)
+	}
+	if err := Write(files, true); err != nil {
+		t.Errorf("Write with overwrite returned error: %v", err)
+	}
+}
+
+func TestEndpointRejectsBadInput(t *testing.T) {
+	for _, opts := range []Options{
+		{Path: "api/users", Method: "GET"},
+		{Path: "/api/users", Method: "FETCH"},
+		{Path: "/api/users", Method: "GET", Status: 42},
+	} {
+		if _, err := Endpoint(opts); err == nil {
+			t.Errorf("Endpoint(%+v) returned no error", opts)
+		}
+	}
+}
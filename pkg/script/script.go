@@ -0,0 +1,242 @@
+// Package script runs Lua response scripts. A script sees the incoming
+// request as the global table `request` and returns the response as a table:
+//
+//	return { status = 201, headers = { ["X-Id"] = "1" }, body = { id = 1 } }
+//
+// A table body is encoded as JSON, a string body is sent as is. The global
+// `json` table offers json.encode and json.decode. Only the base, table,
+// string and math libraries are loaded, so scripts cannot touch files or
+// run programs.
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// DefaultTimeout bounds scripts that configure no timeout_ms
+const DefaultTimeout = time.Second
+
+// Request is the view of the incoming request given to scripts
+type Request struct {
+	Method  string
+	Path    string
+	Query   map[string]string
+	Headers map[string]string
+	Params  map[string]string // path parameters
+	Values  map[string]string // selector, counter and scenario values
+	Body    string
+}
+
+// Response is what a script returned
+type Response struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+}
+
+// Compile checks that source parses as Lua
+func Compile(source, name string) error {
+	chunk, err := parse.Parse(strings.NewReader(source), name)
+	if err != nil {
+		return err
+	}
+	_, err = lua.Compile(chunk, name)
+	return err
+}
+
+// Run executes source for req. A timeout of zero uses DefaultTimeout.
+func Run(source, name string, req Request, timeout time.Duration) (*Response, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	L.SetContext(ctx)
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	// The base library can load code from files
+	L.SetGlobal("dofile", lua.LNil)
+	L.SetGlobal("loadfile", lua.LNil)
+
+	L.SetGlobal("json", jsonModule(L))
+	L.SetGlobal("request", requestTable(L, req))
+
+	fn, err := L.Load(strings.NewReader(source), name)
+	if err != nil {
+		return nil, err
+	}
+	L.Push(fn)
+	if err := L.PCall(0, 1, nil); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("script %s timed out after %v", name, timeout)
+		}
+		return nil, err
+	}
+
+	ret, ok := L.Get(-1).(*lua.LTable)
+	if !ok {
+		return nil, fmt.Errorf("script %s must return a table, got %s", name, L.Get(-1).Type())
+	}
+	return toResponse(ret)
+}
+
+func requestTable(L *lua.LState, req Request) *lua.LTable {
+	t := L.NewTable()
+	t.RawSetString("method", lua.LString(req.Method))
+	t.RawSetString("path", lua.LString(req.Path))
+	t.RawSetString("body", lua.LString(req.Body))
+	t.RawSetString("query", stringTable(L, req.Query))
+	t.RawSetString("headers", stringTable(L, req.Headers))
+	t.RawSetString("params", stringTable(L, req.Params))
+	t.RawSetString("values", stringTable(L, req.Values))
+
+	var decoded interface{}
+	if json.Unmarshal([]byte(req.Body), &decoded) == nil {
+		t.RawSetString("json", toLua(L, decoded))
+	}
+	return t
+}
+
+func stringTable(L *lua.LState, m map[string]string) *lua.LTable {
+	t := L.NewTable()
+	for k, v := range m {
+		t.RawSetString(k, lua.LString(v))
+	}
+	return t
+}
+
+func jsonModule(L *lua.LState) *lua.LTable {
+	mod := L.NewTable()
+	mod.RawSetString("encode", L.NewFunction(func(L *lua.LState) int {
+		data, err := json.Marshal(fromLua(L.CheckAny(1)))
+		if err != nil {
+			L.RaiseError("json.encode: %v", err)
+		}
+		L.Push(lua.LString(data))
+		return 1
+	}))
+	mod.RawSetString("decode", L.NewFunction(func(L *lua.LState) int {
+		var v interface{}
+		if err := json.Unmarshal([]byte(L.CheckString(1)), &v); err != nil {
+			L.RaiseError("json.decode: %v", err)
+		}
+		L.Push(toLua(L, v))
+		return 1
+	}))
+	return mod
+}
+
+func toResponse(t *lua.LTable) (*Response, error) {
+	resp := &Response{Headers: make(map[string]string)}
+
+	switch status := t.RawGetString("status").(type) {
+	case lua.LNumber:
+		resp.Status = int(status)
+	case *lua.LNilType:
+	default:
+		return nil, errors.New("status must be a number")
+	}
+
+	if headers, ok := t.RawGetString("headers").(*lua.LTable); ok {
+		headers.ForEach(func(k, v lua.LValue) {
+			resp.Headers[k.String()] = v.String()
+		})
+	}
+
+	switch body := t.RawGetString("body").(type) {
+	case lua.LString:
+		resp.Body = []byte(body)
+	case *lua.LNilType:
+	default:
+		data, err := json.Marshal(fromLua(body))
+		if err != nil {
+			return nil, fmt.Errorf("encoding body: %w", err)
+		}
+		resp.Body = data
+	}
+	return resp, nil
+}
+
+// toLua converts decoded JSON into Lua values
+func toLua(L *lua.LState, v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []interface{}:
+		t := L.CreateTable(len(v), 0)
+		for _, item := range v {
+			t.Append(toLua(L, item))
+		}
+		return t
+	case map[string]interface{}:
+		t := L.CreateTable(0, len(v))
+		for k, item := range v {
+			t.RawSetString(k, toLua(L, item))
+		}
+		return t
+	}
+	return lua.LString(fmt.Sprint(v))
+}
+
+// fromLua converts a Lua value into something encoding/json can marshal.
+// Tables with only keys 1..n become arrays, other tables objects.
+func fromLua(v lua.LValue) interface{} {
+	switch v := v.(type) {
+	case *lua.LNilType:
+		return nil
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if n := v.Len(); n > 0 && countKeys(v) == n {
+			arr := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				arr = append(arr, fromLua(v.RawGetInt(i)))
+			}
+			return arr
+		}
+		obj := make(map[string]interface{})
+		v.ForEach(func(k, item lua.LValue) {
+			obj[k.String()] = fromLua(item)
+		})
+		return obj
+	}
+	return v.String()
+}
+
+func countKeys(t *lua.LTable) int {
+	n := 0
+	t.ForEach(func(lua.LValue, lua.LValue) { n++ })
+	return n
+}
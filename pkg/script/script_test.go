@@ -0,0 +1,66 @@
+package script
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	req := Request{
+		Method:  "POST",
+		Path:    "/orders/7",
+		Params:  map[string]string{"id": "7"},
+		Headers: map[string]string{"X-User": "ann"},
+		Body:    `{"items":[{"price":2},{"price":3}]}`,
+	}
+	source := `
+		local total = 0
+		for _, item in ipairs(request.json.items) do total = total + item.price end
+		return {
+			status = 201,
+			headers = { ["X-Order"] = request.params.id },
+			body = { id = tonumber(request.params.id), user = request.headers["X-User"], total = total, tags = {"a", "b"} },
+		}`
+
+	resp, err := Run(source, "test", req, 0)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if resp.Status != 201 || resp.Headers["X-Order"] != "7" {
+		t.Errorf("unexpected status/headers: %d %v", resp.Status, resp.Headers)
+	}
+	if string(resp.Body) != `{"id":7,"tags":["a","b"],"total":5,"user":"ann"}` {
+		t.Errorf("unexpected body: %s", resp.Body)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"no table", `return "hi"`, "must return a table"},
+		{"runtime error", `error("boom")`, "boom"},
+		{"no io", `return { body = io.open("/etc/passwd") }`, "non-table object"},
+		{"timeout", `while true do end`, "timed out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(tt.source, "test", Request{}, 50*time.Millisecond)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if err := Compile(`return { body = "ok" }`, "ok"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := Compile(`return {`, "bad"); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
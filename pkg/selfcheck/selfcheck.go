@@ -0,0 +1,256 @@
+// Package selfcheck runs deep checks of a loaded config that validation
+// leaves out: response files are read and parsed as the content type they
+// are served with, templated files are rendered with dummy data first, and
+// the hosts of responder hooks and scenario webhooks are resolved.
+package selfcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/template"
+)
+
+// Check statuses
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check kinds
+const (
+	KindResponseFile = "response_file"
+	KindTemplate     = "template"
+	KindHost         = "host"
+)
+
+// Check is the outcome of checking one file or host
+type Check struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"` // where the config refers to the target, e.g. endpoint[0].rule[1]
+	Target   string `json:"target"`   // file path or host
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Summary counts the checks by status
+type Summary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// Report is the machine-readable result of a self-check
+type Report struct {
+	OK        bool      `json:"ok"`
+	CheckedAt time.Time `json:"checked_at"`
+	Summary   Summary   `json:"summary"`
+	Checks    []Check   `json:"checks"`
+}
+
+// Failures returns the failed checks
+func (r *Report) Failures() []Check {
+	var failed []Check
+	for _, c := range r.Checks {
+		if c.Status == StatusFail {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// LookupHost resolves a host name, as net.Resolver.LookupHost
+type LookupHost func(ctx context.Context, host string) ([]string, error)
+
+// dummyValue stands in for every template placeholder. It is valid both
+// inside a JSON string and as a bare JSON number.
+const dummyValue = "0"
+
+// Run checks cfg. lookup resolves hook hosts, net.DefaultResolver when nil.
+func Run(ctx context.Context, cfg *config.Config, lookup LookupHost) *Report {
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupHost
+	}
+	r := &Report{CheckedAt: time.Now().UTC(), Checks: []Check{}}
+
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		loc := fmt.Sprintf("endpoint[%d]", i)
+		r.checkResponse(loc+".default", ep.Default)
+		for j, rule := range ep.Rules {
+			r.checkResponse(fmt.Sprintf("%s.rule[%d]", loc, j), rule.ResponseConfig)
+		}
+		methods := make([]string, 0, len(ep.MethodDefaults))
+		for method := range ep.MethodDefaults {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			r.checkResponse(loc+".method_defaults."+method, ep.MethodDefaults[method])
+		}
+		if ep.Challenge != nil && ep.Challenge.Unauthorized != nil {
+			r.checkResponse(loc+".challenge.unauthorized", *ep.Challenge.Unauthorized)
+		}
+		if ep.Quota != nil && ep.Quota.Exceeded != nil {
+			r.checkResponse(loc+".quota.exceeded", *ep.Quota.Exceeded)
+		}
+	}
+
+	hosts := make(map[string][]string) // host -> locations referring to it
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		loc := fmt.Sprintf("endpoint[%d]", i)
+		addHook(hosts, loc+".default.responder", ep.Default.Responder)
+		for j, rule := range ep.Rules {
+			addHook(hosts, fmt.Sprintf("%s.rule[%d].responder", loc, j), rule.Responder)
+		}
+	}
+	for name, sc := range cfg.Scenarios {
+		for j, wh := range sc.Webhooks {
+			addHost(hosts, fmt.Sprintf("scenarios.%s.webhooks[%d]", name, j), wh.URL)
+		}
+	}
+	r.checkHosts(ctx, hosts, lookup)
+
+	for _, c := range r.Checks {
+		r.Summary.Total++
+		if c.Status == StatusFail {
+			r.Summary.Failed++
+		} else {
+			r.Summary.Passed++
+		}
+	}
+	r.OK = r.Summary.Failed == 0
+	return r
+}
+
+// checkResponse checks the files of one response config
+func (r *Report) checkResponse(loc string, resp config.ResponseConfig) {
+	contentType := "application/json" // as served when no header overrides it
+	for name, value := range resp.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			contentType = value
+		}
+	}
+	templated := resp.Template != nil && resp.Template.Enabled
+
+	if resp.ResponseFile != "" {
+		r.checkFile(loc, resp.ResponseFile, contentType, templated)
+	}
+	if resp.RandomResponses != nil && resp.RandomResponses.Enabled {
+		for i, rr := range resp.RandomResponses.Files {
+			r.checkFile(fmt.Sprintf("%s.random_responses[%d]", loc, i), rr.File, contentType, templated)
+		}
+	}
+}
+
+// checkFile reads a response file and parses it as contentType, rendering
+// it with dummy data first when it is templated
+func (r *Report) checkFile(loc, path, contentType string, templated bool) {
+	kind := KindResponseFile
+	if templated {
+		kind = KindTemplate
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.add(Check{Kind: kind, Location: loc, Target: path, Status: StatusFail, Message: err.Error()})
+		return
+	}
+	if templated {
+		values := make(map[string]string)
+		for _, name := range template.ExtractPlaceholders(data) {
+			values[name] = dummyValue
+		}
+		data = template.ReplaceVariables(data, values)
+	}
+	if err := parseAs(data, contentType); err != nil {
+		r.add(Check{Kind: kind, Location: loc, Target: path, Status: StatusFail, Message: err.Error()})
+		return
+	}
+	r.add(Check{Kind: kind, Location: loc, Target: path, Status: StatusOK})
+}
+
+// parseAs checks that data is well-formed for JSON and XML content types;
+// other types only need to be readable
+func parseAs(data []byte, contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil
+		}
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("not valid JSON for %s: %w", mediaType, err)
+		}
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			_, err := dec.Token()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("not valid XML for %s: %w", mediaType, err)
+			}
+		}
+	}
+	return nil
+}
+
+// addHook records the host of an HTTP responder hook
+func addHook(hosts map[string][]string, loc string, responder *config.ResponderConfig) {
+	if responder != nil && responder.URL != "" {
+		addHost(hosts, loc, responder.URL)
+	}
+}
+
+func addHost(hosts map[string][]string, loc, rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return // reported by validation
+	}
+	hosts[u.Hostname()] = append(hosts[u.Hostname()], loc)
+}
+
+// checkHosts resolves every referenced host once
+func (r *Report) checkHosts(ctx context.Context, hosts map[string][]string, lookup LookupHost) {
+	names := make([]string, 0, len(hosts))
+	for host := range hosts {
+		names = append(names, host)
+	}
+	sort.Strings(names)
+
+	for _, host := range names {
+		check := Check{Kind: KindHost, Location: strings.Join(hosts[host], ", "), Target: host, Status: StatusOK}
+		if net.ParseIP(host) == nil {
+			lookupCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if _, err := lookup(lookupCtx, host); err != nil {
+				check.Status = StatusFail
+				check.Message = err.Error()
+			}
+			cancel()
+		}
+		r.add(check)
+	}
+}
+
+func (r *Report) add(c Check) {
+	r.Checks = append(r.Checks, c)
+}
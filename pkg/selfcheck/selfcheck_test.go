@@ -0,0 +1,81 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mock-api-server/config"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := write("valid.json", `{"ok": true}`)
+	broken := write("broken.json", `{"ok": `)
+	templated := write("templated.json", `{"id": "{{.id}}", "count": {{.counter.orders}}}`)
+	xmlFile := write("user.xml", `<user><id>1</id></user>`)
+	text := write("plain.txt", `not json`)
+
+	cfg := &config.Config{
+		Endpoints: []config.Endpoint{
+			{
+				Path: "/a", Method: "GET",
+				Default: config.ResponseConfig{ResponseFile: valid},
+				Rules: []config.Rule{
+					{ResponseConfig: config.ResponseConfig{ResponseFile: broken}},
+					{ResponseConfig: config.ResponseConfig{ResponseFile: filepath.Join(dir, "missing.json")}},
+					{ResponseConfig: config.ResponseConfig{ResponseFile: xmlFile, Headers: map[string]string{"content-type": "application/xml"}}},
+					{ResponseConfig: config.ResponseConfig{ResponseFile: text, Headers: map[string]string{"Content-Type": "text/plain"}}},
+					{ResponseConfig: config.ResponseConfig{Responder: &config.ResponderConfig{URL: "http://hooks.internal:9000/respond"}}},
+				},
+			},
+			{
+				Path: "/b", Method: "GET",
+				Default: config.ResponseConfig{ResponseFile: templated, Template: &config.TemplateConfig{Enabled: true}},
+			},
+		},
+		Scenarios: map[string]config.ScenarioConfig{
+			"checkout": {Webhooks: []config.ScenarioWebhook{{URL: "http://127.0.0.1:8081/hook"}, {URL: "https://unknown.invalid/hook"}}},
+		},
+	}
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		if host == "hooks.internal" {
+			return []string{"10.0.0.1"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	report := Run(context.Background(), cfg, lookup)
+
+	want := map[string]string{
+		"endpoint[0].default":            StatusOK,
+		"endpoint[0].rule[0]":            StatusFail,
+		"endpoint[0].rule[1]":            StatusFail,
+		"endpoint[0].rule[2]":            StatusOK,
+		"endpoint[0].rule[3]":            StatusOK,
+		"endpoint[1].default":            StatusOK,
+		"endpoint[0].rule[4].responder":  StatusOK,
+		"scenarios.checkout.webhooks[0]": StatusOK,
+		"scenarios.checkout.webhooks[1]": StatusFail,
+	}
+	if len(report.Checks) != len(want) {
+		t.Fatalf("expected %d checks, got %+v", len(want), report.Checks)
+	}
+	for _, c := range report.Checks {
+		if status, ok := want[c.Location]; !ok || status != c.Status {
+			t.Errorf("%s %s: status %s (%s), want %q", c.Location, c.Target, c.Status, c.Message, status)
+		}
+	}
+	if report.OK || report.Summary.Failed != 3 || report.Summary.Passed != 6 || len(report.Failures()) != 3 {
+		t.Errorf("unexpected summary %+v", report.Summary)
+	}
+}
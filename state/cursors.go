@@ -0,0 +1,89 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+)
+
+// maxCursors bounds how many pagination cursors are remembered; the oldest
+// are forgotten first
+const maxCursors = 10000
+
+// Cursor is the position in a crud collection a pagination token stands for
+type Cursor struct {
+	Resource string
+	AfterID  string // ID of the last item already returned
+	NextID   string // ID of the item that followed it
+	Offset   int    // index of the next item when the cursor was issued
+	Limit    int    // page size the cursor was issued with
+}
+
+// Start returns the index of the first item after the cursor. Items added
+// or removed elsewhere don't shift the position; when both the last returned
+// item and the one after it were deleted, the original offset is used.
+func (c Cursor) Start(items []Item, idField string) int {
+	next := -1
+	for i, item := range items {
+		switch fmt.Sprint(item[idField]) {
+		case c.AfterID:
+			return i + 1
+		case c.NextID:
+			next = i
+		}
+	}
+	if next >= 0 {
+		return next
+	}
+	if c.Offset > len(items) {
+		return len(items)
+	}
+	return c.Offset
+}
+
+// CursorStore issues opaque tokens for pagination cursors so that
+// next_cursor values work across calls
+type CursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]Cursor
+	order   []string
+}
+
+// NewCursorStore creates an empty CursorStore
+func NewCursorStore() *CursorStore {
+	return &CursorStore{cursors: make(map[string]Cursor)}
+}
+
+// Issue stores a cursor and returns its token
+func (s *CursorStore) Issue(cursor Cursor) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[token] = cursor
+	s.order = append(s.order, token)
+	if len(s.order) > maxCursors {
+		delete(s.cursors, s.order[0])
+		s.order = s.order[1:]
+	}
+	return token
+}
+
+// Lookup returns the cursor a token stands for
+func (s *CursorStore) Lookup(token string) (Cursor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cursor, ok := s.cursors[token]
+	return cursor, ok
+}
+
+// ResetAll forgets every cursor
+func (s *CursorStore) ResetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors = make(map[string]Cursor)
+	s.order = nil
+}
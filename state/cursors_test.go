@@ -0,0 +1,37 @@
+package state
+
+import "testing"
+
+func TestCursorStore(t *testing.T) {
+	store := NewCursorStore()
+	token := store.Issue(Cursor{Resource: "users", AfterID: "2", NextID: "3", Offset: 3})
+	if token == "" {
+		t.Fatal("expected a token")
+	}
+
+	cursor, ok := store.Lookup(token)
+	if !ok || cursor.Resource != "users" {
+		t.Fatalf("expected to look up the issued cursor, got %+v %v", cursor, ok)
+	}
+
+	items := []Item{{"id": "0"}, {"id": "1"}, {"id": "2"}, {"id": "3"}}
+	if got := cursor.Start(items, "id"); got != 3 {
+		t.Errorf("expected to resume after id 2, got index %d", got)
+	}
+	// The last returned item was deleted: resume at the one after it
+	if got := cursor.Start([]Item{{"id": "0"}, {"id": "1"}, {"id": "3"}}, "id"); got != 2 {
+		t.Errorf("expected to resume at id 3, got index %d", got)
+	}
+	// Both are gone: fall back to the offset
+	if got := cursor.Start([]Item{{"id": "0"}, {"id": "1"}, {"id": "4"}, {"id": "5"}}, "id"); got != 3 {
+		t.Errorf("expected to resume at offset 3, got index %d", got)
+	}
+	if got := cursor.Start([]Item{{"id": "9"}}, "id"); got != 1 {
+		t.Errorf("expected start clamped to the collection, got %d", got)
+	}
+
+	store.ResetAll()
+	if _, ok := store.Lookup(token); ok {
+		t.Error("expected cursor to be forgotten after ResetAll")
+	}
+}
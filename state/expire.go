@@ -0,0 +1,36 @@
+package state
+
+import "time"
+
+// StartExpirer checks every interval for partitions that outlived the TTL
+// returned by ttls for their scenario, and resets them to DefaultStep.
+// ttls is called on each check so TTL changes apply on hot reload. The
+// returned func stops the expirer.
+func StartExpirer(store Store, ttls func() map[string]time.Duration, interval time.Duration, onExpire func(scenario string, expired []PartitionState)) func() {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				for scenario, ttl := range ttls() {
+					if ttl <= 0 {
+						continue
+					}
+					if expired := store.ExpireIdle(scenario, ttl, now); len(expired) > 0 && onExpire != nil {
+						onExpire(scenario, expired)
+					}
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
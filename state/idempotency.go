@@ -0,0 +1,96 @@
+package state
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"mock-api-server/pkg/lru"
+)
+
+// maxIdempotencyKeys bounds how many keys are remembered; the least
+// recently used are forgotten first
+const maxIdempotencyKeys = 10000
+
+// Idempotency key states reported by Begin
+const (
+	IdempotencyNew      = iota // key claimed, the caller must Complete or Abandon it
+	IdempotencyReplay          // a response is stored for the key
+	IdempotencyInFlight        // another request with the key is being handled
+	IdempotencyMismatch        // the key was used for a different request
+)
+
+// IdempotentResponse is a response remembered for an idempotency key
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type idempotencyEntry struct {
+	requestHash string
+	response    *IdempotentResponse // nil while the first request is in flight
+}
+
+// IdempotencyStore remembers responses by idempotency key, so retries of a
+// request get the original response again
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	entries *lru.Cache[*idempotencyEntry]
+	now     func() time.Time
+}
+
+// NewIdempotencyStore creates an empty IdempotencyStore
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{entries: lru.New[*idempotencyEntry](maxIdempotencyKeys), now: time.Now}
+}
+
+// Begin looks up key for a request identified by requestHash. For an
+// unknown or expired key it claims the key for ttl and returns
+// IdempotencyNew; for a completed one with the same request it returns the
+// stored response.
+func (s *IdempotencyStore) Begin(key, requestHash string, ttl time.Duration) (int, *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if e, ok := s.entries.Get(key, now); ok {
+		switch {
+		case e.requestHash != requestHash:
+			return IdempotencyMismatch, nil
+		case e.response == nil:
+			return IdempotencyInFlight, nil
+		default:
+			return IdempotencyReplay, e.response
+		}
+	}
+
+	s.entries.Put(key, &idempotencyEntry{requestHash: requestHash}, now.Add(ttl))
+	return IdempotencyNew, nil
+}
+
+// Complete stores the response for a key claimed by Begin
+func (s *IdempotencyStore) Complete(key string, resp *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries.Get(key, s.now()); ok {
+		e.response = resp
+	}
+}
+
+// Abandon releases a key claimed by Begin without storing a response, so
+// the next request with the key is handled anew
+func (s *IdempotencyStore) Abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries.Get(key, s.now()); ok && e.response == nil {
+		s.entries.Delete(key)
+	}
+}
+
+// ResetAll forgets every key
+func (s *IdempotencyStore) ResetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries.Reset()
+}
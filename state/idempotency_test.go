@@ -0,0 +1,36 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewIdempotencyStore()
+	s.now = func() time.Time { return now }
+
+	if st, _ := s.Begin("k", "req", time.Minute); st != IdempotencyNew {
+		t.Fatalf("expected a new key, got %d", st)
+	}
+	if st, _ := s.Begin("k", "req", time.Minute); st != IdempotencyInFlight {
+		t.Fatalf("expected the key to be in flight, got %d", st)
+	}
+
+	s.Complete("k", &IdempotentResponse{Status: 201, Body: []byte("ok")})
+	if st, resp := s.Begin("k", "req", time.Minute); st != IdempotencyReplay || resp.Status != 201 {
+		t.Fatalf("expected the stored response, got %d %+v", st, resp)
+	}
+	if st, _ := s.Begin("k", "other", time.Minute); st != IdempotencyMismatch {
+		t.Fatalf("expected a mismatch for a different request, got %d", st)
+	}
+
+	now = now.Add(time.Minute)
+	if st, _ := s.Begin("k", "other", time.Minute); st != IdempotencyNew {
+		t.Fatalf("expected the expired key to be claimed anew, got %d", st)
+	}
+	s.Abandon("k")
+	if st, _ := s.Begin("k", "other", time.Minute); st != IdempotencyNew {
+		t.Fatalf("expected an abandoned key to be claimed anew, got %d", st)
+	}
+}
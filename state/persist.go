@@ -0,0 +1,110 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// persistedState is the on-disk format of a ScenarioStore
+type persistedState struct {
+	SavedAt   time.Time                   `json:"saved_at"`
+	Scenarios map[string][]PartitionState `json:"scenarios"`
+}
+
+// LoadFile restores store from a file written by SaveFile. A missing file is
+// not an error, so the first start with persistence enabled begins empty.
+func LoadFile(store Store, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var persisted persistedState
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return err
+	}
+	store.Restore(persisted.Scenarios)
+	return nil
+}
+
+// SaveFile writes store to path atomically, so a crash mid-write never
+// leaves a truncated file behind
+func SaveFile(store Store, path string) error {
+	data, err := json.MarshalIndent(persistedState{
+		SavedAt:   time.Now(),
+		Scenarios: store.Snapshot(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// StartFlusher saves store to path every interval while it has changed. The
+// returned stop func performs a final flush.
+func StartFlusher(store Store, path string, interval time.Duration, onError func(error)) func() {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	saved := store.Version()
+	flush := func() {
+		version := store.Version()
+		if version == saved {
+			return
+		}
+		if err := SaveFile(store, path); err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			return
+		}
+		saved = version
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				flush()
+			case <-done:
+				ticker.Stop()
+				flush()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,479 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds every Redis round trip so a dead backend cannot hang requests
+const redisTimeout = 2 * time.Second
+
+// maxTxRetries is how often a partition update is retried when another
+// replica modifies the same scenario concurrently
+const maxTxRetries = 10
+
+// RedisStore is a Store shared by every mock replica using the same Redis
+// and key prefix. Keys:
+//
+//	<prefix>scenarios         set of scenario names
+//	<prefix>scenario:<name>   hash of partition -> PartitionState JSON
+//	<prefix>history:<name>    list of Transition JSON, oldest first
+//	<prefix>counters          hash of counter name -> value
+//	<prefix>version           change counter
+//
+// Store methods cannot fail, so Redis errors are passed to onError and reads
+// fall back to defaults (e.g. DefaultStep).
+type RedisStore struct {
+	client  redis.UniversalClient
+	prefix  string
+	onError func(error)
+}
+
+// NewRedisStore creates a RedisStore. onError may be nil.
+func NewRedisStore(client redis.UniversalClient, prefix string, onError func(error)) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix, onError: onError}
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func (s *RedisStore) scenariosKey() string           { return s.prefix + "scenarios" }
+func (s *RedisStore) scenarioKey(name string) string { return s.prefix + "scenario:" + name }
+func (s *RedisStore) historyKey(name string) string  { return s.prefix + "history:" + name }
+func (s *RedisStore) countersKey() string            { return s.prefix + "counters" }
+func (s *RedisStore) versionKey() string             { return s.prefix + "version" }
+
+func (s *RedisStore) fail(err error) {
+	if err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+func (s *RedisStore) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), redisTimeout)
+}
+
+// get loads one partition, returning nil when it does not exist
+func (s *RedisStore) get(ctx context.Context, scenario, partition string) *PartitionState {
+	data, err := s.client.HGet(ctx, s.scenarioKey(scenario), normalizePartition(partition)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			s.fail(err)
+		}
+		return nil
+	}
+	var ps PartitionState
+	if err := json.Unmarshal(data, &ps); err != nil {
+		s.fail(err)
+		return nil
+	}
+	return &ps
+}
+
+// update applies fn to a partition inside an optimistic transaction. fn
+// receives nil for a missing partition and returns the new state, or nil to
+// leave the partition unchanged.
+func (s *RedisStore) update(scenario, partition string, fn func(ps *PartitionState) *PartitionState) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	partition = normalizePartition(partition)
+	key := s.scenarioKey(scenario)
+	txf := func(tx *redis.Tx) error {
+		var current *PartitionState
+		data, err := tx.HGet(ctx, key, partition).Bytes()
+		switch {
+		case err == nil:
+			current = &PartitionState{}
+			if err := json.Unmarshal(data, current); err != nil {
+				return err
+			}
+		case !errors.Is(err, redis.Nil):
+			return err
+		}
+
+		next := fn(current)
+		if next == nil {
+			return nil
+		}
+		next.Partition = partition
+		encoded, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, partition, encoded)
+			pipe.SAdd(ctx, s.scenariosKey(), scenario)
+			pipe.Incr(ctx, s.versionKey())
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < maxTxRetries; i++ {
+		err := s.client.Watch(ctx, txf, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			s.fail(err)
+			return
+		}
+	}
+	s.fail(errors.New("redis: too many concurrent updates to scenario " + scenario))
+}
+
+// GetStep returns the current step of a partition, or DefaultStep if unset
+func (s *RedisStore) GetStep(scenario, partition string) string {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	if ps := s.get(ctx, scenario, partition); ps != nil {
+		return ps.Step
+	}
+	return DefaultStep
+}
+
+// SetStep moves a partition to the given step, keeping its variables
+func (s *RedisStore) SetStep(scenario, partition, step string) {
+	s.update(scenario, partition, func(ps *PartitionState) *PartitionState {
+		now := time.Now()
+		next := &PartitionState{Step: step, UpdatedAt: now, LastSeenAt: now}
+		if ps != nil {
+			next.Variables = ps.Variables
+		}
+		return next
+	})
+}
+
+// Touch records activity on an existing partition so it does not expire
+func (s *RedisStore) Touch(scenario, partition string) {
+	s.update(scenario, partition, func(ps *PartitionState) *PartitionState {
+		if ps == nil {
+			return nil
+		}
+		ps.LastSeenAt = time.Now()
+		return ps
+	})
+}
+
+// GetVariables returns the variables of a partition
+func (s *RedisStore) GetVariables(scenario, partition string) map[string]string {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	return s.get(ctx, scenario, partition).variables()
+}
+
+// SetVariables merges vars into the variables of a partition, creating it in
+// DefaultStep if it does not exist yet
+func (s *RedisStore) SetVariables(scenario, partition string, vars map[string]string) {
+	s.update(scenario, partition, func(ps *PartitionState) *PartitionState {
+		if ps == nil {
+			now := time.Now()
+			ps = &PartitionState{Step: DefaultStep, UpdatedAt: now, LastSeenAt: now}
+		}
+		if ps.Variables == nil {
+			ps.Variables = make(map[string]string, len(vars))
+		}
+		for k, v := range vars {
+			ps.Variables[k] = v
+		}
+		return ps
+	})
+}
+
+// partitions loads every partition of a scenario
+func (s *RedisStore) partitions(ctx context.Context, scenario string) []PartitionState {
+	entries, err := s.client.HGetAll(ctx, s.scenarioKey(scenario)).Result()
+	if err != nil {
+		s.fail(err)
+		return []PartitionState{}
+	}
+	result := make([]PartitionState, 0, len(entries))
+	for _, data := range entries {
+		var ps PartitionState
+		if err := json.Unmarshal([]byte(data), &ps); err != nil {
+			s.fail(err)
+			continue
+		}
+		result = append(result, ps)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Partition < result[j].Partition
+	})
+	return result
+}
+
+// Partitions returns all partitions of a scenario sorted by partition key
+func (s *RedisStore) Partitions(scenario string) []PartitionState {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	return s.partitions(ctx, scenario)
+}
+
+// Scenarios returns the names of all scenarios with at least one partition
+func (s *RedisStore) Scenarios() []string {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	names, err := s.client.SMembers(ctx, s.scenariosKey()).Result()
+	if err != nil {
+		s.fail(err)
+		return []string{}
+	}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		n, err := s.client.HLen(ctx, s.scenarioKey(name)).Result()
+		if err != nil {
+			s.fail(err)
+			continue
+		}
+		if n > 0 {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Reset removes a partition, returning it to DefaultStep
+func (s *RedisStore) Reset(scenario, partition string) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, s.scenarioKey(scenario), normalizePartition(partition))
+		pipe.Incr(ctx, s.versionKey())
+		return nil
+	})
+	s.fail(err)
+}
+
+// ResetAll removes every scenario partition and all history
+func (s *RedisStore) ResetAll() {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	names, err := s.client.SMembers(ctx, s.scenariosKey()).Result()
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	keys := []string{s.scenariosKey()}
+	for _, name := range names {
+		keys = append(keys, s.scenarioKey(name), s.historyKey(name))
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		pipe.Incr(ctx, s.versionKey())
+		return nil
+	})
+	s.fail(err)
+}
+
+// ExpireIdle removes the partitions of scenario that have been inactive for
+// longer than ttl and returns them. With several replicas each expired
+// partition is returned by exactly one of them.
+func (s *RedisStore) ExpireIdle(scenario string, ttl time.Duration, now time.Time) []PartitionState {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	var expired []PartitionState
+	for _, ps := range s.partitions(ctx, scenario) {
+		if now.Sub(ps.LastSeenAt) <= ttl {
+			continue
+		}
+		removed, err := s.client.HDel(ctx, s.scenarioKey(scenario), ps.Partition).Result()
+		if err != nil {
+			s.fail(err)
+			continue
+		}
+		if removed > 0 {
+			expired = append(expired, ps)
+		}
+	}
+	if len(expired) > 0 {
+		s.fail(s.client.Incr(ctx, s.versionKey()).Err())
+	}
+	return expired
+}
+
+// RecordTransition appends t to the history of scenario, dropping the
+// oldest entries beyond MaxHistory
+func (s *RedisStore) RecordTransition(scenario string, t Transition) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	t.Partition = normalizePartition(t.Partition)
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	encoded, err := json.Marshal(t)
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, s.historyKey(scenario), encoded)
+		pipe.LTrim(ctx, s.historyKey(scenario), -MaxHistory, -1)
+		pipe.SAdd(ctx, s.scenariosKey(), scenario)
+		return nil
+	})
+	s.fail(err)
+}
+
+// History returns the recorded transitions of scenario, oldest first. A
+// non-empty partition restricts the result to that partition.
+func (s *RedisStore) History(scenario, partition string) []Transition {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	entries, err := s.client.LRange(ctx, s.historyKey(scenario), 0, -1).Result()
+	if err != nil {
+		s.fail(err)
+		return []Transition{}
+	}
+	result := make([]Transition, 0, len(entries))
+	for _, data := range entries {
+		var t Transition
+		if err := json.Unmarshal([]byte(data), &t); err != nil {
+			s.fail(err)
+			continue
+		}
+		if partition == "" || t.Partition == partition {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Increment adds delta to a counter and returns the new value
+func (s *RedisStore) Increment(name string, delta int64) int64 {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.HIncrBy(ctx, s.countersKey(), name, delta)
+		pipe.Incr(ctx, s.versionKey())
+		return nil
+	})
+	if err != nil {
+		s.fail(err)
+		return 0
+	}
+	return incr.Val()
+}
+
+// GetCounter returns the value of a counter, 0 if it was never incremented
+func (s *RedisStore) GetCounter(name string) int64 {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	v, err := s.client.HGet(ctx, s.countersKey(), name).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.fail(err)
+	}
+	return v
+}
+
+// ResetCounter sets a counter back to 0; an empty name resets all counters
+func (s *RedisStore) ResetCounter(name string) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if name == "" {
+			pipe.Del(ctx, s.countersKey())
+		} else {
+			pipe.HDel(ctx, s.countersKey(), name)
+		}
+		pipe.Incr(ctx, s.versionKey())
+		return nil
+	})
+	s.fail(err)
+}
+
+// Counters returns every counter
+func (s *RedisStore) Counters() map[string]int64 {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	entries, err := s.client.HGetAll(ctx, s.countersKey()).Result()
+	if err != nil {
+		s.fail(err)
+		return map[string]int64{}
+	}
+	counters := make(map[string]int64, len(entries))
+	for name, value := range entries {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			s.fail(err)
+			continue
+		}
+		counters[name] = v
+	}
+	return counters
+}
+
+// Version returns a counter that changes whenever any replica modifies the store
+func (s *RedisStore) Version() uint64 {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	v, err := s.client.Get(ctx, s.versionKey()).Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		s.fail(err)
+	}
+	return v
+}
+
+// Snapshot returns every scenario with its partitions
+func (s *RedisStore) Snapshot() map[string][]PartitionState {
+	snapshot := make(map[string][]PartitionState)
+	for _, name := range s.Scenarios() {
+		snapshot[name] = s.Partitions(name)
+	}
+	return snapshot
+}
+
+// Restore replaces all scenario state with a snapshot. History is kept.
+func (s *RedisStore) Restore(snapshot map[string][]PartitionState) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	names, err := s.client.SMembers(ctx, s.scenariosKey()).Result()
+	if err != nil {
+		s.fail(err)
+		return
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, name := range names {
+			pipe.Del(ctx, s.scenarioKey(name))
+		}
+		for name, partitions := range snapshot {
+			for _, ps := range partitions {
+				ps.Partition = normalizePartition(ps.Partition)
+				if ps.UpdatedAt.IsZero() {
+					ps.UpdatedAt = time.Now()
+				}
+				if ps.LastSeenAt.IsZero() {
+					ps.LastSeenAt = ps.UpdatedAt
+				}
+				encoded, err := json.Marshal(ps)
+				if err != nil {
+					return err
+				}
+				pipe.HSet(ctx, s.scenarioKey(name), ps.Partition, encoded)
+				pipe.SAdd(ctx, s.scenariosKey(), name)
+			}
+		}
+		pipe.Incr(ctx, s.versionKey())
+		return nil
+	})
+	s.fail(err)
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T) *RedisStore {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisStore(client, "test:", func(err error) { t.Errorf("redis error: %v", err) })
+}
+
+// testStore exercises the Store contract shared by every backend
+func testStore(t *testing.T, store Store) {
+	if step := store.GetStep("checkout", "u1"); step != DefaultStep {
+		t.Fatalf("expected %q for unknown partition, got %q", DefaultStep, step)
+	}
+
+	before := store.Version()
+	store.SetVariables("checkout", "u1", map[string]string{"order": "A1"})
+	store.SetStep("checkout", "u1", "paid")
+	store.SetStep("checkout", "", "cart")
+	if store.Version() == before {
+		t.Error("expected version to change")
+	}
+
+	if step := store.GetStep("checkout", "u1"); step != "paid" {
+		t.Errorf("expected step paid, got %q", step)
+	}
+	if vars := store.GetVariables("checkout", "u1"); vars["order"] != "A1" {
+		t.Errorf("expected variables to survive SetStep, got %v", vars)
+	}
+	if partitions := store.Partitions("checkout"); len(partitions) != 2 || partitions[0].Partition != DefaultPartition {
+		t.Errorf("unexpected partitions: %+v", partitions)
+	}
+
+	store.RecordTransition("checkout", Transition{Partition: "u1", PreviousStep: "idle", Step: "paid", Source: "request"})
+	if history := store.History("checkout", "u1"); len(history) != 1 || history[0].Step != "paid" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	snapshot := store.Snapshot()
+	store.Reset("checkout", "u1")
+	if step := store.GetStep("checkout", "u1"); step != DefaultStep {
+		t.Errorf("expected reset partition to return %q, got %q", DefaultStep, step)
+	}
+	store.Restore(snapshot)
+	if step := store.GetStep("checkout", "u1"); step != "paid" {
+		t.Errorf("expected restored step paid, got %q", step)
+	}
+
+	expired := store.ExpireIdle("checkout", time.Minute, time.Now().Add(2*time.Minute))
+	if len(expired) != 2 {
+		t.Errorf("expected both partitions to expire, got %+v", expired)
+	}
+
+	if n := store.Increment("orders", 1); n != 1 {
+		t.Errorf("expected first increment to return 1, got %d", n)
+	}
+	store.Increment("orders", 4)
+	store.Increment("calls", 1)
+	if n := store.GetCounter("orders"); n != 5 {
+		t.Errorf("expected counter 5, got %d", n)
+	}
+	store.ResetCounter("orders")
+	if counters := store.Counters(); counters["orders"] != 0 || counters["calls"] != 1 {
+		t.Errorf("unexpected counters after reset: %v", counters)
+	}
+	store.ResetCounter("")
+	if counters := store.Counters(); len(counters) != 0 {
+		t.Errorf("expected all counters reset, got %v", counters)
+	}
+
+	store.SetStep("login", "u1", "authenticated")
+	store.ResetAll()
+	if names := store.Scenarios(); len(names) != 0 {
+		t.Errorf("expected no scenarios after ResetAll, got %v", names)
+	}
+	if history := store.History("checkout", ""); len(history) != 0 {
+		t.Errorf("expected ResetAll to clear history, got %+v", history)
+	}
+}
+
+func TestStoreContract(t *testing.T) {
+	t.Run("memory", func(t *testing.T) { testStore(t, NewScenarioStore()) })
+	t.Run("redis", func(t *testing.T) { testStore(t, newTestRedisStore(t)) })
+}
+
+func TestRedisStore_SharedBetweenReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	replica := func() *RedisStore {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return NewRedisStore(client, "mock:", nil)
+	}
+	a, b := replica(), replica()
+
+	a.SetStep("checkout", "u1", "paid")
+	if step := b.GetStep("checkout", "u1"); step != "paid" {
+		t.Errorf("expected second replica to see step paid, got %q", step)
+	}
+}
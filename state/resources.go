@@ -0,0 +1,205 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Item is one record of a CRUD resource collection
+type Item map[string]interface{}
+
+// collection keeps the items of one resource in insertion order
+type collection struct {
+	items  []Item
+	nextID int
+}
+
+// ResourceStore keeps the in-memory collections backing crud endpoints
+type ResourceStore struct {
+	mu          sync.RWMutex
+	collections map[string]*collection
+}
+
+// NewResourceStore creates an empty ResourceStore
+func NewResourceStore() *ResourceStore {
+	return &ResourceStore{
+		collections: make(map[string]*collection),
+	}
+}
+
+// Exists reports whether the resource has been created or seeded
+func (s *ResourceStore) Exists(resource string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.collections[resource]
+	return ok
+}
+
+// Seed fills an untouched resource with items. It is a no-op once the
+// resource exists, so seeding on first use never overwrites client changes.
+func (s *ResourceStore) Seed(resource, idField string, items []Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.collections[resource]; ok {
+		return
+	}
+	col := s.collection(resource)
+	for _, item := range items {
+		s.insert(col, idField, item)
+	}
+}
+
+// List returns a copy of every item of resource
+func (s *ResourceStore) List(resource string) []Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	col, ok := s.collections[resource]
+	if !ok {
+		return []Item{}
+	}
+	items := make([]Item, 0, len(col.items))
+	for _, item := range col.items {
+		items = append(items, copyItem(item))
+	}
+	return items
+}
+
+// Get returns the item whose idField equals id
+func (s *ResourceStore) Get(resource, idField, id string) (Item, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	col, ok := s.collections[resource]
+	if !ok {
+		return nil, false
+	}
+	if i := indexOf(col, idField, id); i >= 0 {
+		return copyItem(col.items[i]), true
+	}
+	return nil, false
+}
+
+// Create adds item to resource, assigning a sequential ID when idField is
+// missing. It fails if an item with the same ID already exists.
+func (s *ResourceStore) Create(resource, idField string, item Item) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col := s.collection(resource)
+	if id, ok := item[idField]; ok {
+		if indexOf(col, idField, fmt.Sprint(id)) >= 0 {
+			return nil, fmt.Errorf("%s with %s '%v' already exists", resource, idField, id)
+		}
+	}
+	return copyItem(s.insert(col, idField, item)), nil
+}
+
+// Replace swaps the stored item for item, keeping its ID
+func (s *ResourceStore) Replace(resource, idField, id string, item Item) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col, ok := s.collections[resource]
+	if !ok {
+		return nil, false
+	}
+	i := indexOf(col, idField, id)
+	if i < 0 {
+		return nil, false
+	}
+	replaced := copyItem(item)
+	replaced[idField] = col.items[i][idField]
+	col.items[i] = replaced
+	return copyItem(replaced), true
+}
+
+// Merge applies the top-level fields of patch to the stored item. The ID
+// field cannot be changed.
+func (s *ResourceStore) Merge(resource, idField, id string, patch Item) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col, ok := s.collections[resource]
+	if !ok {
+		return nil, false
+	}
+	i := indexOf(col, idField, id)
+	if i < 0 {
+		return nil, false
+	}
+	for k, v := range patch {
+		if k != idField {
+			col.items[i][k] = v
+		}
+	}
+	return copyItem(col.items[i]), true
+}
+
+// Delete removes the item whose idField equals id
+func (s *ResourceStore) Delete(resource, idField, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col, ok := s.collections[resource]
+	if !ok {
+		return false
+	}
+	i := indexOf(col, idField, id)
+	if i < 0 {
+		return false
+	}
+	col.items = append(col.items[:i], col.items[i+1:]...)
+	return true
+}
+
+// ResetAll drops every collection; seeded resources are re-seeded on next use
+func (s *ResourceStore) ResetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.collections = make(map[string]*collection)
+}
+
+func (s *ResourceStore) collection(resource string) *collection {
+	col, ok := s.collections[resource]
+	if !ok {
+		col = &collection{nextID: 1}
+		s.collections[resource] = col
+	}
+	return col
+}
+
+// insert stores a copy of item, generating the next numeric ID if needed
+func (s *ResourceStore) insert(col *collection, idField string, item Item) Item {
+	stored := copyItem(item)
+	if _, ok := stored[idField]; !ok {
+		for indexOf(col, idField, strconv.Itoa(col.nextID)) >= 0 {
+			col.nextID++
+		}
+		stored[idField] = col.nextID
+		col.nextID++
+	}
+	col.items = append(col.items, stored)
+	return stored
+}
+
+func indexOf(col *collection, idField, id string) int {
+	for i, item := range col.items {
+		if v, ok := item[idField]; ok && fmt.Sprint(v) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func copyItem(item Item) Item {
+	c := make(Item, len(item))
+	for k, v := range item {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,59 @@
+package state
+
+import "testing"
+
+func TestResourceStore_CRUD(t *testing.T) {
+	store := NewResourceStore()
+
+	created, err := store.Create("users", "id", Item{"name": "alice"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created["id"] != 1 {
+		t.Errorf("expected generated id 1, got %v", created["id"])
+	}
+	if _, err := store.Create("users", "id", Item{"id": 1.0, "name": "dup"}); err == nil {
+		t.Error("expected conflict for duplicate id")
+	}
+
+	if _, ok := store.Merge("users", "id", "1", Item{"id": 99, "role": "admin"}); !ok {
+		t.Fatal("Merge: item not found")
+	}
+	item, ok := store.Get("users", "id", "1")
+	if !ok || item["name"] != "alice" || item["role"] != "admin" || item["id"] != 1 {
+		t.Errorf("unexpected item after merge: %v", item)
+	}
+
+	replaced, ok := store.Replace("users", "id", "1", Item{"name": "bob"})
+	if !ok || replaced["name"] != "bob" || replaced["role"] != nil || replaced["id"] != 1 {
+		t.Errorf("unexpected item after replace: %v", replaced)
+	}
+
+	if !store.Delete("users", "id", "1") {
+		t.Error("Delete: item not found")
+	}
+	if len(store.List("users")) != 0 {
+		t.Error("expected empty collection after delete")
+	}
+}
+
+func TestResourceStore_SeedOnce(t *testing.T) {
+	store := NewResourceStore()
+	store.Seed("items", "sku", []Item{{"sku": "A"}, {"name": "no sku"}})
+
+	items := store.List("items")
+	if len(items) != 2 || items[1]["sku"] != 1 {
+		t.Fatalf("unexpected seeded items: %v", items)
+	}
+
+	store.Delete("items", "sku", "A")
+	store.Seed("items", "sku", []Item{{"sku": "A"}})
+	if len(store.List("items")) != 1 {
+		t.Error("Seed must not touch an existing resource")
+	}
+
+	store.ResetAll()
+	if store.Exists("items") {
+		t.Error("expected ResetAll to drop collections")
+	}
+}
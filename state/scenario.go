@@ -0,0 +1,359 @@
+package state
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultStep is the step of a partition that has never transitioned
+	DefaultStep = "idle"
+	// DefaultPartition is used when a request carries no partition key
+	DefaultPartition = "default"
+	// MaxHistory is how many transitions are kept per scenario
+	MaxHistory = 200
+)
+
+// PartitionState is the current state of one scenario partition
+type PartitionState struct {
+	Partition  string            `json:"partition"`
+	Step       string            `json:"step"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	LastSeenAt time.Time         `json:"last_seen_at"`        // last request that read or changed the step
+	Variables  map[string]string `json:"variables,omitempty"` // values captured by set_variables
+}
+
+// Transition is one recorded step change of a partition
+type Transition struct {
+	Partition    string    `json:"partition"`
+	PreviousStep string    `json:"previous_step"`
+	Step         string    `json:"step"`
+	Source       string    `json:"source"` // request, admin or ttl
+	RequestID    string    `json:"request_id,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+// ScenarioStore is the in-memory Store
+type ScenarioStore struct {
+	mu        sync.RWMutex
+	scenarios map[string]map[string]*PartitionState
+	history   map[string][]Transition // newest last, at most MaxHistory per scenario
+	counters  map[string]int64
+	version   uint64 // incremented on every change
+}
+
+// NewScenarioStore creates an empty ScenarioStore
+func NewScenarioStore() *ScenarioStore {
+	return &ScenarioStore{
+		scenarios: make(map[string]map[string]*PartitionState),
+		history:   make(map[string][]Transition),
+		counters:  make(map[string]int64),
+	}
+}
+
+// GetStep returns the current step of a partition, or DefaultStep if unset
+func (s *ScenarioStore) GetStep(scenario, partition string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if ps, ok := s.scenarios[scenario][normalizePartition(partition)]; ok {
+		return ps.Step
+	}
+	return DefaultStep
+}
+
+// SetStep moves a partition to the given step
+func (s *ScenarioStore) SetStep(scenario, partition, step string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	partition = normalizePartition(partition)
+	partitions, ok := s.scenarios[scenario]
+	if !ok {
+		partitions = make(map[string]*PartitionState)
+		s.scenarios[scenario] = partitions
+	}
+	now := time.Now()
+	partitions[partition] = &PartitionState{
+		Partition:  partition,
+		Step:       step,
+		UpdatedAt:  now,
+		LastSeenAt: now,
+		Variables:  partitions[partition].variables(),
+	}
+	s.version++
+}
+
+// GetVariables returns a copy of the variables of a partition
+func (s *ScenarioStore) GetVariables(scenario, partition string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.scenarios[scenario][normalizePartition(partition)].variables()
+}
+
+// SetVariables merges vars into the variables of a partition, creating it in
+// DefaultStep if it does not exist yet
+func (s *ScenarioStore) SetVariables(scenario, partition string, vars map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	partition = normalizePartition(partition)
+	partitions, ok := s.scenarios[scenario]
+	if !ok {
+		partitions = make(map[string]*PartitionState)
+		s.scenarios[scenario] = partitions
+	}
+	ps, ok := partitions[partition]
+	if !ok {
+		now := time.Now()
+		ps = &PartitionState{Partition: partition, Step: DefaultStep, UpdatedAt: now, LastSeenAt: now}
+		partitions[partition] = ps
+	}
+	if ps.Variables == nil {
+		ps.Variables = make(map[string]string, len(vars))
+	}
+	for k, v := range vars {
+		ps.Variables[k] = v
+	}
+	s.version++
+}
+
+// RecordTransition appends t to the history of scenario, dropping the
+// oldest entries beyond MaxHistory
+func (s *ScenarioStore) RecordTransition(scenario string, t Transition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.Partition = normalizePartition(t.Partition)
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	history := append(s.history[scenario], t)
+	if len(history) > MaxHistory {
+		history = append([]Transition(nil), history[len(history)-MaxHistory:]...)
+	}
+	s.history[scenario] = history
+}
+
+// History returns the recorded transitions of scenario, oldest first. A
+// non-empty partition restricts the result to that partition.
+func (s *ScenarioStore) History(scenario, partition string) []Transition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Transition, 0, len(s.history[scenario]))
+	for _, t := range s.history[scenario] {
+		if partition == "" || t.Partition == partition {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Increment adds delta to a counter and returns the new value
+func (s *ScenarioStore) Increment(name string, delta int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counters[name] += delta
+	s.version++
+	return s.counters[name]
+}
+
+// GetCounter returns the value of a counter, 0 if it was never incremented
+func (s *ScenarioStore) GetCounter(name string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.counters[name]
+}
+
+// ResetCounter sets a counter back to 0; an empty name resets all counters
+func (s *ScenarioStore) ResetCounter(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == "" {
+		s.counters = make(map[string]int64)
+	} else {
+		delete(s.counters, name)
+	}
+	s.version++
+}
+
+// Counters returns a copy of every counter
+func (s *ScenarioStore) Counters() map[string]int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counters := make(map[string]int64, len(s.counters))
+	for k, v := range s.counters {
+		counters[k] = v
+	}
+	return counters
+}
+
+// Touch records activity on an existing partition so it does not expire.
+// Partitions still in DefaultStep have nothing to expire and are ignored.
+func (s *ScenarioStore) Touch(scenario, partition string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ps, ok := s.scenarios[scenario][normalizePartition(partition)]; ok {
+		ps.LastSeenAt = time.Now()
+		s.version++
+	}
+}
+
+// ExpireIdle removes the partitions of scenario that have been inactive for
+// longer than ttl and returns them
+func (s *ScenarioStore) ExpireIdle(scenario string, ttl time.Duration, now time.Time) []PartitionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []PartitionState
+	for key, ps := range s.scenarios[scenario] {
+		if now.Sub(ps.LastSeenAt) > ttl {
+			expired = append(expired, *ps)
+			delete(s.scenarios[scenario], key)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	if len(s.scenarios[scenario]) == 0 {
+		delete(s.scenarios, scenario)
+	}
+	s.version++
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].Partition < expired[j].Partition
+	})
+	return expired
+}
+
+// Partitions returns all partitions of a scenario sorted by partition key
+func (s *ScenarioStore) Partitions(scenario string) []PartitionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	partitions := s.scenarios[scenario]
+	result := make([]PartitionState, 0, len(partitions))
+	for _, ps := range partitions {
+		cp := *ps
+		cp.Variables = ps.variables()
+		result = append(result, cp)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Partition < result[j].Partition
+	})
+	return result
+}
+
+// Scenarios returns the names of all scenarios with at least one partition
+func (s *ScenarioStore) Scenarios() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.scenarios))
+	for name := range s.scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reset removes a partition, returning it to DefaultStep
+func (s *ScenarioStore) Reset(scenario, partition string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.scenarios[scenario], normalizePartition(partition))
+	if len(s.scenarios[scenario]) == 0 {
+		delete(s.scenarios, scenario)
+	}
+	s.version++
+}
+
+// ResetAll removes every scenario partition
+func (s *ScenarioStore) ResetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios = make(map[string]map[string]*PartitionState)
+	s.history = make(map[string][]Transition)
+	s.version++
+}
+
+// Version returns a counter that changes whenever the store is modified
+func (s *ScenarioStore) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// Snapshot returns every scenario with its partitions
+func (s *ScenarioStore) Snapshot() map[string][]PartitionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := make(map[string][]PartitionState, len(s.scenarios))
+	for name, partitions := range s.scenarios {
+		list := make([]PartitionState, 0, len(partitions))
+		for _, ps := range partitions {
+			cp := *ps
+			cp.Variables = ps.variables()
+			list = append(list, cp)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Partition < list[j].Partition
+		})
+		snapshot[name] = list
+	}
+	return snapshot
+}
+
+// Restore replaces all scenario state with a snapshot
+func (s *ScenarioStore) Restore(snapshot map[string][]PartitionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scenarios = make(map[string]map[string]*PartitionState, len(snapshot))
+	for name, partitions := range snapshot {
+		restored := make(map[string]*PartitionState, len(partitions))
+		for _, ps := range partitions {
+			ps := ps
+			ps.Partition = normalizePartition(ps.Partition)
+			if ps.UpdatedAt.IsZero() {
+				ps.UpdatedAt = time.Now()
+			}
+			if ps.LastSeenAt.IsZero() {
+				ps.LastSeenAt = ps.UpdatedAt
+			}
+			restored[ps.Partition] = &ps
+		}
+		if len(restored) > 0 {
+			s.scenarios[name] = restored
+		}
+	}
+	s.version++
+}
+
+// variables returns a copy of the partition's variables; nil-safe
+func (ps *PartitionState) variables() map[string]string {
+	if ps == nil || len(ps.Variables) == 0 {
+		return nil
+	}
+	vars := make(map[string]string, len(ps.Variables))
+	for k, v := range ps.Variables {
+		vars[k] = v
+	}
+	return vars
+}
+
+func normalizePartition(partition string) string {
+	if partition == "" {
+		return DefaultPartition
+	}
+	return partition
+}
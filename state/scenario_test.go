@@ -0,0 +1,188 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScenarioStore_SetAndGetStep(t *testing.T) {
+	store := NewScenarioStore()
+
+	if step := store.GetStep("checkout", "u1"); step != DefaultStep {
+		t.Fatalf("expected %q for unknown partition, got %q", DefaultStep, step)
+	}
+
+	store.SetStep("checkout", "u1", "paid")
+	store.SetStep("checkout", "", "cart")
+
+	if step := store.GetStep("checkout", "u1"); step != "paid" {
+		t.Fatalf("expected step paid, got %q", step)
+	}
+	if step := store.GetStep("checkout", DefaultPartition); step != "cart" {
+		t.Fatalf("expected empty partition to map to default, got %q", step)
+	}
+
+	partitions := store.Partitions("checkout")
+	if len(partitions) != 2 {
+		t.Fatalf("expected 2 partitions, got %d", len(partitions))
+	}
+	if partitions[0].Partition != DefaultPartition || partitions[1].Partition != "u1" {
+		t.Fatalf("expected partitions sorted by key, got %+v", partitions)
+	}
+}
+
+func TestScenarioStore_Reset(t *testing.T) {
+	store := NewScenarioStore()
+	store.SetStep("checkout", "u1", "paid")
+	store.SetStep("login", "u1", "authenticated")
+
+	store.Reset("checkout", "u1")
+	if step := store.GetStep("checkout", "u1"); step != DefaultStep {
+		t.Fatalf("expected reset partition to return %q, got %q", DefaultStep, step)
+	}
+	if names := store.Scenarios(); len(names) != 1 || names[0] != "login" {
+		t.Fatalf("expected only login scenario to remain, got %v", names)
+	}
+
+	store.ResetAll()
+	if names := store.Scenarios(); len(names) != 0 {
+		t.Fatalf("expected no scenarios after ResetAll, got %v", names)
+	}
+}
+
+func TestScenarioStore_SnapshotRestore(t *testing.T) {
+	store := NewScenarioStore()
+	store.SetStep("checkout", "u1", "paid")
+	store.SetStep("login", "", "authenticated")
+
+	snapshot := store.Snapshot()
+
+	restored := NewScenarioStore()
+	restored.SetStep("stale", "x", "gone")
+	restored.Restore(snapshot)
+
+	if step := restored.GetStep("checkout", "u1"); step != "paid" {
+		t.Fatalf("expected restored step paid, got %q", step)
+	}
+	if step := restored.GetStep("login", ""); step != "authenticated" {
+		t.Fatalf("expected restored default partition, got %q", step)
+	}
+	if names := restored.Scenarios(); len(names) != 2 {
+		t.Fatalf("expected restore to replace existing state, got %v", names)
+	}
+}
+
+func TestPersistence_SaveLoadAndFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "scenarios.json")
+
+	store := NewScenarioStore()
+	store.SetStep("checkout", "u1", "paid")
+	if err := SaveFile(store, path); err != nil {
+		t.Fatalf("SaveFile returned error: %v", err)
+	}
+
+	loaded := NewScenarioStore()
+	if err := LoadFile(loaded, path); err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if step := loaded.GetStep("checkout", "u1"); step != "paid" {
+		t.Fatalf("expected loaded step paid, got %q", step)
+	}
+
+	if err := LoadFile(NewScenarioStore(), filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("expected missing file to be ignored, got %v", err)
+	}
+
+	stop := StartFlusher(loaded, path, time.Hour, func(err error) { t.Errorf("flush error: %v", err) })
+	loaded.SetStep("checkout", "u1", "shipped")
+	stop()
+
+	reloaded := NewScenarioStore()
+	if err := LoadFile(reloaded, path); err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if step := reloaded.GetStep("checkout", "u1"); step != "shipped" {
+		t.Errorf("expected final flush on stop, got %q", step)
+	}
+}
+
+func TestScenarioStore_ExpireIdle(t *testing.T) {
+	store := NewScenarioStore()
+	store.SetStep("checkout", "stale", "cart")
+	store.SetStep("checkout", "active", "cart")
+	store.SetStep("login", "u1", "authenticated")
+
+	later := time.Now().Add(time.Minute)
+	store.mu.Lock()
+	store.scenarios["checkout"]["active"].LastSeenAt = later
+	store.mu.Unlock()
+
+	expired := store.ExpireIdle("checkout", 30*time.Second, later)
+	if len(expired) != 1 || expired[0].Partition != "stale" || expired[0].Step != "cart" {
+		t.Fatalf("expected only the stale partition to expire, got %+v", expired)
+	}
+	if step := store.GetStep("checkout", "active"); step != "cart" {
+		t.Errorf("expected recently seen partition to survive, got %q", step)
+	}
+	if step := store.GetStep("login", "u1"); step != "authenticated" {
+		t.Errorf("expected other scenarios to be untouched, got %q", step)
+	}
+	if expired := store.ExpireIdle("checkout", 30*time.Second, later); expired != nil {
+		t.Errorf("expected nothing left to expire, got %+v", expired)
+	}
+}
+
+func TestScenarioStore_Variables(t *testing.T) {
+	store := NewScenarioStore()
+	store.SetVariables("checkout", "u1", map[string]string{"order_id": "A1"})
+
+	if step := store.GetStep("checkout", "u1"); step != DefaultStep {
+		t.Fatalf("expected SetVariables to create the partition in %q, got %q", DefaultStep, step)
+	}
+
+	store.SetStep("checkout", "u1", "paid")
+	store.SetVariables("checkout", "u1", map[string]string{"amount": "10"})
+
+	vars := store.GetVariables("checkout", "u1")
+	if vars["order_id"] != "A1" || vars["amount"] != "10" {
+		t.Fatalf("expected variables to be merged and kept across steps, got %v", vars)
+	}
+
+	vars["order_id"] = "changed"
+	if store.GetVariables("checkout", "u1")["order_id"] != "A1" {
+		t.Error("GetVariables must return a copy")
+	}
+	if store.GetVariables("checkout", "other") != nil {
+		t.Error("expected no variables for unknown partition")
+	}
+}
+
+func TestScenarioStore_History(t *testing.T) {
+	store := NewScenarioStore()
+	store.RecordTransition("checkout", Transition{Partition: "u1", PreviousStep: "idle", Step: "cart", Source: "request", RequestID: "r1"})
+	store.RecordTransition("checkout", Transition{Partition: "", PreviousStep: "idle", Step: "paid", Source: "admin"})
+
+	history := store.History("checkout", "")
+	if len(history) != 2 || history[0].RequestID != "r1" || history[1].Partition != DefaultPartition {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if history[0].Time.IsZero() {
+		t.Error("expected transition time to be filled in")
+	}
+	if u1 := store.History("checkout", "u1"); len(u1) != 1 || u1[0].Step != "cart" {
+		t.Errorf("expected partition filter to return u1 only, got %+v", u1)
+	}
+
+	for i := 0; i < MaxHistory+10; i++ {
+		store.RecordTransition("checkout", Transition{Partition: "u1", Step: "loop"})
+	}
+	if n := len(store.History("checkout", "")); n != MaxHistory {
+		t.Errorf("expected history capped at %d, got %d", MaxHistory, n)
+	}
+
+	store.ResetAll()
+	if n := len(store.History("checkout", "")); n != 0 {
+		t.Errorf("expected ResetAll to clear history, got %d entries", n)
+	}
+}
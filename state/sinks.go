@@ -0,0 +1,83 @@
+package state
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSinkEntries is how many requests a sink keeps when max_entries is unset
+const DefaultSinkEntries = 100
+
+// SinkEntry is one request received by a sink endpoint
+type SinkEntry struct {
+	Time      time.Time         `json:"time"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Query     string            `json:"query,omitempty"`
+	Headers   map[string]string `json:"headers"`
+	Body      string            `json:"body"`
+	ClientIP  string            `json:"client_ip"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// SinkStore keeps the requests received by sink endpoints, keyed by endpoint ID
+type SinkStore struct {
+	mu      sync.RWMutex
+	entries map[string][]SinkEntry
+	total   map[string]int
+}
+
+// NewSinkStore creates an empty SinkStore
+func NewSinkStore() *SinkStore {
+	return &SinkStore{
+		entries: make(map[string][]SinkEntry),
+		total:   make(map[string]int),
+	}
+}
+
+// Record appends an entry, dropping the oldest beyond max (DefaultSinkEntries when max <= 0)
+func (s *SinkStore) Record(id string, entry SinkEntry, max int) {
+	if max <= 0 {
+		max = DefaultSinkEntries
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := append(s.entries[id], entry)
+	if len(entries) > max {
+		entries = append([]SinkEntry(nil), entries[len(entries)-max:]...)
+	}
+	s.entries[id] = entries
+	s.total[id]++
+}
+
+// Entries returns the kept entries of a sink, oldest first
+func (s *SinkStore) Entries(id string) []SinkEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]SinkEntry{}, s.entries[id]...)
+}
+
+// Total returns how many requests a sink received since it was last cleared,
+// including dropped ones
+func (s *SinkStore) Total(id string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.total[id]
+}
+
+// Clear forgets the requests of one sink
+func (s *SinkStore) Clear(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	delete(s.total, id)
+}
+
+// ClearAll forgets the requests of every sink
+func (s *SinkStore) ClearAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string][]SinkEntry)
+	s.total = make(map[string]int)
+}
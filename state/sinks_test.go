@@ -0,0 +1,23 @@
+package state
+
+import "testing"
+
+func TestSinkStore(t *testing.T) {
+	s := NewSinkStore()
+	for _, path := range []string{"/a", "/b", "/c"} {
+		s.Record("hooks", SinkEntry{Path: path}, 2)
+	}
+
+	entries := s.Entries("hooks")
+	if len(entries) != 2 || entries[0].Path != "/b" || entries[1].Path != "/c" {
+		t.Errorf("expected the two newest entries, got %+v", entries)
+	}
+	if total := s.Total("hooks"); total != 3 {
+		t.Errorf("expected total 3, got %d", total)
+	}
+
+	s.Clear("hooks")
+	if len(s.Entries("hooks")) != 0 || s.Total("hooks") != 0 {
+		t.Error("expected Clear to forget entries")
+	}
+}
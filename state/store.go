@@ -0,0 +1,34 @@
+package state
+
+import "time"
+
+// Store keeps scenario steps, variables, transition history and named
+// counters. ScenarioStore keeps them in process memory; RedisStore shares
+// them between replicas.
+type Store interface {
+	GetStep(scenario, partition string) string
+	SetStep(scenario, partition, step string)
+	Touch(scenario, partition string)
+	GetVariables(scenario, partition string) map[string]string
+	SetVariables(scenario, partition string, vars map[string]string)
+
+	Partitions(scenario string) []PartitionState
+	Scenarios() []string
+	Reset(scenario, partition string)
+	ResetAll() // clears scenarios and history; counters are reset with ResetCounter
+	ExpireIdle(scenario string, ttl time.Duration, now time.Time) []PartitionState
+
+	RecordTransition(scenario string, t Transition)
+	History(scenario, partition string) []Transition
+
+	Increment(name string, delta int64) int64
+	GetCounter(name string) int64
+	ResetCounter(name string)
+	Counters() map[string]int64
+
+	Version() uint64
+	Snapshot() map[string][]PartitionState
+	Restore(snapshot map[string][]PartitionState)
+}
+
+var _ Store = (*ScenarioStore)(nil)
@@ -0,0 +1,96 @@
+// Shared helpers for the admin console pages. Every page is served from
+// /admin/ui/, so admin API calls use paths relative to /admin/.
+(function () {
+  var pages = [
+    { href: "index.html", title: "Home" },
+    { href: "requests.html", title: "Requests" },
+    { href: "endpoints.html", title: "Endpoints" },
+    { href: "metrics.html", title: "Metrics" },
+    { href: "scenarios.html", title: "Scenarios" },
+    { href: "templates.html", title: "Templates" },
+    { href: "match.html", title: "Match debugger" }
+  ];
+
+  function renderNav() {
+    var current = location.pathname.split("/").pop() || "index.html";
+    var header = document.createElement("header");
+    var links = pages.map(function (p) {
+      var cls = p.href === current ? ' class="active"' : "";
+      return '<a href="' + p.href + '"' + cls + ">" + p.title + "</a>";
+    }).join("");
+    header.innerHTML = '<span class="brand">Mock API Server</span><nav>' + links + "</nav>";
+    document.body.insertBefore(header, document.body.firstChild);
+  }
+
+  function escapeHTML(s) {
+    return String(s == null ? "" : s).replace(/[&<>"']/g, function (ch) {
+      return { "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" }[ch];
+    });
+  }
+
+  // prettyJSON indents text that parses as JSON and returns anything else unchanged
+  function prettyJSON(text) {
+    if (typeof text !== "string") return JSON.stringify(text, null, 2);
+    try {
+      return JSON.stringify(JSON.parse(text), null, 2);
+    } catch (e) {
+      return text;
+    }
+  }
+
+  // api calls an admin endpoint and resolves with the parsed JSON (or text)
+  // body, rejecting with the server's error message on non-2xx responses
+  function api(method, path, body, contentType) {
+    var opts = { method: method, headers: {} };
+    if (body !== undefined) {
+      opts.body = typeof body === "string" ? body : JSON.stringify(body);
+      opts.headers["Content-Type"] = contentType || "application/json";
+    }
+    return fetch("../" + path, opts).then(function (resp) {
+      return resp.text().then(function (text) {
+        var data = text;
+        try { data = JSON.parse(text); } catch (e) { /* plain text */ }
+        if (!resp.ok) {
+          var msg = data && data.error ? data.error.message : text || resp.statusText;
+          throw new Error(resp.status + ": " + msg);
+        }
+        return data;
+      });
+    });
+  }
+
+  // stream connects to /admin/ws and calls onEvent for every event, reconnecting
+  // after disconnects. onStatus receives "connected" or "disconnected".
+  function stream(onEvent, onStatus) {
+    var url = (location.protocol === "https:" ? "wss://" : "ws://") + location.host +
+      location.pathname.replace(/\/ui\/[^/]*$/, "/ws");
+    function connect() {
+      var ws = new WebSocket(url);
+      ws.onopen = function () { onStatus && onStatus("connected"); };
+      ws.onmessage = function (msg) {
+        try { onEvent(JSON.parse(msg.data)); } catch (e) { /* ignore malformed events */ }
+      };
+      ws.onclose = function () {
+        onStatus && onStatus("disconnected");
+        setTimeout(connect, 2000);
+      };
+    }
+    connect();
+  }
+
+  function statusClass(status) {
+    if (status >= 500) return "err";
+    if (status >= 400) return "warn";
+    return "ok";
+  }
+
+  window.MockUI = {
+    escapeHTML: escapeHTML,
+    prettyJSON: prettyJSON,
+    api: api,
+    stream: stream,
+    statusClass: statusClass
+  };
+
+  document.addEventListener("DOMContentLoaded", renderNav);
+})();
@@ -0,0 +1,20 @@
+// Package ui embeds the browser console served by the admin API under /admin/ui
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var files embed.FS
+
+// FS returns the console's pages and assets
+func FS() http.FileSystem {
+	sub, err := fs.Sub(files, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(sub)
+}
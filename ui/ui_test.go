@@ -0,0 +1,14 @@
+package ui
+
+import "testing"
+
+func TestFSServesPages(t *testing.T) {
+	for _, name := range []string{"/index.html", "/requests.html", "/endpoints.html", "/metrics.html", "/scenarios.html", "/templates.html", "/match.html", "/app.js", "/app.css"} {
+		f, err := FS().Open(name)
+		if err != nil {
+			t.Errorf("expected %s to be embedded: %v", name, err)
+			continue
+		}
+		f.Close()
+	}
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+	"mock-api-server/pkg/template"
+	"mock-api-server/state"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Transition is a scenario step change as published on the event bus
+type Transition struct {
+	Scenario     string `json:"scenario"`
+	Partition    string `json:"partition"`
+	PreviousStep string `json:"previous_step"`
+	Step         string `json:"step"`
+	Source       string `json:"source"`
+}
+
+// Dispatcher fires the webhooks configured under scenarios.<name>.webhooks
+// whenever a scenario partition enters a step
+type Dispatcher struct {
+	configManager *config.ConfigManager
+	scenarioStore state.Store
+	client        *http.Client
+	onError       func(error)
+}
+
+// NewDispatcher creates a Dispatcher. onError, which may be nil, receives
+// failed deliveries.
+func NewDispatcher(cfgManager *config.ConfigManager, scenarioStore state.Store, onError func(error)) *Dispatcher {
+	return &Dispatcher{
+		configManager: cfgManager,
+		scenarioStore: scenarioStore,
+		client:        &http.Client{},
+		onError:       onError,
+	}
+}
+
+// Start subscribes to scenario transitions on bus and returns a func that
+// stops listening. Deliveries run in the background and are not retried.
+func (d *Dispatcher) Start(bus *events.Bus) func() {
+	ch, unsubscribe := bus.Subscribe()
+	go func() {
+		for event := range ch {
+			if event.Type != events.TypeScenarioTransition {
+				continue
+			}
+			t, err := decodeTransition(event.Data)
+			if err != nil {
+				d.fail(err)
+				continue
+			}
+			d.Dispatch(t)
+		}
+	}()
+	return unsubscribe
+}
+
+// Dispatch fires every webhook of the transition's scenario whose on_step
+// matches the new step
+func (d *Dispatcher) Dispatch(t Transition) {
+	cfg := d.configManager.GetConfig()
+	if cfg == nil {
+		return
+	}
+	sc, ok := cfg.Scenarios[t.Scenario]
+	if !ok {
+		return
+	}
+	for _, wh := range sc.Webhooks {
+		if wh.OnStep != "" && wh.OnStep != t.Step {
+			continue
+		}
+		go func(wh config.ScenarioWebhook) {
+			if err := d.send(wh, t); err != nil {
+				d.fail(fmt.Errorf("webhook %s for scenario %s: %w", wh.URL, t.Scenario, err))
+			}
+		}(wh)
+	}
+}
+
+// send delivers one webhook and treats non-2xx responses as failures
+func (d *Dispatcher) send(wh config.ScenarioWebhook, t Transition) error {
+	values := map[string]string{
+		"scenario":      t.Scenario,
+		"partition":     t.Partition,
+		"previous_step": t.PreviousStep,
+		"step":          t.Step,
+		"source":        t.Source,
+	}
+	for name, value := range d.scenarioStore.GetVariables(t.Scenario, t.Partition) {
+		values["var."+name] = value
+	}
+
+	var body []byte
+	if wh.Body != "" {
+		body = template.ReplaceVariables([]byte(wh.Body), values)
+	} else {
+		body, _ = json.Marshal(t)
+	}
+
+	method := strings.ToUpper(wh.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	timeout := defaultTimeout
+	if wh.TimeoutMs > 0 {
+		timeout = time.Duration(wh.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range wh.Headers {
+		req.Header.Set(k, string(template.ReplaceVariables([]byte(v), values)))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (d *Dispatcher) fail(err error) {
+	if d.onError != nil {
+		d.onError(err)
+	}
+}
+
+// decodeTransition converts event data, which publishers pass as a generic
+// map, into a Transition
+func decodeTransition(data interface{}) (Transition, error) {
+	var t Transition
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return t, err
+	}
+	err = json.Unmarshal(raw, &t)
+	return t, err
+}
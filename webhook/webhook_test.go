@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mock-api-server/config"
+	"mock-api-server/pkg/events"
+	"mock-api-server/state"
+)
+
+type received struct {
+	method string
+	header string
+	body   string
+}
+
+func TestDispatcher_FiresOnMatchingStep(t *testing.T) {
+	calls := make(chan received, 4)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls <- received{method: r.Method, header: r.Header.Get("X-Partition"), body: string(body)}
+	}))
+	defer target.Close()
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Scenarios: map[string]config.ScenarioConfig{
+		"checkout": {Webhooks: []config.ScenarioWebhook{{
+			OnStep:  "paid",
+			URL:     target.URL,
+			Method:  "put",
+			Headers: map[string]string{"X-Partition": "{{.partition}}"},
+			Body:    `{"from":"{{.previous_step}}","order":"{{.var.order}}"}`,
+		}}},
+	}})
+
+	store := state.NewScenarioStore()
+	store.SetVariables("checkout", "u1", map[string]string{"order": "A1"})
+
+	bus := events.NewBus()
+	stop := NewDispatcher(cm, store, func(err error) { t.Errorf("unexpected error: %v", err) }).Start(bus)
+	defer stop()
+
+	bus.Publish(events.TypeScenarioTransition, map[string]interface{}{
+		"scenario": "checkout", "partition": "u1", "previous_step": "cart", "step": "shipped", "source": "request",
+	})
+	bus.Publish(events.TypeScenarioTransition, map[string]interface{}{
+		"scenario": "checkout", "partition": "u1", "previous_step": "cart", "step": "paid", "source": "request",
+	})
+
+	select {
+	case got := <-calls:
+		if got.method != http.MethodPut || got.header != "u1" || got.body != `{"from":"cart","order":"A1"}` {
+			t.Errorf("unexpected webhook request: %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+
+	select {
+	case got := <-calls:
+		t.Errorf("expected a single webhook call, got another: %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestDispatcher_DefaultBodyAndFailure(t *testing.T) {
+	calls := make(chan string, 1)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls <- string(body)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer target.Close()
+
+	cm := config.NewConfigManager("")
+	cm.SetConfig(&config.Config{Scenarios: map[string]config.ScenarioConfig{
+		"login": {Webhooks: []config.ScenarioWebhook{{URL: target.URL}}},
+	}})
+
+	errs := make(chan error, 1)
+	d := NewDispatcher(cm, state.NewScenarioStore(), func(err error) { errs <- err })
+	d.Dispatch(Transition{Scenario: "login", Partition: "u1", PreviousStep: "idle", Step: "authenticated", Source: "admin"})
+
+	want := `{"scenario":"login","partition":"u1","previous_step":"idle","step":"authenticated","source":"admin"}`
+	if got := <-calls; got != want {
+		t.Errorf("default body = %s, want %s", got, want)
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected non-2xx response to be reported")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected failure to be reported")
+	}
+}